@@ -6,10 +6,16 @@ import (
 	"fmt"
 	"net/url"
 	"os"
+	"strconv"
+	"time"
 
 	"codeberg.org/goern/forgejo-mcp/v2/operation"
+	"codeberg.org/goern/forgejo-mcp/v2/operation/resource"
 	flagPkg "codeberg.org/goern/forgejo-mcp/v2/pkg/flag"
+	"codeberg.org/goern/forgejo-mcp/v2/pkg/forgejo"
 	"codeberg.org/goern/forgejo-mcp/v2/pkg/log"
+	"codeberg.org/goern/forgejo-mcp/v2/pkg/paginate"
+	"codeberg.org/goern/forgejo-mcp/v2/pkg/to"
 )
 
 var (
@@ -17,6 +23,16 @@ var (
 	urlFlag   string
 	ssePort   int
 	token     string
+	verbosity string
+	format    string
+	maxItems  int
+
+	timeout        time.Duration
+	maxRetries     int
+	maxConcurrency int
+	toolTimeout    time.Duration
+	cacheSize      int
+	pollInterval   time.Duration
 
 	debug bool
 )
@@ -52,6 +68,60 @@ func init() {
 		"",
 		"Your personal access token",
 	)
+	flag.StringVar(
+		&verbosity,
+		"verbosity",
+		"",
+		"Tool output verbosity (compact or full, default compact)",
+	)
+	flag.StringVar(
+		&format,
+		"format",
+		"",
+		"Tool output format (json or markdown, default json)",
+	)
+	flag.IntVar(
+		&maxItems,
+		"max-items",
+		0,
+		"Maximum items returned by list tools called with all=true (default 1000)",
+	)
+	flag.DurationVar(
+		&timeout,
+		"timeout",
+		forgejo.DefaultTimeout,
+		"Timeout of each Forgejo API call, retries included",
+	)
+	flag.IntVar(
+		&maxRetries,
+		"max-retries",
+		forgejo.DefaultMaxRetries,
+		"Retries of idempotent Forgejo API calls on server and connection errors (0 disables)",
+	)
+	flag.IntVar(
+		&maxConcurrency,
+		"max-concurrency",
+		forgejo.DefaultMaxConcurrency,
+		"Maximum Forgejo API calls in flight",
+	)
+	flag.DurationVar(
+		&toolTimeout,
+		"tool-timeout",
+		operation.DefaultToolTimeout,
+		"Deadline of a tool call; code search, migrations and all=true lists get at least 5m",
+	)
+	flag.IntVar(
+		&cacheSize,
+		"cache-size",
+		forgejo.DefaultCacheSize,
+		"Maximum Forgejo API responses kept in the in-memory cache (0 disables)",
+	)
+	flag.DurationVar(
+		&pollInterval,
+		"poll-interval",
+		resource.DefaultPollInterval,
+		"How often resources that clients subscribed to are checked for changes",
+	)
 	flag.BoolVar(
 		&debug,
 		"d",
@@ -123,6 +193,159 @@ func init() {
 		}
 	}
 
+	flagPkg.Verbosity = verbosity
+	if flagPkg.Verbosity == "" {
+		flagPkg.Verbosity = os.Getenv("FORGEJO_VERBOSITY")
+	}
+	if flagPkg.Verbosity == "" {
+		flagPkg.Verbosity = to.VerbosityCompact
+	}
+	if flagPkg.Verbosity != to.VerbosityCompact && flagPkg.Verbosity != to.VerbosityFull {
+		log.Fatal("Invalid verbosity configuration",
+			log.StringField("verbosity", flagPkg.Verbosity),
+			log.StringField("valid_options", "compact, full"),
+		)
+	}
+
+	flagPkg.Format = format
+	if flagPkg.Format == "" {
+		flagPkg.Format = os.Getenv("FORGEJO_FORMAT")
+	}
+	if flagPkg.Format == "" {
+		flagPkg.Format = to.FormatJSON
+	}
+	if flagPkg.Format != to.FormatJSON && flagPkg.Format != to.FormatMarkdown {
+		log.Fatal("Invalid format configuration",
+			log.StringField("format", flagPkg.Format),
+			log.StringField("valid_options", "json, markdown"),
+		)
+	}
+
+	flagPkg.MaxItems = maxItems
+	if flagPkg.MaxItems == 0 {
+		if env := os.Getenv("FORGEJO_MAX_ITEMS"); env != "" {
+			n, err := strconv.Atoi(env)
+			if err != nil {
+				log.Fatal("Invalid max items configuration",
+					log.StringField("max_items", env),
+					log.ErrorField(err),
+				)
+			}
+			flagPkg.MaxItems = n
+		}
+	}
+	if flagPkg.MaxItems == 0 {
+		flagPkg.MaxItems = paginate.DefaultMaxItems
+	}
+	if flagPkg.MaxItems < 0 {
+		log.Fatal("Invalid max items configuration",
+			log.IntField("max_items", flagPkg.MaxItems),
+		)
+	}
+
+	flagPkg.Timeout = timeout
+	if env := os.Getenv("FORGEJO_TIMEOUT"); env != "" && !isSet("timeout") {
+		d, err := time.ParseDuration(env)
+		if err != nil {
+			log.Fatal("Invalid timeout configuration",
+				log.StringField("timeout", env),
+				log.ErrorField(err),
+			)
+		}
+		flagPkg.Timeout = d
+	}
+	if flagPkg.Timeout <= 0 {
+		log.Fatal("Invalid timeout configuration",
+			log.DurationField("timeout", flagPkg.Timeout),
+		)
+	}
+
+	flagPkg.MaxRetries = maxRetries
+	if env := os.Getenv("FORGEJO_MAX_RETRIES"); env != "" && !isSet("max-retries") {
+		n, err := strconv.Atoi(env)
+		if err != nil {
+			log.Fatal("Invalid max retries configuration",
+				log.StringField("max_retries", env),
+				log.ErrorField(err),
+			)
+		}
+		flagPkg.MaxRetries = n
+	}
+	if flagPkg.MaxRetries < 0 {
+		log.Fatal("Invalid max retries configuration",
+			log.IntField("max_retries", flagPkg.MaxRetries),
+		)
+	}
+
+	flagPkg.MaxConcurrency = maxConcurrency
+	if env := os.Getenv("FORGEJO_MAX_CONCURRENCY"); env != "" && !isSet("max-concurrency") {
+		n, err := strconv.Atoi(env)
+		if err != nil {
+			log.Fatal("Invalid max concurrency configuration",
+				log.StringField("max_concurrency", env),
+				log.ErrorField(err),
+			)
+		}
+		flagPkg.MaxConcurrency = n
+	}
+	if flagPkg.MaxConcurrency < 1 {
+		log.Fatal("Invalid max concurrency configuration",
+			log.IntField("max_concurrency", flagPkg.MaxConcurrency),
+		)
+	}
+
+	flagPkg.ToolTimeout = toolTimeout
+	if env := os.Getenv("FORGEJO_TOOL_TIMEOUT"); env != "" && !isSet("tool-timeout") {
+		d, err := time.ParseDuration(env)
+		if err != nil {
+			log.Fatal("Invalid tool timeout configuration",
+				log.StringField("tool_timeout", env),
+				log.ErrorField(err),
+			)
+		}
+		flagPkg.ToolTimeout = d
+	}
+	if flagPkg.ToolTimeout <= 0 {
+		log.Fatal("Invalid tool timeout configuration",
+			log.DurationField("tool_timeout", flagPkg.ToolTimeout),
+		)
+	}
+
+	flagPkg.CacheSize = cacheSize
+	if env := os.Getenv("FORGEJO_CACHE_SIZE"); env != "" && !isSet("cache-size") {
+		n, err := strconv.Atoi(env)
+		if err != nil {
+			log.Fatal("Invalid cache size configuration",
+				log.StringField("cache_size", env),
+				log.ErrorField(err),
+			)
+		}
+		flagPkg.CacheSize = n
+	}
+	if flagPkg.CacheSize < 0 {
+		log.Fatal("Invalid cache size configuration",
+			log.IntField("cache_size", flagPkg.CacheSize),
+		)
+	}
+
+	flagPkg.PollInterval = pollInterval
+	if env := os.Getenv("FORGEJO_POLL_INTERVAL"); env != "" && !isSet("poll-interval") {
+		d, err := time.ParseDuration(env)
+		if err != nil {
+			log.Fatal("Invalid poll interval configuration",
+				log.StringField("poll_interval", env),
+				log.ErrorField(err),
+			)
+		}
+		flagPkg.PollInterval = d
+	}
+	if flagPkg.PollInterval < time.Second {
+		log.Fatal("Invalid poll interval configuration",
+			log.DurationField("poll_interval", flagPkg.PollInterval),
+			log.StringField("minimum", "1s"),
+		)
+	}
+
 	if debug {
 		flagPkg.Debug = debug
 		log.Debug("Debug mode enabled via flag")
@@ -146,6 +369,18 @@ func init() {
 	}
 }
 
+// isSet reports whether the named flag was given on the command line, so
+// its default does not shadow the environment variable
+func isSet(name string) bool {
+	set := false
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
+}
+
 func validateURL(urlStr string) error {
 	parsedURL, err := url.Parse(urlStr)
 	if err != nil {
@@ -171,6 +406,15 @@ func Execute(version string) {
 		log.SanitizedURLField("url", flagPkg.URL),
 		log.StringField("transport", transport),
 		log.IntField("sse-port", flagPkg.SSEPort),
+		log.StringField("verbosity", flagPkg.Verbosity),
+		log.StringField("format", flagPkg.Format),
+		log.IntField("max_items", flagPkg.MaxItems),
+		log.DurationField("timeout", flagPkg.Timeout),
+		log.IntField("max_retries", flagPkg.MaxRetries),
+		log.IntField("max_concurrency", flagPkg.MaxConcurrency),
+		log.DurationField("tool_timeout", flagPkg.ToolTimeout),
+		log.IntField("cache_size", flagPkg.CacheSize),
+		log.DurationField("poll_interval", flagPkg.PollInterval),
 		log.BoolField("debug", flagPkg.Debug),
 		log.BoolField("token_configured", flagPkg.Token != ""),
 	)
@@ -0,0 +1,345 @@
+package actions
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"codeberg.org/goern/forgejo-mcp/v2/operation/params"
+	"codeberg.org/goern/forgejo-mcp/v2/pkg/forgejo"
+	"codeberg.org/goern/forgejo-mcp/v2/pkg/log"
+	"codeberg.org/goern/forgejo-mcp/v2/pkg/paginate"
+	"codeberg.org/goern/forgejo-mcp/v2/pkg/to"
+
+	forgejo_sdk "codeberg.org/mvdkleijn/forgejo-sdk/forgejo/v2"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const (
+	ListActionVariablesToolName  = "list_action_variables"
+	GetActionVariableToolName    = "get_action_variable"
+	CreateActionVariableToolName = "create_action_variable"
+	UpdateActionVariableToolName = "update_action_variable"
+	DeleteActionVariableToolName = "delete_action_variable"
+	ListActionSecretsToolName    = "list_action_secrets"
+	CreateActionSecretToolName   = "create_action_secret"
+	DeleteActionSecretToolName   = "delete_action_secret"
+)
+
+// ActionVariable mirrors the Forgejo ActionVariable API object, which the SDK does not provide
+type ActionVariable struct {
+	OwnerID int64  `json:"owner_id"`
+	RepoID  int64  `json:"repo_id"`
+	Name    string `json:"name"`
+	Data    string `json:"data"`
+}
+
+var (
+	ListActionVariablesTool = mcp.NewTool(
+		ListActionVariablesToolName,
+		mcp.WithDescription("List Actions variables of a repo or org"),
+		to.WithResult[*to.Page[*ActionVariable]](),
+		mcp.WithString("owner", mcp.Required(), mcp.Description(params.Owner)),
+		mcp.WithString("repo", mcp.Description(params.ScopeRepo)),
+		mcp.WithNumber("page", mcp.Description(params.Page), mcp.DefaultNumber(1)),
+		mcp.WithNumber("limit", mcp.Description(params.Limit), mcp.DefaultNumber(20)),
+	)
+
+	GetActionVariableTool = mcp.NewTool(
+		GetActionVariableToolName,
+		mcp.WithDescription("Get Actions variable"),
+		to.WithResult[*ActionVariable](),
+		mcp.WithString("owner", mcp.Required(), mcp.Description(params.Owner)),
+		mcp.WithString("repo", mcp.Description(params.ScopeRepo)),
+		mcp.WithString("name", mcp.Required(), mcp.Description(params.VariableName)),
+	)
+
+	CreateActionVariableTool = mcp.NewTool(
+		CreateActionVariableToolName,
+		mcp.WithDescription("Create Actions variable"),
+		to.WithResult[*ActionVariable](),
+		mcp.WithString("owner", mcp.Required(), mcp.Description(params.Owner)),
+		mcp.WithString("repo", mcp.Description(params.ScopeRepo)),
+		mcp.WithString("name", mcp.Required(), mcp.Description(params.VariableName)),
+		mcp.WithString("value", mcp.Required(), mcp.Description("Variable value")),
+	)
+
+	UpdateActionVariableTool = mcp.NewTool(
+		UpdateActionVariableToolName,
+		mcp.WithDescription("Update Actions variable"),
+		to.WithResult[*ActionVariable](),
+		mcp.WithString("owner", mcp.Required(), mcp.Description(params.Owner)),
+		mcp.WithString("repo", mcp.Description(params.ScopeRepo)),
+		mcp.WithString("name", mcp.Required(), mcp.Description(params.VariableName)),
+		mcp.WithString("value", mcp.Required(), mcp.Description("New variable value")),
+		mcp.WithString("new_name", mcp.Description("New variable name")),
+	)
+
+	DeleteActionVariableTool = mcp.NewTool(
+		DeleteActionVariableToolName,
+		mcp.WithDescription("Delete Actions variable"),
+		to.WithResult[string](),
+		mcp.WithString("owner", mcp.Required(), mcp.Description(params.Owner)),
+		mcp.WithString("repo", mcp.Description(params.ScopeRepo)),
+		mcp.WithString("name", mcp.Required(), mcp.Description(params.VariableName)),
+	)
+
+	ListActionSecretsTool = mcp.NewTool(
+		ListActionSecretsToolName,
+		mcp.WithDescription("List Actions secret names of a repo or org (values are never returned)"),
+		to.WithResult[*to.Page[*forgejo_sdk.Secret]](),
+		mcp.WithString("owner", mcp.Required(), mcp.Description(params.Owner)),
+		mcp.WithString("repo", mcp.Description(params.ScopeRepo)),
+		mcp.WithNumber("page", mcp.Description(params.Page), mcp.DefaultNumber(1)),
+		mcp.WithNumber("limit", mcp.Description(params.Limit), mcp.DefaultNumber(20)),
+	)
+
+	CreateActionSecretTool = mcp.NewTool(
+		CreateActionSecretToolName,
+		mcp.WithDescription("Create or overwrite Actions secret (write-only)"),
+		to.WithResult[string](),
+		mcp.WithString("owner", mcp.Required(), mcp.Description(params.Owner)),
+		mcp.WithString("repo", mcp.Description(params.ScopeRepo)),
+		mcp.WithString("name", mcp.Required(), mcp.Description(params.SecretName)),
+		mcp.WithString("secret", mcp.Required(), mcp.Description("Secret value")),
+	)
+
+	DeleteActionSecretTool = mcp.NewTool(
+		DeleteActionSecretToolName,
+		mcp.WithDescription("Delete Actions secret"),
+		to.WithResult[string](),
+		mcp.WithString("owner", mcp.Required(), mcp.Description(params.Owner)),
+		mcp.WithString("repo", mcp.Description(params.ScopeRepo)),
+		mcp.WithString("name", mcp.Required(), mcp.Description(params.SecretName)),
+	)
+)
+
+func RegisterTool(s *server.MCPServer) {
+	s.AddTool(ListActionVariablesTool, ListActionVariablesFn)
+	s.AddTool(GetActionVariableTool, GetActionVariableFn)
+	s.AddTool(CreateActionVariableTool, CreateActionVariableFn)
+	s.AddTool(UpdateActionVariableTool, UpdateActionVariableFn)
+	s.AddTool(DeleteActionVariableTool, DeleteActionVariableFn)
+	s.AddTool(ListActionSecretsTool, ListActionSecretsFn)
+	s.AddTool(CreateActionSecretTool, CreateActionSecretFn)
+	s.AddTool(DeleteActionSecretTool, DeleteActionSecretFn)
+}
+
+// scopePath returns the actions API prefix for a repo, or for the org named
+// by owner when repo is empty
+func scopePath(owner, repo string) string {
+	if repo == "" {
+		return fmt.Sprintf("/orgs/%s/actions", url.PathEscape(owner))
+	}
+	return fmt.Sprintf("/repos/%s/%s/actions", url.PathEscape(owner), url.PathEscape(repo))
+}
+
+func variablePath(owner, repo, name string) string {
+	return fmt.Sprintf("%s/variables/%s", scopePath(owner, repo), url.PathEscape(name))
+}
+
+func ListActionVariablesFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called ListActionVariablesFn")
+	owner, err := req.RequireString("owner")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	repo := req.GetString("repo", "")
+	paging, err := paginate.FromRequest(req, 20)
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+
+	variables, err := paginate.Fetch(ctx, paging, func(page, limit int) ([]*ActionVariable, *http.Response, error) {
+		variables := []*ActionVariable{}
+		resp, err := forgejo.Do(ctx, "GET", scopePath(owner, repo)+"/variables", paginate.Query(nil, page, limit), nil, &variables)
+		return variables, resp, err
+	})
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("list action variables err: %v", err))
+	}
+	return to.TextResult(variables)
+}
+
+func GetActionVariableFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called GetActionVariableFn")
+	owner, err := req.RequireString("owner")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	repo := req.GetString("repo", "")
+	name, err := req.RequireString("name")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+
+	variable := &ActionVariable{}
+	_, err = forgejo.Do(ctx, "GET", variablePath(owner, repo, name), nil, nil, variable)
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("get action variable err: %v", err))
+	}
+	return to.TextResult(variable)
+}
+
+func CreateActionVariableFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called CreateActionVariableFn")
+	owner, err := req.RequireString("owner")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	repo := req.GetString("repo", "")
+	name, err := req.RequireString("name")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	value, err := req.RequireString("value")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+
+	body := map[string]string{"value": value}
+	_, err = forgejo.Do(ctx, "POST", variablePath(owner, repo, name), nil, body, nil)
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("create action variable err: %v", err))
+	}
+
+	// Creation returns no body, fetch the variable to return it
+	variable := &ActionVariable{}
+	_, err = forgejo.Do(ctx, "GET", variablePath(owner, repo, name), nil, nil, variable)
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("get created action variable err: %v", err))
+	}
+	return to.TextResult(variable)
+}
+
+func UpdateActionVariableFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called UpdateActionVariableFn")
+	owner, err := req.RequireString("owner")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	repo := req.GetString("repo", "")
+	name, err := req.RequireString("name")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	value, err := req.RequireString("value")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	newName := req.GetString("new_name", "")
+
+	body := map[string]string{"value": value}
+	if newName != "" {
+		body["name"] = newName
+	} else {
+		newName = name
+	}
+	_, err = forgejo.Do(ctx, "PUT", variablePath(owner, repo, name), nil, body, nil)
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("update action variable err: %v", err))
+	}
+
+	variable := &ActionVariable{}
+	_, err = forgejo.Do(ctx, "GET", variablePath(owner, repo, newName), nil, nil, variable)
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("get updated action variable err: %v", err))
+	}
+	return to.TextResult(variable)
+}
+
+func DeleteActionVariableFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called DeleteActionVariableFn")
+	owner, err := req.RequireString("owner")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	repo := req.GetString("repo", "")
+	name, err := req.RequireString("name")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+
+	_, err = forgejo.Do(ctx, "DELETE", variablePath(owner, repo, name), nil, nil, nil)
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("delete action variable err: %v", err))
+	}
+	return to.TextResult("Delete variable success")
+}
+
+func ListActionSecretsFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called ListActionSecretsFn")
+	owner, err := req.RequireString("owner")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	repo := req.GetString("repo", "")
+	paging, err := paginate.FromRequest(req, 20)
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+
+	secrets, err := paginate.List(ctx, paging, func(lo forgejo_sdk.ListOptions) ([]*forgejo_sdk.Secret, *forgejo_sdk.Response, error) {
+		if repo == "" {
+			return forgejo.ClientCtx(ctx).ListOrgActionSecret(owner, forgejo_sdk.ListOrgActionSecretOption{ListOptions: lo})
+		}
+		return forgejo.ClientCtx(ctx).ListRepoActionSecret(owner, repo, forgejo_sdk.ListRepoActionSecretOption{ListOptions: lo})
+	})
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("list action secrets err: %v", err))
+	}
+	return to.TextResult(secrets)
+}
+
+func CreateActionSecretFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called CreateActionSecretFn")
+	owner, err := req.RequireString("owner")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	repo := req.GetString("repo", "")
+	name, err := req.RequireString("name")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	secret, err := req.RequireString("secret")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+
+	opt := forgejo_sdk.CreateSecretOption{
+		Name: name,
+		Data: secret,
+	}
+	if repo == "" {
+		_, err = forgejo.ClientCtx(ctx).CreateOrgActionSecret(owner, opt)
+	} else {
+		_, err = forgejo.ClientCtx(ctx).CreateRepoActionSecret(owner, repo, opt)
+	}
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("create action secret err: %v", err))
+	}
+	return to.TextResult(fmt.Sprintf("Secret %s stored", name))
+}
+
+func DeleteActionSecretFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called DeleteActionSecretFn")
+	owner, err := req.RequireString("owner")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	repo := req.GetString("repo", "")
+	name, err := req.RequireString("name")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+
+	path := fmt.Sprintf("%s/secrets/%s", scopePath(owner, repo), url.PathEscape(name))
+	_, err = forgejo.Do(ctx, "DELETE", path, nil, nil, nil)
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("delete action secret err: %v", err))
+	}
+	return to.TextResult("Delete secret success")
+}
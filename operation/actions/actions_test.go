@@ -0,0 +1,174 @@
+package actions
+
+import (
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestCreateActionSecretTool verifies the tool definition is correctly configured
+func TestCreateActionSecretTool(t *testing.T) {
+	tool := CreateActionSecretTool
+
+	assert.Equal(t, "create_action_secret", tool.Name)
+	assert.NotNil(t, tool.Description)
+
+	// Check parameters
+	params := tool.InputSchema.Properties
+	assert.Contains(t, params, "owner")
+	assert.Contains(t, params, "repo")
+	assert.Contains(t, params, "name")
+	assert.Contains(t, params, "secret")
+
+	// Verify required fields, repo is optional for org-level secrets
+	assert.Contains(t, tool.InputSchema.Required, "owner")
+	assert.Contains(t, tool.InputSchema.Required, "name")
+	assert.Contains(t, tool.InputSchema.Required, "secret")
+	assert.NotContains(t, tool.InputSchema.Required, "repo")
+}
+
+// TestUpdateActionVariableTool verifies the tool definition is correctly configured
+func TestUpdateActionVariableTool(t *testing.T) {
+	tool := UpdateActionVariableTool
+
+	assert.Equal(t, "update_action_variable", tool.Name)
+	assert.NotNil(t, tool.Description)
+
+	params := tool.InputSchema.Properties
+	assert.Contains(t, params, "owner")
+	assert.Contains(t, params, "repo")
+	assert.Contains(t, params, "name")
+	assert.Contains(t, params, "value")
+	assert.Contains(t, params, "new_name")
+
+	assert.Contains(t, tool.InputSchema.Required, "owner")
+	assert.Contains(t, tool.InputSchema.Required, "name")
+	assert.Contains(t, tool.InputSchema.Required, "value")
+}
+
+// TestScopePath tests the repo and org API prefixes
+func TestScopePath(t *testing.T) {
+	tests := []struct {
+		name  string
+		owner string
+		repo  string
+		want  string
+	}{
+		{
+			name:  "repo scope",
+			owner: "goern",
+			repo:  "forgejo-mcp",
+			want:  "/repos/goern/forgejo-mcp/actions",
+		},
+		{
+			name:  "org scope",
+			owner: "my-org",
+			repo:  "",
+			want:  "/orgs/my-org/actions",
+		},
+		{
+			name:  "escaped segments",
+			owner: "a b",
+			repo:  "c/d",
+			want:  "/repos/a%20b/c%2Fd/actions",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, scopePath(tt.owner, tt.repo))
+		})
+	}
+}
+
+// TestCreateActionSecretFn_MissingRequiredParams tests error handling for missing required parameters
+func TestCreateActionSecretFn_MissingRequiredParams(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     map[string]interface{}
+		errField string
+	}{
+		{
+			name: "missing owner",
+			args: map[string]interface{}{
+				"name":   "TOKEN",
+				"secret": "s3cr3t",
+			},
+			errField: "owner",
+		},
+		{
+			name: "missing name",
+			args: map[string]interface{}{
+				"owner":  "test-owner",
+				"secret": "s3cr3t",
+			},
+			errField: "name",
+		},
+		{
+			name: "missing secret",
+			args: map[string]interface{}{
+				"owner": "test-owner",
+				"name":  "TOKEN",
+			},
+			errField: "secret",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := mcp.CallToolRequest{
+				Params: mcp.CallToolParams{
+					Arguments: tt.args,
+				},
+			}
+
+			result, err := CreateActionSecretFn(nil, req)
+			assert.NoError(t, err)
+			require.True(t, result.IsError)
+			assert.Contains(t, result.Content[0].(mcp.TextContent).Text, tt.errField)
+		})
+	}
+}
+
+// TestCreateActionVariableFn_MissingRequiredParams tests error handling for missing required parameters
+func TestCreateActionVariableFn_MissingRequiredParams(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     map[string]interface{}
+		errField string
+	}{
+		{
+			name: "missing name",
+			args: map[string]interface{}{
+				"owner": "test-owner",
+				"value": "staging",
+			},
+			errField: "name",
+		},
+		{
+			name: "missing value",
+			args: map[string]interface{}{
+				"owner": "test-owner",
+				"name":  "DEPLOY_ENV",
+			},
+			errField: "value",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := mcp.CallToolRequest{
+				Params: mcp.CallToolParams{
+					Arguments: tt.args,
+				},
+			}
+
+			result, err := CreateActionVariableFn(nil, req)
+			assert.NoError(t, err)
+			require.True(t, result.IsError)
+			assert.Contains(t, result.Content[0].(mcp.TextContent).Text, tt.errField)
+		})
+	}
+}
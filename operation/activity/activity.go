@@ -0,0 +1,179 @@
+package activity
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"codeberg.org/goern/forgejo-mcp/v2/operation/params"
+	"codeberg.org/goern/forgejo-mcp/v2/pkg/forgejo"
+	"codeberg.org/goern/forgejo-mcp/v2/pkg/log"
+	"codeberg.org/goern/forgejo-mcp/v2/pkg/paginate"
+	"codeberg.org/goern/forgejo-mcp/v2/pkg/to"
+
+	forgejo_sdk "codeberg.org/mvdkleijn/forgejo-sdk/forgejo/v2"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const (
+	ListRepoActivityToolName = "list_repo_activity"
+	ListOrgActivityToolName  = "list_org_activity"
+	ListUserActivityToolName = "list_user_activity"
+
+	// feedDateLayout is the format of the feed date filter
+	feedDateLayout = "2006-01-02"
+)
+
+var (
+	ListRepoActivityTool = mcp.NewTool(
+		ListRepoActivityToolName,
+		mcp.WithDescription("List repository activity feed (pushes, issue and PR events, releases)"),
+		to.WithResult[*to.Page[*Activity]](),
+		mcp.WithString("owner", mcp.Required(), mcp.Description(params.Owner)),
+		mcp.WithString("repo", mcp.Required(), mcp.Description(params.Repo)),
+		mcp.WithString("date", mcp.Description(params.FeedDate)),
+		mcp.WithNumber("page", mcp.Description(params.Page), mcp.DefaultNumber(1)),
+		mcp.WithNumber("limit", mcp.Description(params.Limit), mcp.DefaultNumber(20)),
+	)
+
+	ListOrgActivityTool = mcp.NewTool(
+		ListOrgActivityToolName,
+		mcp.WithDescription("List organization activity feed, optionally of one team"),
+		to.WithResult[*to.Page[*Activity]](),
+		mcp.WithString("org", mcp.Required(), mcp.Description(params.Org)),
+		mcp.WithNumber("team_id", mcp.Description("Team ID (only this team's repositories)")),
+		mcp.WithString("date", mcp.Description(params.FeedDate)),
+		mcp.WithNumber("page", mcp.Description(params.Page), mcp.DefaultNumber(1)),
+		mcp.WithNumber("limit", mcp.Description(params.Limit), mcp.DefaultNumber(20)),
+	)
+
+	ListUserActivityTool = mcp.NewTool(
+		ListUserActivityToolName,
+		mcp.WithDescription("List user activity feed"),
+		to.WithResult[*to.Page[*Activity]](),
+		mcp.WithString("user", mcp.Required(), mcp.Description(params.User)),
+		mcp.WithBoolean("only_performed_by", mcp.Description("Only actions the user performed, not those on their repositories")),
+		mcp.WithString("date", mcp.Description(params.FeedDate)),
+		mcp.WithNumber("page", mcp.Description(params.Page), mcp.DefaultNumber(1)),
+		mcp.WithNumber("limit", mcp.Description(params.Limit), mcp.DefaultNumber(20)),
+	)
+)
+
+// Activity is one activity feed entry. OpType names the action, e.g.
+// commit_repo, create_issue, merge_pull_request or publish_release.
+type Activity struct {
+	ID        int64                   `json:"id"`
+	OpType    string                  `json:"op_type"`
+	ActUser   *forgejo_sdk.User       `json:"act_user"`
+	Repo      *forgejo_sdk.Repository `json:"repo"`
+	RefName   string                  `json:"ref_name"`
+	Comment   *forgejo_sdk.Comment    `json:"comment,omitempty"`
+	Content   string                  `json:"content"`
+	IsPrivate bool                    `json:"is_private"`
+	Created   time.Time               `json:"created"`
+}
+
+// activityView is the compact form of an Activity; its fields hide those
+// of the embedded entry in JSON
+type activityView struct {
+	*Activity
+	ActUser *to.UserView `json:"act_user"`
+	Repo    *to.RepoView `json:"repo"`
+}
+
+func (a *Activity) CompactView() any {
+	if a == nil {
+		return (*activityView)(nil)
+	}
+	return &activityView{
+		Activity: a,
+		ActUser:  to.View[*to.UserView](a.ActUser),
+		Repo:     to.View[*to.RepoView](a.Repo),
+	}
+}
+
+func RegisterTool(s *server.MCPServer) {
+	s.AddTool(ListRepoActivityTool, ListRepoActivityFn)
+	s.AddTool(ListOrgActivityTool, ListOrgActivityFn)
+	s.AddTool(ListUserActivityTool, ListUserActivityFn)
+}
+
+// feedQuery reads the date filter and paging shared by all feeds
+func feedQuery(req mcp.CallToolRequest) (url.Values, paginate.Options, error) {
+	query := url.Values{}
+	if date := req.GetString("date", ""); date != "" {
+		if _, err := time.Parse(feedDateLayout, date); err != nil {
+			return nil, paginate.Options{}, to.InvalidArgument("invalid date format (expected YYYY-MM-DD): %v", err)
+		}
+		query.Set("date", date)
+	}
+	paging, err := paginate.FromRequest(req, 20)
+	if err != nil {
+		return nil, paginate.Options{}, err
+	}
+	return query, paging, nil
+}
+
+func listFeed(ctx context.Context, path string, query url.Values, paging paginate.Options) (*mcp.CallToolResult, error) {
+	feed, err := paginate.Fetch(ctx, paging, func(page, limit int) ([]*Activity, *http.Response, error) {
+		feed := []*Activity{}
+		resp, err := forgejo.Do(ctx, "GET", path, paginate.Query(query, page, limit), nil, &feed)
+		return feed, resp, err
+	})
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("list activity err: %v", err))
+	}
+	return to.TextResult(feed)
+}
+
+func ListRepoActivityFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called ListRepoActivityFn")
+	owner, err := req.RequireString("owner")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	repo, err := req.RequireString("repo")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	query, paging, err := feedQuery(req)
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	return listFeed(ctx, fmt.Sprintf("/repos/%s/%s/activities/feeds", url.PathEscape(owner), url.PathEscape(repo)), query, paging)
+}
+
+func ListOrgActivityFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called ListOrgActivityFn")
+	org, err := req.RequireString("org")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	query, paging, err := feedQuery(req)
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	if teamID := int64(req.GetFloat("team_id", 0)); teamID != 0 {
+		return listFeed(ctx, fmt.Sprintf("/teams/%d/activities/feeds", teamID), query, paging)
+	}
+	return listFeed(ctx, fmt.Sprintf("/orgs/%s/activities/feeds", url.PathEscape(org)), query, paging)
+}
+
+func ListUserActivityFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called ListUserActivityFn")
+	user, err := req.RequireString("user")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	query, paging, err := feedQuery(req)
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	if req.GetBool("only_performed_by", false) {
+		query.Set("only-performed-by", "true")
+	}
+	return listFeed(ctx, fmt.Sprintf("/users/%s/activities/feeds", url.PathEscape(user)), query, paging)
+}
@@ -0,0 +1,44 @@
+package activity
+
+import (
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestActivityTools verifies the feed tools share the date and paging arguments
+func TestActivityTools(t *testing.T) {
+	for _, tool := range []mcp.Tool{ListRepoActivityTool, ListOrgActivityTool, ListUserActivityTool} {
+		params := tool.InputSchema.Properties
+		assert.Contains(t, params, "date", tool.Name)
+		assert.Contains(t, params, "page", tool.Name)
+		assert.Contains(t, params, "limit", tool.Name)
+	}
+	assert.Contains(t, ListOrgActivityTool.InputSchema.Properties, "team_id")
+	assert.Contains(t, ListUserActivityTool.InputSchema.Properties, "only_performed_by")
+}
+
+// TestFeedQuery tests the date filter and paging query
+func TestFeedQuery(t *testing.T) {
+	req := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Arguments: map[string]interface{}{
+				"date":  "2026-10-17",
+				"page":  float64(2),
+				"limit": float64(10),
+			},
+		},
+	}
+
+	query, paging, err := feedQuery(req)
+	assert.NoError(t, err)
+	assert.Equal(t, "2026-10-17", query.Get("date"))
+	assert.Equal(t, 2, paging.Page)
+	assert.Equal(t, 10, paging.Limit)
+
+	req.Params.Arguments = map[string]interface{}{"date": "2026-10-17T00:00:00Z"}
+	_, _, err = feedQuery(req)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid date format")
+}
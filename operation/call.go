@@ -0,0 +1,161 @@
+package operation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"codeberg.org/goern/forgejo-mcp/v2/operation/issue"
+	"codeberg.org/goern/forgejo-mcp/v2/operation/repo"
+	"codeberg.org/goern/forgejo-mcp/v2/operation/search"
+	"codeberg.org/goern/forgejo-mcp/v2/pkg/flag"
+	"codeberg.org/goern/forgejo-mcp/v2/pkg/forgejo"
+	"codeberg.org/goern/forgejo-mcp/v2/pkg/log"
+	"codeberg.org/goern/forgejo-mcp/v2/pkg/to"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// DefaultToolTimeout is the deadline of a tool call when no --tool-timeout
+// is configured
+const DefaultToolTimeout = 2 * time.Minute
+
+// longToolTimeouts are the deadlines of tools that scan many files, walk
+// issue graphs or move large payloads. Their Forgejo calls may take as long
+// as the tool.
+var longToolTimeouts = map[string]time.Duration{
+	search.SearchCodeToolName:             5 * time.Minute,
+	issue.GetIssueGraphToolName:           5 * time.Minute,
+	issue.UploadIssueAttachmentToolName:   5 * time.Minute,
+	issue.DownloadIssueAttachmentToolName: 5 * time.Minute,
+	repo.MigrateRepoToolName:              15 * time.Minute,
+}
+
+// allPagesTimeout is the deadline of list calls with all=true
+const allPagesTimeout = 5 * time.Minute
+
+// requestKeyMeta is the _meta field in which the before-call hook hands the
+// session and JSON-RPC id of a call to callMiddleware
+const requestKeyMeta = "forgejo-mcp/request-key"
+
+// errCancelled is the cause of calls stopped by notifications/cancelled
+var errCancelled = errors.New("cancelled by the client")
+
+// runningCalls are the cancel functions of tool calls in progress, by
+// request key
+var runningCalls sync.Map
+
+// toolTimeout returns the deadline of a call, and whether its single
+// Forgejo calls may take that long too
+func toolTimeout(req mcp.CallToolRequest) (time.Duration, bool) {
+	timeout := flag.ToolTimeout
+	if timeout <= 0 {
+		timeout = DefaultToolTimeout
+	}
+	if long, ok := longToolTimeouts[req.Params.Name]; ok {
+		return max(timeout, long), true
+	}
+	if req.GetBool("all", false) {
+		return max(timeout, allPagesTimeout), false
+	}
+	return timeout, false
+}
+
+// requestKey identifies a call by session and JSON-RPC id, the way
+// notifications/cancelled names it
+func requestKey(ctx context.Context, id mcp.RequestId) string {
+	session := ""
+	if s := server.ClientSessionFromContext(ctx); s != nil {
+		session = s.SessionID()
+	}
+	return session + "/" + id.String()
+}
+
+// tagRequest is the before-call hook that records the request key in the
+// call's _meta, since tool handlers do not see the JSON-RPC id
+func tagRequest(ctx context.Context, id any, req *mcp.CallToolRequest) {
+	requestID, ok := id.(mcp.RequestId)
+	if !ok {
+		requestID = mcp.NewRequestId(id)
+	}
+	if req.Params.Meta == nil {
+		req.Params.Meta = &mcp.Meta{}
+	}
+	if req.Params.Meta.AdditionalFields == nil {
+		req.Params.Meta.AdditionalFields = map[string]any{}
+	}
+	req.Params.Meta.AdditionalFields[requestKeyMeta] = requestKey(ctx, requestID)
+}
+
+// handleCancelled stops the call named by a notifications/cancelled
+func handleCancelled(ctx context.Context, notification mcp.JSONRPCNotification) {
+	id, ok := notification.Params.AdditionalFields["requestId"]
+	if !ok {
+		return
+	}
+	key := requestKey(ctx, mcp.NewRequestId(id))
+	if cancel, ok := runningCalls.Load(key); ok {
+		reason, _ := notification.Params.AdditionalFields["reason"].(string)
+		log.Info("Tool call cancelled by client",
+			log.StringField("request", key),
+			log.StringField("reason", reason),
+		)
+		cancel.(context.CancelCauseFunc)(errCancelled)
+	}
+}
+
+// callMiddleware runs each tool call under its deadline with a client bound
+// to it, and makes it cancellable through notifications/cancelled
+func callMiddleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		timeout, longCalls := toolTimeout(req)
+		ctx, cancel := context.WithCancelCause(ctx)
+		defer cancel(nil)
+		ctx, stop := context.WithTimeout(ctx, timeout)
+		defer stop()
+		if longCalls {
+			ctx = forgejo.WithCallTimeout(ctx, timeout)
+		}
+		ctx, err := forgejo.WithClient(ctx)
+		if err != nil {
+			return to.ErrorResult(fmt.Errorf("%s: %w", req.Params.Name, err))
+		}
+
+		if req.Params.Meta != nil {
+			if key, ok := req.Params.Meta.AdditionalFields[requestKeyMeta].(string); ok {
+				runningCalls.Store(key, cancel)
+				defer runningCalls.Delete(key)
+			}
+		}
+
+		result, err := next(ctx, req)
+		switch {
+		case errors.Is(context.Cause(ctx), errCancelled):
+			return to.ErrorResult(fmt.Errorf("%s %w (%w)", req.Params.Name, errCancelled, context.Canceled))
+		case errors.Is(ctx.Err(), context.DeadlineExceeded):
+			return to.ErrorResult(fmt.Errorf("%s: %w after %s", req.Params.Name, context.DeadlineExceeded, timeout))
+		}
+		return result, err
+	}
+}
+
+// resourceMiddleware runs each resource read under the tool call deadline
+// with a client bound to it
+func resourceMiddleware(next server.ResourceHandlerFunc) server.ResourceHandlerFunc {
+	return func(ctx context.Context, req mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		timeout := flag.ToolTimeout
+		if timeout <= 0 {
+			timeout = DefaultToolTimeout
+		}
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+		ctx, err := forgejo.WithClient(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return next(ctx, req)
+	}
+}
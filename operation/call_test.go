@@ -0,0 +1,100 @@
+package operation
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"codeberg.org/goern/forgejo-mcp/v2/pkg/flag"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// blockingServer serves a tool that runs until its context is done and
+// reports when it started
+func blockingServer(t *testing.T) (*server.MCPServer, chan struct{}) {
+	s := newMCPServer("test")
+	started := make(chan struct{}, 1)
+	s.AddTool(mcp.NewTool("block"), func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		started <- struct{}{}
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	return s, started
+}
+
+// callResult decodes the result of a tools/call response
+func callResult(t *testing.T, response mcp.JSONRPCMessage) mcp.CallToolResult {
+	data, err := json.Marshal(response)
+	require.NoError(t, err)
+	var msg struct {
+		Result mcp.CallToolResult `json:"result"`
+	}
+	require.NoError(t, json.Unmarshal(data, &msg))
+	return msg.Result
+}
+
+// TestCallMiddleware_Cancelled stops a running call on notifications/cancelled
+func TestCallMiddleware_Cancelled(t *testing.T) {
+	s, started := blockingServer(t)
+	ctx := context.Background()
+
+	done := make(chan mcp.JSONRPCMessage)
+	go func() {
+		done <- s.HandleMessage(ctx, []byte(`{"jsonrpc":"2.0","id":7,"method":"tools/call","params":{"name":"block"}}`))
+	}()
+	<-started
+	s.HandleMessage(ctx, []byte(`{"jsonrpc":"2.0","method":"notifications/cancelled","params":{"requestId":7,"reason":"user aborted"}}`))
+
+	select {
+	case response := <-done:
+		result := callResult(t, response)
+		assert.True(t, result.IsError)
+		assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "block cancelled by the client")
+		assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "class: cancelled")
+	case <-time.After(5 * time.Second):
+		t.Fatal("call was not cancelled")
+	}
+}
+
+// TestCallMiddleware_Deadline ends calls that run past the tool timeout
+func TestCallMiddleware_Deadline(t *testing.T) {
+	defer func(timeout time.Duration) { flag.ToolTimeout = timeout }(flag.ToolTimeout)
+	flag.ToolTimeout = 20 * time.Millisecond
+	s, _ := blockingServer(t)
+
+	response := s.HandleMessage(context.Background(), []byte(`{"jsonrpc":"2.0","id":"a","method":"tools/call","params":{"name":"block"}}`))
+	result := callResult(t, response)
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "block: context deadline exceeded after 20ms")
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "class: timeout")
+}
+
+// TestToolTimeout tests the deadlines of long tools and all=true lists
+func TestToolTimeout(t *testing.T) {
+	defer func(timeout time.Duration) { flag.ToolTimeout = timeout }(flag.ToolTimeout)
+	flag.ToolTimeout = 0
+
+	call := func(name string, args map[string]any) mcp.CallToolRequest {
+		return mcp.CallToolRequest{Params: mcp.CallToolParams{Name: name, Arguments: args}}
+	}
+	timeout, long := toolTimeout(call("get_issue", nil))
+	assert.Equal(t, DefaultToolTimeout, timeout)
+	assert.False(t, long)
+
+	timeout, long = toolTimeout(call("list_repo_issues", map[string]any{"all": true}))
+	assert.Equal(t, allPagesTimeout, timeout)
+	assert.False(t, long)
+
+	timeout, long = toolTimeout(call("migrate_repo", nil))
+	assert.Equal(t, 15*time.Minute, timeout)
+	assert.True(t, long)
+
+	flag.ToolTimeout = time.Hour
+	timeout, _ = toolTimeout(call("migrate_repo", nil))
+	assert.Equal(t, time.Hour, timeout)
+}
@@ -0,0 +1,235 @@
+package issue
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/url"
+
+	"codeberg.org/goern/forgejo-mcp/v2/operation/params"
+	"codeberg.org/goern/forgejo-mcp/v2/pkg/forgejo"
+	"codeberg.org/goern/forgejo-mcp/v2/pkg/log"
+	"codeberg.org/goern/forgejo-mcp/v2/pkg/to"
+
+	forgejo_sdk "codeberg.org/mvdkleijn/forgejo-sdk/forgejo/v2"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const (
+	ListIssueAttachmentsToolName    = "list_issue_attachments"
+	DownloadIssueAttachmentToolName = "download_issue_attachment"
+	UploadIssueAttachmentToolName   = "upload_issue_attachment"
+	DeleteIssueAttachmentToolName   = "delete_issue_attachment"
+
+	// maxAttachmentSize bounds downloads returned through a tool result
+	maxAttachmentSize = 10 << 20
+)
+
+var (
+	ListIssueAttachmentsTool = mcp.NewTool(
+		ListIssueAttachmentsToolName,
+		mcp.WithDescription("List attachments of an issue or comment"),
+		to.WithResult[*to.Page[*forgejo_sdk.Attachment]](),
+		mcp.WithString("owner", mcp.Required(), mcp.Description(params.Owner)),
+		mcp.WithString("repo", mcp.Required(), mcp.Description(params.Repo)),
+		mcp.WithNumber("index", mcp.Description(params.TargetIndex)),
+		mcp.WithNumber("comment_id", mcp.Description(params.TargetCommentID)),
+	)
+
+	DownloadIssueAttachmentTool = mcp.NewTool(
+		DownloadIssueAttachmentToolName,
+		mcp.WithDescription("Download attachment (text inline, binary as blob resource)"),
+		to.WithResult[*AttachmentContent](),
+		mcp.WithString("owner", mcp.Required(), mcp.Description(params.Owner)),
+		mcp.WithString("repo", mcp.Required(), mcp.Description(params.Repo)),
+		mcp.WithNumber("index", mcp.Description(params.TargetIndex)),
+		mcp.WithNumber("comment_id", mcp.Description(params.TargetCommentID)),
+		mcp.WithNumber("id", mcp.Required(), mcp.Description(params.AttachmentID)),
+	)
+
+	UploadIssueAttachmentTool = mcp.NewTool(
+		UploadIssueAttachmentToolName,
+		mcp.WithDescription("Upload attachment to an issue or comment"),
+		to.WithResult[*forgejo_sdk.Attachment](),
+		mcp.WithString("owner", mcp.Required(), mcp.Description(params.Owner)),
+		mcp.WithString("repo", mcp.Required(), mcp.Description(params.Repo)),
+		mcp.WithNumber("index", mcp.Description(params.TargetIndex)),
+		mcp.WithNumber("comment_id", mcp.Description(params.TargetCommentID)),
+		mcp.WithString("name", mcp.Required(), mcp.Description("File name")),
+		mcp.WithString("content", mcp.Required(), mcp.Description("File content")),
+		mcp.WithString("encoding", mcp.Description("Content encoding (text|base64)"), mcp.DefaultString("text")),
+	)
+
+	DeleteIssueAttachmentTool = mcp.NewTool(
+		DeleteIssueAttachmentToolName,
+		mcp.WithDescription("Delete attachment of an issue or comment"),
+		to.WithResult[string](),
+		mcp.WithString("owner", mcp.Required(), mcp.Description(params.Owner)),
+		mcp.WithString("repo", mcp.Required(), mcp.Description(params.Repo)),
+		mcp.WithNumber("index", mcp.Description(params.TargetIndex)),
+		mcp.WithNumber("comment_id", mcp.Description(params.TargetCommentID)),
+		mcp.WithNumber("id", mcp.Required(), mcp.Description(params.AttachmentID)),
+	)
+)
+
+// AttachmentContent is a downloaded attachment. Content is only set for text
+// attachments; binary data follows as an embedded blob resource.
+type AttachmentContent struct {
+	Attachment *forgejo_sdk.Attachment `json:"attachment"`
+	MIMEType   string                  `json:"mime_type"`
+	Content    string                  `json:"content,omitempty"`
+}
+
+// assetsPath returns the attachment API path of an issue, or of a comment
+// when commentID is set
+func assetsPath(owner, repo string, index, commentID int64) string {
+	if commentID != 0 {
+		return fmt.Sprintf("/repos/%s/%s/issues/comments/%d/assets", url.PathEscape(owner), url.PathEscape(repo), commentID)
+	}
+	return issuePath(owner, repo, index, "assets")
+}
+
+// decodeUploadContent turns the content argument into bytes
+func decodeUploadContent(content, encoding string) ([]byte, error) {
+	switch encoding {
+	case "", "text":
+		return []byte(content), nil
+	case "base64":
+		data, err := base64.StdEncoding.DecodeString(content)
+		if err != nil {
+			return nil, to.InvalidArgument("invalid base64 content: %v", err)
+		}
+		return data, nil
+	}
+	return nil, to.InvalidArgument("invalid encoding '%s': must be text or base64", encoding)
+}
+
+func ListIssueAttachmentsFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called ListIssueAttachmentsFn")
+	owner, err := req.RequireString("owner")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	repo, err := req.RequireString("repo")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	index, commentID, err := issueOrComment(req)
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+
+	attachments := []*forgejo_sdk.Attachment{}
+	_, err = forgejo.Do(ctx, "GET", assetsPath(owner, repo, index, commentID), nil, nil, &attachments)
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("list attachments err: %v", err))
+	}
+	return to.TextResult(to.SinglePage(attachments))
+}
+
+func DownloadIssueAttachmentFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called DownloadIssueAttachmentFn")
+	owner, err := req.RequireString("owner")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	repo, err := req.RequireString("repo")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	index, commentID, err := issueOrComment(req)
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	id, err := req.RequireFloat("id")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+
+	attachment := &forgejo_sdk.Attachment{}
+	_, err = forgejo.Do(ctx, "GET", fmt.Sprintf("%s/%d", assetsPath(owner, repo, index, commentID), int64(id)), nil, nil, attachment)
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("get attachment err: %v", err))
+	}
+
+	data, contentType, err := forgejo.Download(ctx, attachment.DownloadURL, maxAttachmentSize)
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("download attachment err: %v", err))
+	}
+
+	mimeType := to.MIMEType(attachment.Name, contentType, data)
+	if to.IsText(mimeType, data) {
+		return to.TextResult(&AttachmentContent{
+			Attachment: attachment,
+			MIMEType:   mimeType,
+			Content:    string(data),
+		})
+	}
+	return to.BlobResult(&AttachmentContent{
+		Attachment: attachment,
+		MIMEType:   mimeType,
+	}, attachment.DownloadURL, mimeType, data)
+}
+
+func UploadIssueAttachmentFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called UploadIssueAttachmentFn")
+	owner, err := req.RequireString("owner")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	repo, err := req.RequireString("repo")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	index, commentID, err := issueOrComment(req)
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	name, err := req.RequireString("name")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	content, err := req.RequireString("content")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	data, err := decodeUploadContent(content, req.GetString("encoding", "text"))
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+
+	query := url.Values{}
+	query.Set("name", name)
+	attachment := &forgejo_sdk.Attachment{}
+	_, err = forgejo.DoUpload(ctx, assetsPath(owner, repo, index, commentID), query, "attachment", name, data, attachment)
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("upload attachment err: %v", err))
+	}
+	return to.TextResult(attachment)
+}
+
+func DeleteIssueAttachmentFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called DeleteIssueAttachmentFn")
+	owner, err := req.RequireString("owner")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	repo, err := req.RequireString("repo")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	index, commentID, err := issueOrComment(req)
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	id, err := req.RequireFloat("id")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+
+	_, err = forgejo.Do(ctx, "DELETE", fmt.Sprintf("%s/%d", assetsPath(owner, repo, index, commentID), int64(id)), nil, nil, nil)
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("delete attachment err: %v", err))
+	}
+	return to.TextResult("Delete attachment success")
+}
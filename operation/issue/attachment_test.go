@@ -0,0 +1,55 @@
+package issue
+
+import (
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestAssetsPath tests issue and comment attachment paths
+func TestAssetsPath(t *testing.T) {
+	assert.Equal(t, "/repos/acme/website/issues/4/assets", assetsPath("acme", "website", 4, 0))
+	assert.Equal(t, "/repos/acme/website/issues/comments/99/assets", assetsPath("acme", "website", 0, 99))
+}
+
+// TestDecodeUploadContent tests text and base64 upload content
+func TestDecodeUploadContent(t *testing.T) {
+	data, err := decodeUploadContent("hello", "text")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("hello"), data)
+
+	data, err = decodeUploadContent("AAEC/w==", "base64")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{0, 1, 2, 255}, data)
+
+	_, err = decodeUploadContent("not base64!", "base64")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid base64 content")
+
+	_, err = decodeUploadContent("hello", "hex")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid encoding")
+}
+
+// TestUploadIssueAttachmentFn_InvalidEncoding tests encoding validation before any API call
+func TestUploadIssueAttachmentFn_InvalidEncoding(t *testing.T) {
+	req := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Arguments: map[string]interface{}{
+				"owner":    "acme",
+				"repo":     "website",
+				"index":    float64(1),
+				"name":     "a.bin",
+				"content":  "AAEC",
+				"encoding": "hex",
+			},
+		},
+	}
+
+	result, err := UploadIssueAttachmentFn(nil, req)
+	assert.NoError(t, err)
+	require.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "invalid encoding")
+}
@@ -0,0 +1,417 @@
+package issue
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"codeberg.org/goern/forgejo-mcp/v2/operation/params"
+	"codeberg.org/goern/forgejo-mcp/v2/pkg/forgejo"
+	"codeberg.org/goern/forgejo-mcp/v2/pkg/log"
+	"codeberg.org/goern/forgejo-mcp/v2/pkg/to"
+
+	forgejo_sdk "codeberg.org/mvdkleijn/forgejo-sdk/forgejo/v2"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const (
+	ListIssueReactionsToolName   = "list_issue_reactions"
+	AddIssueReactionToolName     = "add_issue_reaction"
+	RemoveIssueReactionToolName  = "remove_issue_reaction"
+	ListIssueSubscribersToolName = "list_issue_subscribers"
+	SubscribeIssueToolName       = "subscribe_issue"
+	UnsubscribeIssueToolName     = "unsubscribe_issue"
+	ListPinnedIssuesToolName     = "list_pinned_issues"
+	PinIssueToolName             = "pin_issue"
+	UnpinIssueToolName           = "unpin_issue"
+	MovePinnedIssueToolName      = "move_pinned_issue"
+	LockIssueToolName            = "lock_issue"
+	UnlockIssueToolName          = "unlock_issue"
+)
+
+var (
+	ListIssueReactionsTool = mcp.NewTool(
+		ListIssueReactionsToolName,
+		mcp.WithDescription("List reactions on an issue or comment"),
+		to.WithResult[*to.Page[*forgejo_sdk.Reaction]](),
+		mcp.WithString("owner", mcp.Required(), mcp.Description(params.Owner)),
+		mcp.WithString("repo", mcp.Required(), mcp.Description(params.Repo)),
+		mcp.WithNumber("index", mcp.Description(params.TargetIndex)),
+		mcp.WithNumber("comment_id", mcp.Description(params.TargetCommentID)),
+	)
+
+	AddIssueReactionTool = mcp.NewTool(
+		AddIssueReactionToolName,
+		mcp.WithDescription("Add reaction to an issue or comment"),
+		to.WithResult[*forgejo_sdk.Reaction](),
+		mcp.WithString("owner", mcp.Required(), mcp.Description(params.Owner)),
+		mcp.WithString("repo", mcp.Required(), mcp.Description(params.Repo)),
+		mcp.WithNumber("index", mcp.Description(params.TargetIndex)),
+		mcp.WithNumber("comment_id", mcp.Description(params.TargetCommentID)),
+		mcp.WithString("reaction", mcp.Required(), mcp.Description(params.Reaction)),
+	)
+
+	RemoveIssueReactionTool = mcp.NewTool(
+		RemoveIssueReactionToolName,
+		mcp.WithDescription("Remove my reaction from an issue or comment"),
+		to.WithResult[string](),
+		mcp.WithString("owner", mcp.Required(), mcp.Description(params.Owner)),
+		mcp.WithString("repo", mcp.Required(), mcp.Description(params.Repo)),
+		mcp.WithNumber("index", mcp.Description(params.TargetIndex)),
+		mcp.WithNumber("comment_id", mcp.Description(params.TargetCommentID)),
+		mcp.WithString("reaction", mcp.Required(), mcp.Description(params.Reaction)),
+	)
+
+	ListIssueSubscribersTool = mcp.NewTool(
+		ListIssueSubscribersToolName,
+		mcp.WithDescription("List issue subscribers"),
+		to.WithResult[*to.Page[*forgejo_sdk.User]](),
+		mcp.WithString("owner", mcp.Required(), mcp.Description(params.Owner)),
+		mcp.WithString("repo", mcp.Required(), mcp.Description(params.Repo)),
+		mcp.WithNumber("index", mcp.Required(), mcp.Description(params.Index)),
+	)
+
+	SubscribeIssueTool = mcp.NewTool(
+		SubscribeIssueToolName,
+		mcp.WithDescription("Subscribe a user to an issue"),
+		to.WithResult[string](),
+		mcp.WithString("owner", mcp.Required(), mcp.Description(params.Owner)),
+		mcp.WithString("repo", mcp.Required(), mcp.Description(params.Repo)),
+		mcp.WithNumber("index", mcp.Required(), mcp.Description(params.Index)),
+		mcp.WithString("user", mcp.Description("Username (default: me)")),
+	)
+
+	UnsubscribeIssueTool = mcp.NewTool(
+		UnsubscribeIssueToolName,
+		mcp.WithDescription("Unsubscribe a user from an issue"),
+		to.WithResult[string](),
+		mcp.WithString("owner", mcp.Required(), mcp.Description(params.Owner)),
+		mcp.WithString("repo", mcp.Required(), mcp.Description(params.Repo)),
+		mcp.WithNumber("index", mcp.Required(), mcp.Description(params.Index)),
+		mcp.WithString("user", mcp.Description("Username (default: me)")),
+	)
+
+	ListPinnedIssuesTool = mcp.NewTool(
+		ListPinnedIssuesToolName,
+		mcp.WithDescription("List pinned issues in pin order"),
+		to.WithResult[*to.Page[*forgejo_sdk.Issue]](),
+		mcp.WithString("owner", mcp.Required(), mcp.Description(params.Owner)),
+		mcp.WithString("repo", mcp.Required(), mcp.Description(params.Repo)),
+	)
+
+	PinIssueTool = mcp.NewTool(
+		PinIssueToolName,
+		mcp.WithDescription("Pin issue"),
+		to.WithResult[string](),
+		mcp.WithString("owner", mcp.Required(), mcp.Description(params.Owner)),
+		mcp.WithString("repo", mcp.Required(), mcp.Description(params.Repo)),
+		mcp.WithNumber("index", mcp.Required(), mcp.Description(params.Index)),
+	)
+
+	UnpinIssueTool = mcp.NewTool(
+		UnpinIssueToolName,
+		mcp.WithDescription("Unpin issue"),
+		to.WithResult[string](),
+		mcp.WithString("owner", mcp.Required(), mcp.Description(params.Owner)),
+		mcp.WithString("repo", mcp.Required(), mcp.Description(params.Repo)),
+		mcp.WithNumber("index", mcp.Required(), mcp.Description(params.Index)),
+	)
+
+	MovePinnedIssueTool = mcp.NewTool(
+		MovePinnedIssueToolName,
+		mcp.WithDescription("Move pinned issue to a new position"),
+		to.WithResult[string](),
+		mcp.WithString("owner", mcp.Required(), mcp.Description(params.Owner)),
+		mcp.WithString("repo", mcp.Required(), mcp.Description(params.Repo)),
+		mcp.WithNumber("index", mcp.Required(), mcp.Description(params.Index)),
+		mcp.WithNumber("position", mcp.Required(), mcp.Description("New position (1-based)"), mcp.Min(1)),
+	)
+
+	LockIssueTool = mcp.NewTool(
+		LockIssueToolName,
+		mcp.WithDescription("Lock issue conversation"),
+		to.WithResult[string](),
+		mcp.WithString("owner", mcp.Required(), mcp.Description(params.Owner)),
+		mcp.WithString("repo", mcp.Required(), mcp.Description(params.Repo)),
+		mcp.WithNumber("index", mcp.Required(), mcp.Description(params.Index)),
+		mcp.WithString("reason", mcp.Description("Reason, one of the instance's lock reasons as spelled there (default: Too heated, Off-topic, Resolved, Spam)")),
+	)
+
+	UnlockIssueTool = mcp.NewTool(
+		UnlockIssueToolName,
+		mcp.WithDescription("Unlock issue conversation"),
+		to.WithResult[string](),
+		mcp.WithString("owner", mcp.Required(), mcp.Description(params.Owner)),
+		mcp.WithString("repo", mcp.Required(), mcp.Description(params.Repo)),
+		mcp.WithNumber("index", mcp.Required(), mcp.Description(params.Index)),
+	)
+)
+
+// issueOrComment returns the issue index or comment ID a tool acts
+// on. Exactly one of them must be given; the other is returned as 0.
+func issueOrComment(req mcp.CallToolRequest) (index, commentID int64, err error) {
+	args := req.GetArguments()
+	_, hasIndex := args["index"]
+	_, hasComment := args["comment_id"]
+	if hasIndex == hasComment {
+		return 0, 0, to.InvalidArgument("exactly one of index or comment_id is required")
+	}
+	if hasIndex {
+		i, err := req.RequireFloat("index")
+		return int64(i), 0, err
+	}
+	c, err := req.RequireFloat("comment_id")
+	return 0, int64(c), err
+}
+
+// issueArgs reads the owner, repo and index arguments that identify an issue
+func issueArgs(req mcp.CallToolRequest) (owner, repo string, index int64, err error) {
+	if owner, err = req.RequireString("owner"); err != nil {
+		return
+	}
+	if repo, err = req.RequireString("repo"); err != nil {
+		return
+	}
+	i, err := req.RequireFloat("index")
+	return owner, repo, int64(i), err
+}
+
+func issuePath(owner, repo string, index int64, suffix string) string {
+	return fmt.Sprintf("/repos/%s/%s/issues/%d/%s", url.PathEscape(owner), url.PathEscape(repo), index, suffix)
+}
+
+func ListIssueReactionsFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called ListIssueReactionsFn")
+	owner, err := req.RequireString("owner")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	repo, err := req.RequireString("repo")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	index, commentID, err := issueOrComment(req)
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+
+	var reactions []*forgejo_sdk.Reaction
+	if commentID != 0 {
+		reactions, _, err = forgejo.ClientCtx(ctx).GetIssueCommentReactions(owner, repo, commentID)
+	} else {
+		reactions, _, err = forgejo.ClientCtx(ctx).GetIssueReactions(owner, repo, index)
+	}
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("list reactions err: %v", err))
+	}
+	return to.TextResult(to.SinglePage(reactions))
+}
+
+func AddIssueReactionFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called AddIssueReactionFn")
+	owner, err := req.RequireString("owner")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	repo, err := req.RequireString("repo")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	index, commentID, err := issueOrComment(req)
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	reaction, err := req.RequireString("reaction")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+
+	var result *forgejo_sdk.Reaction
+	if commentID != 0 {
+		result, _, err = forgejo.ClientCtx(ctx).PostIssueCommentReaction(owner, repo, commentID, reaction)
+	} else {
+		result, _, err = forgejo.ClientCtx(ctx).PostIssueReaction(owner, repo, index, reaction)
+	}
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("add reaction err: %v", err))
+	}
+	return to.TextResult(result)
+}
+
+func RemoveIssueReactionFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called RemoveIssueReactionFn")
+	owner, err := req.RequireString("owner")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	repo, err := req.RequireString("repo")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	index, commentID, err := issueOrComment(req)
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	reaction, err := req.RequireString("reaction")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+
+	if commentID != 0 {
+		_, err = forgejo.ClientCtx(ctx).DeleteIssueCommentReaction(owner, repo, commentID, reaction)
+	} else {
+		_, err = forgejo.ClientCtx(ctx).DeleteIssueReaction(owner, repo, index, reaction)
+	}
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("remove reaction err: %v", err))
+	}
+	return to.TextResult("Remove reaction success")
+}
+
+func ListIssueSubscribersFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called ListIssueSubscribersFn")
+	owner, repo, index, err := issueArgs(req)
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+
+	users, _, err := forgejo.ClientCtx(ctx).GetIssueSubscribers(owner, repo, index)
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("list issue subscribers err: %v", err))
+	}
+	return to.TextResult(to.SinglePage(users))
+}
+
+func SubscribeIssueFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called SubscribeIssueFn")
+	owner, repo, index, err := issueArgs(req)
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+
+	if user := req.GetString("user", ""); user != "" {
+		_, err = forgejo.ClientCtx(ctx).AddIssueSubscription(owner, repo, index, user)
+	} else {
+		_, err = forgejo.ClientCtx(ctx).IssueSubscribe(owner, repo, index)
+	}
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("subscribe issue err: %v", err))
+	}
+	return to.TextResult("Subscribe issue success")
+}
+
+func UnsubscribeIssueFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called UnsubscribeIssueFn")
+	owner, repo, index, err := issueArgs(req)
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+
+	if user := req.GetString("user", ""); user != "" {
+		_, err = forgejo.ClientCtx(ctx).DeleteIssueSubscription(owner, repo, index, user)
+	} else {
+		_, err = forgejo.ClientCtx(ctx).IssueUnSubscribe(owner, repo, index)
+	}
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("unsubscribe issue err: %v", err))
+	}
+	return to.TextResult("Unsubscribe issue success")
+}
+
+func ListPinnedIssuesFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called ListPinnedIssuesFn")
+	owner, err := req.RequireString("owner")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	repo, err := req.RequireString("repo")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+
+	issues := []*forgejo_sdk.Issue{}
+	path := fmt.Sprintf("/repos/%s/%s/issues/pinned", url.PathEscape(owner), url.PathEscape(repo))
+	_, err = forgejo.Do(ctx, "GET", path, nil, nil, &issues)
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("list pinned issues err: %v", err))
+	}
+	return to.TextResult(to.SinglePage(issues))
+}
+
+func PinIssueFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called PinIssueFn")
+	owner, repo, index, err := issueArgs(req)
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+
+	_, err = forgejo.Do(ctx, "POST", issuePath(owner, repo, index, "pin"), nil, nil, nil)
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("pin issue err: %v", err))
+	}
+	return to.TextResult("Pin issue success")
+}
+
+func UnpinIssueFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called UnpinIssueFn")
+	owner, repo, index, err := issueArgs(req)
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+
+	_, err = forgejo.Do(ctx, "DELETE", issuePath(owner, repo, index, "pin"), nil, nil, nil)
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("unpin issue err: %v", err))
+	}
+	return to.TextResult("Unpin issue success")
+}
+
+func MovePinnedIssueFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called MovePinnedIssueFn")
+	owner, repo, index, err := issueArgs(req)
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	position, err := req.RequireFloat("position")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	if position < 1 {
+		return to.ErrorResult(to.InvalidArgument("position must be at least 1"))
+	}
+
+	_, err = forgejo.Do(ctx, "PATCH", issuePath(owner, repo, index, fmt.Sprintf("pin/%d", int64(position))), nil, nil, nil)
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("move pinned issue err: %v", err))
+	}
+	return to.TextResult(fmt.Sprintf("Pinned issue moved to position %d", int64(position)))
+}
+
+func LockIssueFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called LockIssueFn")
+	owner, repo, index, err := issueArgs(req)
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	// Lock reasons are configurable per instance, so Forgejo validates them
+	body := map[string]string{"lock_reason": req.GetString("reason", "")}
+	_, err = forgejo.Do(ctx, "PUT", issuePath(owner, repo, index, "lock"), nil, body, nil)
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("lock issue err: %v", err))
+	}
+	return to.TextResult("Lock issue success")
+}
+
+func UnlockIssueFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called UnlockIssueFn")
+	owner, repo, index, err := issueArgs(req)
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+
+	_, err = forgejo.Do(ctx, "DELETE", issuePath(owner, repo, index, "lock"), nil, nil, nil)
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("unlock issue err: %v", err))
+	}
+	return to.TextResult("Unlock issue success")
+}
@@ -0,0 +1,84 @@
+package issue
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"codeberg.org/goern/forgejo-mcp/v2/pkg/flag"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestIssueOrComment tests that exactly one of index or comment_id is accepted
+func TestIssueOrComment(t *testing.T) {
+	tests := []struct {
+		name      string
+		args      map[string]interface{}
+		index     int64
+		commentID int64
+		wantErr   bool
+	}{
+		{name: "issue", args: map[string]interface{}{"index": float64(4)}, index: 4},
+		{name: "comment", args: map[string]interface{}{"comment_id": float64(99)}, commentID: 99},
+		{name: "neither", args: map[string]interface{}{}, wantErr: true},
+		{name: "both", args: map[string]interface{}{"index": float64(4), "comment_id": float64(99)}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := mcp.CallToolRequest{
+				Params: mcp.CallToolParams{
+					Arguments: tt.args,
+				},
+			}
+
+			index, commentID, err := issueOrComment(req)
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), "exactly one of index or comment_id")
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.index, index)
+			assert.Equal(t, tt.commentID, commentID)
+		})
+	}
+}
+
+// TestLockIssueFn_Reason tests that the reason reaches Forgejo as given,
+// since the instance decides which lock reasons are valid
+func TestLockIssueFn_Reason(t *testing.T) {
+	var got map[string]string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut || r.URL.Path != "/api/v1/repos/acme/website/issues/1/lock" {
+			http.NotFound(w, r)
+			return
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+	defer func(url string) { flag.URL = url }(flag.URL)
+	flag.URL = srv.URL
+
+	req := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Arguments: map[string]interface{}{
+				"owner":  "acme",
+				"repo":   "website",
+				"index":  float64(1),
+				"reason": "Too heated",
+			},
+		},
+	}
+
+	result, err := LockIssueFn(context.Background(), req)
+	assert.NoError(t, err)
+	assert.False(t, result.IsError)
+	assert.Equal(t, map[string]string{"lock_reason": "Too heated"}, got)
+}
@@ -0,0 +1,437 @@
+package issue
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+
+	"codeberg.org/goern/forgejo-mcp/v2/operation/params"
+	"codeberg.org/goern/forgejo-mcp/v2/pkg/forgejo"
+	"codeberg.org/goern/forgejo-mcp/v2/pkg/log"
+	"codeberg.org/goern/forgejo-mcp/v2/pkg/paginate"
+	"codeberg.org/goern/forgejo-mcp/v2/pkg/to"
+
+	forgejo_sdk "codeberg.org/mvdkleijn/forgejo-sdk/forgejo/v2"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const (
+	ListIssueDependenciesToolName = "list_issue_dependencies"
+	AddIssueDependencyToolName    = "add_issue_dependency"
+	RemoveIssueDependencyToolName = "remove_issue_dependency"
+	GetIssueGraphToolName         = "get_issue_graph"
+
+	// maxGraphDepth bounds how many hops get_issue_graph walks from the issue
+	maxGraphDepth = 10
+	// maxGraphFetches bounds how many issues get_issue_graph lists the
+	// dependencies of, which with one page per direction bounds its API
+	// calls regardless of the size of the backlog
+	maxGraphFetches = 50
+	// graphPageSize is the page size get_issue_graph lists dependencies
+	// with, and the most dependencies it reads per issue and direction
+	graphPageSize = 50
+)
+
+var (
+	ListIssueDependenciesTool = mcp.NewTool(
+		ListIssueDependenciesToolName,
+		mcp.WithDescription("List issues blocking and blocked by an issue"),
+		to.WithResult[*IssueDependencies](),
+		mcp.WithString("owner", mcp.Required(), mcp.Description(params.Owner)),
+		mcp.WithString("repo", mcp.Required(), mcp.Description(params.Repo)),
+		mcp.WithNumber("index", mcp.Required(), mcp.Description(params.IssueIndex)),
+		mcp.WithNumber("page", mcp.Description(params.Page), mcp.DefaultNumber(1)),
+		mcp.WithNumber("limit", mcp.Description(params.Limit), mcp.DefaultNumber(20)),
+	)
+
+	AddIssueDependencyTool = mcp.NewTool(
+		AddIssueDependencyToolName,
+		mcp.WithDescription("Mark an issue as blocked by another issue"),
+		to.WithResult[string](),
+		mcp.WithString("owner", mcp.Required(), mcp.Description(params.Owner)),
+		mcp.WithString("repo", mcp.Required(), mcp.Description(params.Repo)),
+		mcp.WithNumber("index", mcp.Required(), mcp.Description("Blocked issue index")),
+		mcp.WithNumber("blocker_index", mcp.Required(), mcp.Description("Blocking issue index")),
+		mcp.WithString("blocker_owner", mcp.Description("Blocking issue owner (default: owner)")),
+		mcp.WithString("blocker_repo", mcp.Description("Blocking issue repo (default: repo)")),
+	)
+
+	RemoveIssueDependencyTool = mcp.NewTool(
+		RemoveIssueDependencyToolName,
+		mcp.WithDescription("Remove a blocking relationship between issues"),
+		to.WithResult[string](),
+		mcp.WithString("owner", mcp.Required(), mcp.Description(params.Owner)),
+		mcp.WithString("repo", mcp.Required(), mcp.Description(params.Repo)),
+		mcp.WithNumber("index", mcp.Required(), mcp.Description("Blocked issue index")),
+		mcp.WithNumber("blocker_index", mcp.Required(), mcp.Description("Blocking issue index")),
+		mcp.WithString("blocker_owner", mcp.Description("Blocking issue owner (default: owner)")),
+		mcp.WithString("blocker_repo", mcp.Description("Blocking issue repo (default: repo)")),
+	)
+
+	GetIssueGraphTool = mcp.NewTool(
+		GetIssueGraphToolName,
+		mcp.WithDescription("Walk issue dependencies and return a DAG summary with detected cycles"),
+		to.WithResult[*IssueGraph](),
+		mcp.WithString("owner", mcp.Required(), mcp.Description(params.Owner)),
+		mcp.WithString("repo", mcp.Required(), mcp.Description(params.Repo)),
+		mcp.WithNumber("index", mcp.Required(), mcp.Description(params.IssueIndex)),
+		mcp.WithNumber("depth", mcp.Description("Max hops from the issue (1-10)"), mcp.DefaultNumber(3), mcp.Min(1), mcp.Max(maxGraphDepth)),
+	)
+)
+
+// IssueRef identifies an issue across repositories
+type IssueRef struct {
+	Owner string `json:"owner"`
+	Repo  string `json:"repo"`
+	Index int64  `json:"index"`
+}
+
+func (r IssueRef) String() string {
+	return fmt.Sprintf("%s/%s#%d", r.Owner, r.Repo, r.Index)
+}
+
+// issueMeta is the request body Forgejo expects for dependency changes
+type issueMeta struct {
+	Owner string `json:"owner"`
+	Repo  string `json:"repo"`
+	Index int64  `json:"index"`
+}
+
+// IssueDependencies lists both directions of an issue's blocking
+// relationships, each paged on its own
+type IssueDependencies struct {
+	BlockedBy *to.Page[*forgejo_sdk.Issue] `json:"blocked_by"`
+	Blocks    *to.Page[*forgejo_sdk.Issue] `json:"blocks"`
+}
+
+// issueDependenciesView is the compact form of IssueDependencies
+type issueDependenciesView struct {
+	BlockedBy *to.Page[*to.IssueView] `json:"blocked_by"`
+	Blocks    *to.Page[*to.IssueView] `json:"blocks"`
+}
+
+func (d *IssueDependencies) CompactView() any {
+	if d == nil {
+		return (*issueDependenciesView)(nil)
+	}
+	return &issueDependenciesView{BlockedBy: compactIssues(d.BlockedBy), Blocks: compactIssues(d.Blocks)}
+}
+
+func compactIssues(p *to.Page[*forgejo_sdk.Issue]) *to.Page[*to.IssueView] {
+	if p == nil {
+		return nil
+	}
+	return to.Reshape(p, to.View[[]*to.IssueView](p.Items))
+}
+
+// IssueGraphNode is one issue in the dependency graph
+type IssueGraphNode struct {
+	Ref   string `json:"ref"`
+	Title string `json:"title"`
+	State string `json:"state"`
+	Depth int    `json:"depth"`
+}
+
+// IssueGraphEdge points from a blocking issue to the issue it blocks
+type IssueGraphEdge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// IssueGraph is the summary returned by get_issue_graph
+type IssueGraph struct {
+	Root      string            `json:"root"`
+	Nodes     []*IssueGraphNode `json:"nodes"`
+	Edges     []IssueGraphEdge  `json:"edges"`
+	Cycles    [][]string        `json:"cycles"`
+	IsDAG     bool              `json:"is_dag"`
+	Truncated bool              `json:"truncated"`
+}
+
+func dependencyPath(ref IssueRef, kind string) string {
+	return fmt.Sprintf("/repos/%s/%s/issues/%d/%s", url.PathEscape(ref.Owner), url.PathEscape(ref.Repo), ref.Index, kind)
+}
+
+// fetchDependencies returns the issues blocking ref and the issues ref
+// blocks, both paged with paging
+func fetchDependencies(ctx context.Context, ref IssueRef, paging paginate.Options) (*IssueDependencies, error) {
+	list := func(kind string) (*to.Page[*forgejo_sdk.Issue], error) {
+		return paginate.Fetch(ctx, paging, func(page, limit int) ([]*forgejo_sdk.Issue, *http.Response, error) {
+			issues := []*forgejo_sdk.Issue{}
+			resp, err := forgejo.Do(ctx, "GET", dependencyPath(ref, kind), paginate.Query(nil, page, limit), nil, &issues)
+			return issues, resp, err
+		})
+	}
+
+	blockedBy, err := list("dependencies")
+	if err != nil {
+		return nil, err
+	}
+	blocks, err := list("blocks")
+	if err != nil {
+		return nil, err
+	}
+	return &IssueDependencies{BlockedBy: blockedBy, Blocks: blocks}, nil
+}
+
+// issueRefOf builds the reference of an issue returned by the API, falling
+// back to the repo it was listed from
+func issueRefOf(issue *forgejo_sdk.Issue, fallback IssueRef) IssueRef {
+	ref := IssueRef{Owner: fallback.Owner, Repo: fallback.Repo, Index: issue.Index}
+	if issue.Repository != nil && issue.Repository.Owner != "" && issue.Repository.Name != "" {
+		ref.Owner = issue.Repository.Owner
+		ref.Repo = issue.Repository.Name
+	}
+	return ref
+}
+
+// buildIssueGraph walks blocking relationships in both directions, up to
+// depth hops from root and maxFetches calls of fetch, and reports any
+// cycles among the visited issues. The graph is truncated at either limit
+// and when a dependency list was cut off.
+func buildIssueGraph(root IssueRef, rootIssue *forgejo_sdk.Issue, depth, maxFetches int, fetch func(IssueRef) (*IssueDependencies, error)) (*IssueGraph, error) {
+	graph := &IssueGraph{
+		Root:   root.String(),
+		Nodes:  []*IssueGraphNode{},
+		Edges:  []IssueGraphEdge{},
+		Cycles: [][]string{},
+	}
+	nodes := map[string]*IssueGraphNode{}
+	edges := map[IssueGraphEdge]bool{}
+	addNode := func(ref IssueRef, issue *forgejo_sdk.Issue, d int) bool {
+		key := ref.String()
+		if _, ok := nodes[key]; ok {
+			return false
+		}
+		node := &IssueGraphNode{Ref: key, Depth: d}
+		if issue != nil {
+			node.Title = issue.Title
+			node.State = string(issue.State)
+		}
+		nodes[key] = node
+		graph.Nodes = append(graph.Nodes, node)
+		return true
+	}
+	addEdge := func(from, to IssueRef) {
+		edge := IssueGraphEdge{From: from.String(), To: to.String()}
+		if !edges[edge] {
+			edges[edge] = true
+			graph.Edges = append(graph.Edges, edge)
+		}
+	}
+
+	addNode(root, rootIssue, 0)
+	frontier := []IssueRef{root}
+	fetches := 0
+walk:
+	for d := 0; len(frontier) > 0; d++ {
+		if d == depth {
+			// Nodes at the depth limit may have unexplored neighbours
+			graph.Truncated = true
+			break
+		}
+		var next []IssueRef
+		for _, ref := range frontier {
+			if fetches == maxFetches {
+				// The rest of the frontier stays unexplored
+				graph.Truncated = true
+				break walk
+			}
+			fetches++
+			deps, err := fetch(ref)
+			if err != nil {
+				return nil, fmt.Errorf("fetch dependencies of %s: %v", ref, err)
+			}
+			if deps.BlockedBy.HasMore || deps.Blocks.HasMore {
+				graph.Truncated = true
+			}
+			for _, issue := range deps.BlockedBy.Items {
+				blocker := issueRefOf(issue, ref)
+				addEdge(blocker, ref)
+				if addNode(blocker, issue, d+1) {
+					next = append(next, blocker)
+				}
+			}
+			for _, issue := range deps.Blocks.Items {
+				blocked := issueRefOf(issue, ref)
+				addEdge(ref, blocked)
+				if addNode(blocked, issue, d+1) {
+					next = append(next, blocked)
+				}
+			}
+		}
+		frontier = next
+	}
+
+	graph.Cycles = findCycles(graph.Edges)
+	graph.IsDAG = len(graph.Cycles) == 0
+	return graph, nil
+}
+
+// findCycles runs a depth-first search over the edges and returns one cycle
+// per back edge, each listed from its first node back to itself
+func findCycles(edges []IssueGraphEdge) [][]string {
+	adjacency := map[string][]string{}
+	var keys []string
+	for _, edge := range edges {
+		if _, ok := adjacency[edge.From]; !ok {
+			keys = append(keys, edge.From)
+		}
+		adjacency[edge.From] = append(adjacency[edge.From], edge.To)
+	}
+	sort.Strings(keys)
+
+	const (
+		unvisited = iota
+		onStack
+		done
+	)
+	state := map[string]int{}
+	var stack []string
+	cycles := [][]string{}
+
+	var visit func(node string)
+	visit = func(node string) {
+		state[node] = onStack
+		stack = append(stack, node)
+		for _, next := range adjacency[node] {
+			switch state[next] {
+			case unvisited:
+				visit(next)
+			case onStack:
+				for i := len(stack) - 1; i >= 0; i-- {
+					if stack[i] == next {
+						cycle := append([]string{}, stack[i:]...)
+						cycles = append(cycles, append(cycle, next))
+						break
+					}
+				}
+			}
+		}
+		stack = stack[:len(stack)-1]
+		state[node] = done
+	}
+	for _, key := range keys {
+		if state[key] == unvisited {
+			visit(key)
+		}
+	}
+	return cycles
+}
+
+// dependencyArgs reads the blocked issue and its blocker from the request
+func dependencyArgs(req mcp.CallToolRequest) (IssueRef, issueMeta, error) {
+	owner, err := req.RequireString("owner")
+	if err != nil {
+		return IssueRef{}, issueMeta{}, err
+	}
+	repo, err := req.RequireString("repo")
+	if err != nil {
+		return IssueRef{}, issueMeta{}, err
+	}
+	index, err := req.RequireFloat("index")
+	if err != nil {
+		return IssueRef{}, issueMeta{}, err
+	}
+	blockerIndex, err := req.RequireFloat("blocker_index")
+	if err != nil {
+		return IssueRef{}, issueMeta{}, err
+	}
+
+	blocker := issueMeta{
+		Owner: req.GetString("blocker_owner", owner),
+		Repo:  req.GetString("blocker_repo", repo),
+		Index: int64(blockerIndex),
+	}
+	return IssueRef{Owner: owner, Repo: repo, Index: int64(index)}, blocker, nil
+}
+
+func ListIssueDependenciesFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called ListIssueDependenciesFn")
+	owner, err := req.RequireString("owner")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	repo, err := req.RequireString("repo")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	index, err := req.RequireFloat("index")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	paging, err := paginate.FromRequest(req, 20)
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+
+	deps, err := fetchDependencies(ctx, IssueRef{Owner: owner, Repo: repo, Index: int64(index)}, paging)
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("list issue dependencies err: %v", err))
+	}
+	return to.TextResult(deps)
+}
+
+func AddIssueDependencyFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called AddIssueDependencyFn")
+	blocked, blocker, err := dependencyArgs(req)
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+
+	_, err = forgejo.Do(ctx, "POST", dependencyPath(blocked, "dependencies"), nil, blocker, nil)
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("add issue dependency err: %v", err))
+	}
+	return to.TextResult(fmt.Sprintf("%s is now blocked by %s/%s#%d", blocked, blocker.Owner, blocker.Repo, blocker.Index))
+}
+
+func RemoveIssueDependencyFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called RemoveIssueDependencyFn")
+	blocked, blocker, err := dependencyArgs(req)
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+
+	_, err = forgejo.Do(ctx, "DELETE", dependencyPath(blocked, "dependencies"), nil, blocker, nil)
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("remove issue dependency err: %v", err))
+	}
+	return to.TextResult(fmt.Sprintf("%s is no longer blocked by %s/%s#%d", blocked, blocker.Owner, blocker.Repo, blocker.Index))
+}
+
+func GetIssueGraphFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called GetIssueGraphFn")
+	owner, err := req.RequireString("owner")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	repo, err := req.RequireString("repo")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	index, err := req.RequireFloat("index")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	depth := int(req.GetFloat("depth", 3))
+	if depth < 1 || depth > maxGraphDepth {
+		return to.ErrorResult(to.InvalidArgument("depth must be between 1 and %d", maxGraphDepth))
+	}
+
+	root := IssueRef{Owner: owner, Repo: repo, Index: int64(index)}
+	issue, _, err := forgejo.ClientCtx(ctx).GetIssue(owner, repo, int64(index))
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("get issue err: %v", err))
+	}
+
+	graph, err := buildIssueGraph(root, issue, depth, maxGraphFetches, func(ref IssueRef) (*IssueDependencies, error) {
+		return fetchDependencies(ctx, ref, paginate.Options{Page: 1, Limit: graphPageSize})
+	})
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("get issue graph err: %v", err))
+	}
+	return to.TextResult(graph)
+}
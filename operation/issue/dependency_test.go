@@ -0,0 +1,139 @@
+package issue
+
+import (
+	"fmt"
+	"testing"
+
+	"codeberg.org/goern/forgejo-mcp/v2/pkg/to"
+
+	forgejo_sdk "codeberg.org/mvdkleijn/forgejo-sdk/forgejo/v2"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeDependencies serves blocking relationships from an in-memory edge list
+// of blocker -> blocked issue indexes in owner/repo
+func fakeDependencies(edges [][2]int64, calls *int) func(IssueRef) (*IssueDependencies, error) {
+	return func(ref IssueRef) (*IssueDependencies, error) {
+		*calls++
+		var blockedBy, blocks []*forgejo_sdk.Issue
+		for _, edge := range edges {
+			if edge[1] == ref.Index {
+				blockedBy = append(blockedBy, &forgejo_sdk.Issue{Index: edge[0], Title: fmt.Sprintf("issue %d", edge[0])})
+			}
+			if edge[0] == ref.Index {
+				blocks = append(blocks, &forgejo_sdk.Issue{Index: edge[1], Title: fmt.Sprintf("issue %d", edge[1])})
+			}
+		}
+		return &IssueDependencies{BlockedBy: to.SinglePage(blockedBy), Blocks: to.SinglePage(blocks)}, nil
+	}
+}
+
+// TestBuildIssueGraph_DAG tests a graph without cycles
+func TestBuildIssueGraph_DAG(t *testing.T) {
+	calls := 0
+	root := IssueRef{Owner: "o", Repo: "r", Index: 2}
+	graph, err := buildIssueGraph(root, &forgejo_sdk.Issue{Index: 2}, 5, maxGraphFetches, fakeDependencies([][2]int64{{1, 2}, {2, 3}, {1, 3}}, &calls))
+
+	assert.NoError(t, err)
+	assert.True(t, graph.IsDAG)
+	assert.False(t, graph.Truncated)
+	assert.Empty(t, graph.Cycles)
+	assert.Len(t, graph.Nodes, 3)
+	assert.ElementsMatch(t, []IssueGraphEdge{
+		{From: "o/r#1", To: "o/r#2"},
+		{From: "o/r#2", To: "o/r#3"},
+		{From: "o/r#1", To: "o/r#3"},
+	}, graph.Edges)
+}
+
+// TestBuildIssueGraph_Cycle tests that a cycle is detected and reported
+func TestBuildIssueGraph_Cycle(t *testing.T) {
+	calls := 0
+	root := IssueRef{Owner: "o", Repo: "r", Index: 1}
+	graph, err := buildIssueGraph(root, nil, 5, maxGraphFetches, fakeDependencies([][2]int64{{1, 2}, {2, 3}, {3, 1}}, &calls))
+
+	assert.NoError(t, err)
+	assert.False(t, graph.IsDAG)
+	assert.Equal(t, [][]string{{"o/r#1", "o/r#2", "o/r#3", "o/r#1"}}, graph.Cycles)
+}
+
+// TestBuildIssueGraph_DepthLimit tests that the walk stops at the depth limit
+func TestBuildIssueGraph_DepthLimit(t *testing.T) {
+	calls := 0
+	root := IssueRef{Owner: "o", Repo: "r", Index: 1}
+	graph, err := buildIssueGraph(root, nil, 2, maxGraphFetches, fakeDependencies([][2]int64{{1, 2}, {2, 3}, {3, 4}, {4, 5}}, &calls))
+
+	assert.NoError(t, err)
+	assert.True(t, graph.Truncated)
+	assert.Len(t, graph.Nodes, 3)
+	assert.Equal(t, 2, calls)
+}
+
+// TestBuildIssueGraph_FetchLimit tests that the walk stops once the fetch
+// budget is spent, even within the depth limit
+func TestBuildIssueGraph_FetchLimit(t *testing.T) {
+	calls := 0
+	root := IssueRef{Owner: "o", Repo: "r", Index: 1}
+	graph, err := buildIssueGraph(root, nil, 10, 3, fakeDependencies([][2]int64{{1, 2}, {1, 3}, {1, 4}, {2, 5}, {3, 6}, {4, 7}}, &calls))
+
+	assert.NoError(t, err)
+	assert.True(t, graph.Truncated)
+	assert.Equal(t, 3, calls)
+	assert.Len(t, graph.Nodes, 6)
+
+	calls = 0
+	graph, err = buildIssueGraph(root, nil, 10, 7, fakeDependencies([][2]int64{{1, 2}, {1, 3}, {1, 4}, {2, 5}, {3, 6}, {4, 7}}, &calls))
+	assert.NoError(t, err)
+	assert.False(t, graph.Truncated)
+	assert.Len(t, graph.Nodes, 7)
+}
+
+// TestBuildIssueGraph_ItemCap tests that a dependency list cut off at the
+// item cap marks the graph as truncated
+func TestBuildIssueGraph_ItemCap(t *testing.T) {
+	root := IssueRef{Owner: "o", Repo: "r", Index: 1}
+	graph, err := buildIssueGraph(root, nil, 5, maxGraphFetches, func(ref IssueRef) (*IssueDependencies, error) {
+		blocks := to.SinglePage([]*forgejo_sdk.Issue{})
+		if ref.Index == 1 {
+			blocks = to.SinglePage([]*forgejo_sdk.Issue{{Index: 2}})
+			blocks.HasMore = true
+		}
+		return &IssueDependencies{BlockedBy: to.SinglePage([]*forgejo_sdk.Issue{}), Blocks: blocks}, nil
+	})
+
+	assert.NoError(t, err)
+	assert.True(t, graph.Truncated)
+	assert.Len(t, graph.Nodes, 2)
+}
+
+// TestIssueRefOf tests cross-repo references taken from the issue's repository
+func TestIssueRefOf(t *testing.T) {
+	fallback := IssueRef{Owner: "o", Repo: "r", Index: 1}
+
+	ref := issueRefOf(&forgejo_sdk.Issue{Index: 7, Repository: &forgejo_sdk.RepositoryMeta{Owner: "other", Name: "lib"}}, fallback)
+	assert.Equal(t, "other/lib#7", ref.String())
+
+	ref = issueRefOf(&forgejo_sdk.Issue{Index: 8}, fallback)
+	assert.Equal(t, "o/r#8", ref.String())
+}
+
+// TestGetIssueGraphFn_InvalidDepth tests depth validation before any API call
+func TestGetIssueGraphFn_InvalidDepth(t *testing.T) {
+	req := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Arguments: map[string]interface{}{
+				"owner": "o",
+				"repo":  "r",
+				"index": float64(1),
+				"depth": float64(50),
+			},
+		},
+	}
+
+	result, err := GetIssueGraphFn(nil, req)
+	assert.NoError(t, err)
+	require.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "depth must be between 1 and 10")
+}
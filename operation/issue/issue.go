@@ -10,6 +10,7 @@ import (
 	"codeberg.org/goern/forgejo-mcp/v2/operation/params"
 	"codeberg.org/goern/forgejo-mcp/v2/pkg/forgejo"
 	"codeberg.org/goern/forgejo-mcp/v2/pkg/log"
+	"codeberg.org/goern/forgejo-mcp/v2/pkg/paginate"
 	"codeberg.org/goern/forgejo-mcp/v2/pkg/to"
 
 	forgejo_sdk "codeberg.org/mvdkleijn/forgejo-sdk/forgejo/v2"
@@ -37,6 +38,7 @@ var (
 	GetIssueByIndexTool = mcp.NewTool(
 		GetIssueByIndexToolName,
 		mcp.WithDescription("Get issue by index"),
+		to.WithResult[*forgejo_sdk.Issue](),
 		mcp.WithString("owner", mcp.Required(), mcp.Description(params.Owner)),
 		mcp.WithString("repo", mcp.Required(), mcp.Description(params.Repo)),
 		mcp.WithNumber("index", mcp.Required(), mcp.Description(params.IssueIndex)),
@@ -45,6 +47,7 @@ var (
 	ListRepoIssuesTool = mcp.NewTool(
 		ListRepoIssuesToolName,
 		mcp.WithDescription("List repo issues"),
+		to.WithResult[*to.Page[*forgejo_sdk.Issue]](),
 		mcp.WithString("owner", mcp.Required(), mcp.Description(params.Owner)),
 		mcp.WithString("repo", mcp.Required(), mcp.Description(params.Repo)),
 		mcp.WithString("state", mcp.Description("State (open|closed|all)"), mcp.DefaultString("open")),
@@ -58,6 +61,7 @@ var (
 	CreateIssueTool = mcp.NewTool(
 		CreateIssueToolName,
 		mcp.WithDescription("Create issue"),
+		to.WithResult[*forgejo_sdk.Issue](),
 		mcp.WithString("owner", mcp.Required(), mcp.Description(params.Owner)),
 		mcp.WithString("repo", mcp.Required(), mcp.Description(params.Repo)),
 		mcp.WithString("title", mcp.Required(), mcp.Description(params.Title)),
@@ -67,6 +71,7 @@ var (
 	CreateIssueCommentTool = mcp.NewTool(
 		CreateIssueCommentToolName,
 		mcp.WithDescription("Create issue comment"),
+		to.WithResult[*forgejo_sdk.Comment](),
 		mcp.WithString("owner", mcp.Required(), mcp.Description(params.Owner)),
 		mcp.WithString("repo", mcp.Required(), mcp.Description(params.Repo)),
 		mcp.WithNumber("index", mcp.Required(), mcp.Description(params.Index)),
@@ -76,6 +81,7 @@ var (
 	UpdateIssueTool = mcp.NewTool(
 		UpdateIssueToolName,
 		mcp.WithDescription("Update issue"),
+		to.WithResult[*forgejo_sdk.Issue](),
 		mcp.WithString("owner", mcp.Required(), mcp.Description(params.Owner)),
 		mcp.WithString("repo", mcp.Required(), mcp.Description(params.Repo)),
 		mcp.WithNumber("index", mcp.Required(), mcp.Description(params.IssueIndex)),
@@ -88,6 +94,7 @@ var (
 	AddIssueLabelsTools = mcp.NewTool(
 		AddIssueLabelsToolName,
 		mcp.WithDescription("Add labels to issue"),
+		to.WithResult[*forgejo_sdk.Issue](),
 		mcp.WithString("owner", mcp.Required(), mcp.Description(params.Owner)),
 		mcp.WithString("repo", mcp.Required(), mcp.Description(params.Repo)),
 		mcp.WithNumber("index", mcp.Required(), mcp.Description(params.IssueIndex)),
@@ -97,6 +104,7 @@ var (
 	ReplaceIssueLabelsTool = mcp.NewTool(
 		ReplaceIssueLabelsToolName,
 		mcp.WithDescription("Replace all labels on an issue"),
+		to.WithResult[*forgejo_sdk.Issue](),
 		mcp.WithString("owner", mcp.Required(), mcp.Description(params.Owner)),
 		mcp.WithString("repo", mcp.Required(), mcp.Description(params.Repo)),
 		mcp.WithNumber("index", mcp.Required(), mcp.Description(params.Index)),
@@ -106,6 +114,7 @@ var (
 	DeleteIssueLabelTool = mcp.NewTool(
 		DeleteIssueLabelToolName,
 		mcp.WithDescription("Remove a label from an issue"),
+		to.WithResult[*forgejo_sdk.Issue](),
 		mcp.WithString("owner", mcp.Required(), mcp.Description(params.Owner)),
 		mcp.WithString("repo", mcp.Required(), mcp.Description(params.Repo)),
 		mcp.WithNumber("index", mcp.Required(), mcp.Description(params.Index)),
@@ -115,6 +124,7 @@ var (
 	IssueStateChangeTool = mcp.NewTool(
 		IssueStateChangeToolName,
 		mcp.WithDescription("Change issue state"),
+		to.WithResult[*forgejo_sdk.Issue](),
 		mcp.WithString("owner", mcp.Required(), mcp.Description(params.Owner)),
 		mcp.WithString("repo", mcp.Required(), mcp.Description(params.Repo)),
 		mcp.WithNumber("index", mcp.Required(), mcp.Description(params.IssueIndex)),
@@ -124,6 +134,7 @@ var (
 	ListIssueCommentsTool = mcp.NewTool(
 		ListIssueCommentsToolName,
 		mcp.WithDescription("List issue/PR comments"),
+		to.WithResult[*to.Page[*forgejo_sdk.Comment]](),
 		mcp.WithString("owner", mcp.Required(), mcp.Description(params.Owner)),
 		mcp.WithString("repo", mcp.Required(), mcp.Description(params.Repo)),
 		mcp.WithNumber("index", mcp.Required(), mcp.Description(params.Index)),
@@ -136,6 +147,7 @@ var (
 	GetIssueCommentTool = mcp.NewTool(
 		GetIssueCommentToolName,
 		mcp.WithDescription("Get comment by ID"),
+		to.WithResult[*forgejo_sdk.Comment](),
 		mcp.WithString("owner", mcp.Required(), mcp.Description(params.Owner)),
 		mcp.WithString("repo", mcp.Required(), mcp.Description(params.Repo)),
 		mcp.WithNumber("comment_id", mcp.Required(), mcp.Description(params.CommentID)),
@@ -144,6 +156,7 @@ var (
 	EditIssueCommentTool = mcp.NewTool(
 		EditIssueCommentToolName,
 		mcp.WithDescription("Edit issue/PR comment"),
+		to.WithResult[*forgejo_sdk.Comment](),
 		mcp.WithString("owner", mcp.Required(), mcp.Description(params.Owner)),
 		mcp.WithString("repo", mcp.Required(), mcp.Description(params.Repo)),
 		mcp.WithNumber("comment_id", mcp.Required(), mcp.Description(params.CommentID)),
@@ -153,6 +166,7 @@ var (
 	DeleteIssueCommentTool = mcp.NewTool(
 		DeleteIssueCommentToolName,
 		mcp.WithDescription("Delete issue/PR comment"),
+		to.WithResult[string](),
 		mcp.WithString("owner", mcp.Required(), mcp.Description(params.Owner)),
 		mcp.WithString("repo", mcp.Required(), mcp.Description(params.Repo)),
 		mcp.WithNumber("comment_id", mcp.Required(), mcp.Description(params.CommentID)),
@@ -173,6 +187,43 @@ func RegisterTool(s *server.MCPServer) {
 	s.AddTool(GetIssueCommentTool, GetIssueCommentFn)
 	s.AddTool(EditIssueCommentTool, EditIssueCommentFn)
 	s.AddTool(DeleteIssueCommentTool, DeleteIssueCommentFn)
+	s.AddTool(ListIssueTimelineTool, ListIssueTimelineFn)
+
+	// Reactions, subscriptions, pins and locks
+	s.AddTool(ListIssueReactionsTool, ListIssueReactionsFn)
+	s.AddTool(AddIssueReactionTool, AddIssueReactionFn)
+	s.AddTool(RemoveIssueReactionTool, RemoveIssueReactionFn)
+	s.AddTool(ListIssueSubscribersTool, ListIssueSubscribersFn)
+	s.AddTool(SubscribeIssueTool, SubscribeIssueFn)
+	s.AddTool(UnsubscribeIssueTool, UnsubscribeIssueFn)
+	s.AddTool(ListPinnedIssuesTool, ListPinnedIssuesFn)
+	s.AddTool(PinIssueTool, PinIssueFn)
+	s.AddTool(UnpinIssueTool, UnpinIssueFn)
+	s.AddTool(MovePinnedIssueTool, MovePinnedIssueFn)
+	s.AddTool(LockIssueTool, LockIssueFn)
+	s.AddTool(UnlockIssueTool, UnlockIssueFn)
+
+	// Time tracking
+	s.AddTool(AddTrackedTimeTool, AddTrackedTimeFn)
+	s.AddTool(ListTrackedTimesTool, ListTrackedTimesFn)
+	s.AddTool(DeleteTrackedTimeTool, DeleteTrackedTimeFn)
+	s.AddTool(StartStopwatchTool, StartStopwatchFn)
+	s.AddTool(StopStopwatchTool, StopStopwatchFn)
+	s.AddTool(CancelStopwatchTool, CancelStopwatchFn)
+	s.AddTool(ListMyStopwatchesTool, ListMyStopwatchesFn)
+	s.AddTool(SummarizeTrackedTimeTool, SummarizeTrackedTimeFn)
+
+	// Dependencies
+	s.AddTool(ListIssueDependenciesTool, ListIssueDependenciesFn)
+	s.AddTool(AddIssueDependencyTool, AddIssueDependencyFn)
+	s.AddTool(RemoveIssueDependencyTool, RemoveIssueDependencyFn)
+	s.AddTool(GetIssueGraphTool, GetIssueGraphFn)
+
+	// Attachments
+	s.AddTool(ListIssueAttachmentsTool, ListIssueAttachmentsFn)
+	s.AddTool(DownloadIssueAttachmentTool, DownloadIssueAttachmentFn)
+	s.AddTool(UploadIssueAttachmentTool, UploadIssueAttachmentFn)
+	s.AddTool(DeleteIssueAttachmentTool, DeleteIssueAttachmentFn)
 }
 
 func GetIssueByIndexFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -190,7 +241,7 @@ func GetIssueByIndexFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallT
 		return to.ErrorResult(err)
 	}
 
-	issue, _, err := forgejo.Client().GetIssue(owner, repo, int64(index))
+	issue, _, err := forgejo.ClientCtx(ctx).GetIssue(owner, repo, int64(index))
 	if err != nil {
 		return to.ErrorResult(fmt.Errorf("get issue err: %v", err))
 	}
@@ -211,18 +262,15 @@ func ListRepoIssuesFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallTo
 	issueType := req.GetString("type", "")
 	milestones := req.GetString("milestones", "")
 	labels := req.GetString("labels", "")
-	page := req.GetFloat("page", 1)
-	limit := req.GetFloat("limit", 20)
+	paging, err := paginate.FromRequest(req, 20)
+	if err != nil {
+		return to.ErrorResult(err)
+	}
 
 	// Create ListIssueOption according to the Forgejo API
 	opt := forgejo_sdk.ListIssueOption{
 		// State is correctly set directly
 		State: forgejo_sdk.StateType(state),
-		// ListOptions maps directly
-		ListOptions: forgejo_sdk.ListOptions{
-			Page:     int(page),
-			PageSize: int(limit),
-		},
 	}
 
 	// Set issue type if provided (convert to string parameters)
@@ -240,7 +288,10 @@ func ListRepoIssuesFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallTo
 		opt.Labels = strings.Split(labels, ",")
 	}
 
-	issues, _, err := forgejo.Client().ListRepoIssues(owner, repo, opt)
+	issues, err := paginate.List(ctx, paging, func(lo forgejo_sdk.ListOptions) ([]*forgejo_sdk.Issue, *forgejo_sdk.Response, error) {
+		opt.ListOptions = lo
+		return forgejo.ClientCtx(ctx).ListRepoIssues(owner, repo, opt)
+	})
 	if err != nil {
 		return to.ErrorResult(fmt.Errorf("get issues list err: %v", err))
 	}
@@ -267,7 +318,7 @@ func CreateIssueFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolR
 		Title: title,
 		Body:  body,
 	}
-	issue, _, err := forgejo.Client().CreateIssue(owner, repo, opt)
+	issue, _, err := forgejo.ClientCtx(ctx).CreateIssue(owner, repo, opt)
 	if err != nil {
 		return to.ErrorResult(fmt.Errorf("create issue err: %v", err))
 	}
@@ -296,7 +347,7 @@ func CreateIssueCommentFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.Ca
 	opt := forgejo_sdk.CreateIssueCommentOption{
 		Body: body,
 	}
-	comment, _, err := forgejo.Client().CreateIssueComment(owner, repo, int64(index), opt)
+	comment, _, err := forgejo.ClientCtx(ctx).CreateIssueComment(owner, repo, int64(index), opt)
 	if err != nil {
 		return to.ErrorResult(fmt.Errorf("create issue comment err: %v", err))
 	}
@@ -336,12 +387,12 @@ func UpdateIssueFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolR
 	if milestone != "" {
 		milestoneID, err := strconv.ParseInt(milestone, 10, 64)
 		if err != nil {
-			return to.ErrorResult(fmt.Errorf("invalid milestone ID: %v", err))
+			return to.ErrorResult(to.InvalidArgument("invalid milestone ID: %v", err))
 		}
 		opt.Milestone = &milestoneID
 	}
 
-	issue, _, err := forgejo.Client().EditIssue(owner, repo, int64(index), opt)
+	issue, _, err := forgejo.ClientCtx(ctx).EditIssue(owner, repo, int64(index), opt)
 	if err != nil {
 		return to.ErrorResult(fmt.Errorf("update issue err: %v", err))
 	}
@@ -376,7 +427,7 @@ func AddIssueLabelsFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallTo
 		labelStr = strings.TrimSpace(labelStr)
 		labelID, err := strconv.ParseInt(labelStr, 10, 64)
 		if err != nil {
-			return to.ErrorResult(fmt.Errorf("invalid label ID '%s': %v - labels must be numeric IDs", labelStr, err))
+			return to.ErrorResult(to.InvalidArgument("invalid label ID '%s': %v - labels must be numeric IDs", labelStr, err))
 		}
 		labelIDs = append(labelIDs, labelID)
 	}
@@ -386,13 +437,13 @@ func AddIssueLabelsFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallTo
 		Labels: labelIDs,
 	}
 
-	_, _, err = forgejo.Client().AddIssueLabels(owner, repo, int64(index), opt)
+	_, _, err = forgejo.ClientCtx(ctx).AddIssueLabels(owner, repo, int64(index), opt)
 	if err != nil {
 		return to.ErrorResult(fmt.Errorf("add issue labels err: %v", err))
 	}
 
 	// Fetch the updated issue to return it with the new labels
-	issue, _, err := forgejo.Client().GetIssue(owner, repo, int64(index))
+	issue, _, err := forgejo.ClientCtx(ctx).GetIssue(owner, repo, int64(index))
 	if err != nil {
 		return to.ErrorResult(fmt.Errorf("get updated issue err: %v", err))
 	}
@@ -419,7 +470,7 @@ func IssueStateChangeFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.Call
 	}
 
 	if state != "open" && state != "closed" {
-		return to.ErrorResult(fmt.Errorf("invalid state: %s, must be 'open' or 'closed'", state))
+		return to.ErrorResult(to.InvalidArgument("invalid state: %s, must be 'open' or 'closed'", state))
 	}
 
 	// Convert string to StateType and create pointer
@@ -429,7 +480,7 @@ func IssueStateChangeFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.Call
 		State: &stateType,
 	}
 
-	issue, _, err := forgejo.Client().EditIssue(owner, repo, int64(index), opt)
+	issue, _, err := forgejo.ClientCtx(ctx).EditIssue(owner, repo, int64(index), opt)
 	if err != nil {
 		return to.ErrorResult(fmt.Errorf("change issue state err: %v", err))
 	}
@@ -452,33 +503,33 @@ func ListIssueCommentsFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.Cal
 	}
 	since := req.GetString("since", "")
 	before := req.GetString("before", "")
-	page := req.GetFloat("page", 1)
-	limit := req.GetFloat("limit", 20)
-
-	opt := forgejo_sdk.ListIssueCommentOptions{
-		ListOptions: forgejo_sdk.ListOptions{
-			Page:     int(page),
-			PageSize: int(limit),
-		},
+	paging, err := paginate.FromRequest(req, 20)
+	if err != nil {
+		return to.ErrorResult(err)
 	}
 
+	opt := forgejo_sdk.ListIssueCommentOptions{}
+
 	// Set time filters if provided
 	if since != "" {
 		sinceTime, err := time.Parse(time.RFC3339, since)
 		if err != nil {
-			return to.ErrorResult(fmt.Errorf("invalid since time format (expected RFC3339): %v", err))
+			return to.ErrorResult(to.InvalidArgument("invalid since time format (expected RFC3339): %v", err))
 		}
 		opt.Since = sinceTime
 	}
 	if before != "" {
 		beforeTime, err := time.Parse(time.RFC3339, before)
 		if err != nil {
-			return to.ErrorResult(fmt.Errorf("invalid before time format (expected RFC3339): %v", err))
+			return to.ErrorResult(to.InvalidArgument("invalid before time format (expected RFC3339): %v", err))
 		}
 		opt.Before = beforeTime
 	}
 
-	comments, _, err := forgejo.Client().ListIssueComments(owner, repo, int64(index), opt)
+	comments, err := paginate.List(ctx, paging, func(lo forgejo_sdk.ListOptions) ([]*forgejo_sdk.Comment, *forgejo_sdk.Response, error) {
+		opt.ListOptions = lo
+		return forgejo.ClientCtx(ctx).ListIssueComments(owner, repo, int64(index), opt)
+	})
 	if err != nil {
 		return to.ErrorResult(fmt.Errorf("list issue comments err: %v", err))
 	}
@@ -500,7 +551,7 @@ func GetIssueCommentFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallT
 		return to.ErrorResult(err)
 	}
 
-	comment, _, err := forgejo.Client().GetIssueComment(owner, repo, int64(commentID))
+	comment, _, err := forgejo.ClientCtx(ctx).GetIssueComment(owner, repo, int64(commentID))
 	if err != nil {
 		return to.ErrorResult(fmt.Errorf("get issue comment err: %v", err))
 	}
@@ -529,7 +580,7 @@ func EditIssueCommentFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.Call
 	opt := forgejo_sdk.EditIssueCommentOption{
 		Body: body,
 	}
-	comment, _, err := forgejo.Client().EditIssueComment(owner, repo, int64(commentID), opt)
+	comment, _, err := forgejo.ClientCtx(ctx).EditIssueComment(owner, repo, int64(commentID), opt)
 	if err != nil {
 		return to.ErrorResult(fmt.Errorf("edit issue comment err: %v", err))
 	}
@@ -551,7 +602,7 @@ func DeleteIssueCommentFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.Ca
 		return to.ErrorResult(err)
 	}
 
-	_, err = forgejo.Client().DeleteIssueComment(owner, repo, int64(commentID))
+	_, err = forgejo.ClientCtx(ctx).DeleteIssueComment(owner, repo, int64(commentID))
 	if err != nil {
 		return to.ErrorResult(fmt.Errorf("delete issue comment err: %v", err))
 	}
@@ -583,7 +634,7 @@ func ReplaceIssueLabelsFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.Ca
 		labelStr = strings.TrimSpace(labelStr)
 		labelID, err := strconv.ParseInt(labelStr, 10, 64)
 		if err != nil {
-			return to.ErrorResult(fmt.Errorf("invalid label ID '%s': %v", labelStr, err))
+			return to.ErrorResult(to.InvalidArgument("invalid label ID '%s': %v", labelStr, err))
 		}
 		labelIDs = append(labelIDs, labelID)
 	}
@@ -592,13 +643,13 @@ func ReplaceIssueLabelsFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.Ca
 		Labels: labelIDs,
 	}
 
-	_, _, err = forgejo.Client().ReplaceIssueLabels(owner, repo, int64(index), opt)
+	_, _, err = forgejo.ClientCtx(ctx).ReplaceIssueLabels(owner, repo, int64(index), opt)
 	if err != nil {
 		return to.ErrorResult(fmt.Errorf("replace issue labels err: %v", err))
 	}
 
 	// Fetch updated issue to return with new labels
-	issue, _, err := forgejo.Client().GetIssue(owner, repo, int64(index))
+	issue, _, err := forgejo.ClientCtx(ctx).GetIssue(owner, repo, int64(index))
 	if err != nil {
 		return to.ErrorResult(fmt.Errorf("get updated issue err: %v", err))
 	}
@@ -624,13 +675,13 @@ func DeleteIssueLabelFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.Call
 		return to.ErrorResult(err)
 	}
 
-	_, err = forgejo.Client().DeleteIssueLabel(owner, repo, int64(index), int64(id))
+	_, err = forgejo.ClientCtx(ctx).DeleteIssueLabel(owner, repo, int64(index), int64(id))
 	if err != nil {
 		return to.ErrorResult(fmt.Errorf("delete issue label err: %v", err))
 	}
 
 	// Fetch updated issue to return without the removed label
-	issue, _, err := forgejo.Client().GetIssue(owner, repo, int64(index))
+	issue, _, err := forgejo.ClientCtx(ctx).GetIssue(owner, repo, int64(index))
 	if err != nil {
 		return to.ErrorResult(fmt.Errorf("get updated issue err: %v", err))
 	}
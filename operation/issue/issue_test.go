@@ -5,6 +5,7 @@ import (
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // TestReplaceIssueLabelsTool verifies the tool definition is correctly configured
@@ -130,9 +131,9 @@ func TestReplaceIssueLabelsFn_MissingRequiredParams(t *testing.T) {
 
 			result, err := ReplaceIssueLabelsFn(nil, req)
 			if tt.wantErr {
-				assert.Error(t, err)
-				assert.Nil(t, result)
-				assert.Contains(t, err.Error(), tt.errField)
+				assert.NoError(t, err)
+				require.True(t, result.IsError)
+				assert.Contains(t, result.Content[0].(mcp.TextContent).Text, tt.errField)
 			} else {
 				assert.NoError(t, err)
 				assert.NotNil(t, result)
@@ -196,9 +197,9 @@ func TestReplaceIssueLabelsFn_InvalidLabelIDs(t *testing.T) {
 
 			result, err := ReplaceIssueLabelsFn(nil, req)
 			if tt.wantErr {
-				assert.Error(t, err)
-				assert.Nil(t, result)
-				assert.Contains(t, err.Error(), tt.errContains)
+				assert.NoError(t, err)
+				require.True(t, result.IsError)
+				assert.Contains(t, result.Content[0].(mcp.TextContent).Text, tt.errContains)
 			} else {
 				assert.NoError(t, err)
 				assert.NotNil(t, result)
@@ -267,9 +268,9 @@ func TestDeleteIssueLabelFn_MissingRequiredParams(t *testing.T) {
 
 			result, err := DeleteIssueLabelFn(nil, req)
 			if tt.wantErr {
-				assert.Error(t, err)
-				assert.Nil(t, result)
-				assert.Contains(t, err.Error(), tt.errField)
+				assert.NoError(t, err)
+				require.True(t, result.IsError)
+				assert.Contains(t, result.Content[0].(mcp.TextContent).Text, tt.errField)
 			} else {
 				assert.NoError(t, err)
 				assert.NotNil(t, result)
@@ -321,9 +322,9 @@ func TestAddIssueLabelsFn_InvalidLabelIDs(t *testing.T) {
 
 			result, err := AddIssueLabelsFn(nil, req)
 			if tt.wantErr {
-				assert.Error(t, err)
-				assert.Nil(t, result)
-				assert.Contains(t, err.Error(), tt.errContains)
+				assert.NoError(t, err)
+				require.True(t, result.IsError)
+				assert.Contains(t, result.Content[0].(mcp.TextContent).Text, tt.errContains)
 			} else {
 				assert.NoError(t, err)
 				assert.NotNil(t, result)
@@ -0,0 +1,411 @@
+package issue
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"time"
+
+	"codeberg.org/goern/forgejo-mcp/v2/operation/params"
+	"codeberg.org/goern/forgejo-mcp/v2/pkg/forgejo"
+	"codeberg.org/goern/forgejo-mcp/v2/pkg/log"
+	"codeberg.org/goern/forgejo-mcp/v2/pkg/paginate"
+	"codeberg.org/goern/forgejo-mcp/v2/pkg/to"
+
+	forgejo_sdk "codeberg.org/mvdkleijn/forgejo-sdk/forgejo/v2"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const (
+	AddTrackedTimeToolName       = "add_tracked_time"
+	ListTrackedTimesToolName     = "list_tracked_times"
+	DeleteTrackedTimeToolName    = "delete_tracked_time"
+	StartStopwatchToolName       = "start_stopwatch"
+	StopStopwatchToolName        = "stop_stopwatch"
+	CancelStopwatchToolName      = "cancel_stopwatch"
+	ListMyStopwatchesToolName    = "list_my_stopwatches"
+	SummarizeTrackedTimeToolName = "summarize_tracked_time"
+
+	// summaryPageSize is the page size tracked times are summarized with and
+	// summaryMaxItems bounds the entries read for a summary
+	summaryPageSize = 50
+	summaryMaxItems = 2000
+)
+
+var (
+	AddTrackedTimeTool = mcp.NewTool(
+		AddTrackedTimeToolName,
+		mcp.WithDescription("Add tracked time to an issue"),
+		to.WithResult[*forgejo_sdk.TrackedTime](),
+		mcp.WithString("owner", mcp.Required(), mcp.Description(params.Owner)),
+		mcp.WithString("repo", mcp.Required(), mcp.Description(params.Repo)),
+		mcp.WithNumber("index", mcp.Required(), mcp.Description(params.Index)),
+		mcp.WithString("duration", mcp.Required(), mcp.Description("Time spent (e.g. 1h30m, 45m)")),
+		mcp.WithString("created", mcp.Description("When the work was done (RFC3339, default now)")),
+		mcp.WithString("user", mcp.Description("User the time is for (admins only)")),
+	)
+
+	ListTrackedTimesTool = mcp.NewTool(
+		ListTrackedTimesToolName,
+		mcp.WithDescription("List tracked times of an issue or repo"),
+		to.WithResult[*to.Page[*forgejo_sdk.TrackedTime]](),
+		mcp.WithString("owner", mcp.Required(), mcp.Description(params.Owner)),
+		mcp.WithString("repo", mcp.Required(), mcp.Description(params.Repo)),
+		mcp.WithNumber("index", mcp.Description("Issue/PR index (omit for whole repo)")),
+		mcp.WithString("user", mcp.Description("Only times of this user (repo-wide only)")),
+		mcp.WithString("since", mcp.Description(params.Since)),
+		mcp.WithString("before", mcp.Description(params.Before)),
+		mcp.WithNumber("page", mcp.Description(params.Page), mcp.DefaultNumber(1)),
+		mcp.WithNumber("limit", mcp.Description(params.Limit), mcp.DefaultNumber(20)),
+	)
+
+	DeleteTrackedTimeTool = mcp.NewTool(
+		DeleteTrackedTimeToolName,
+		mcp.WithDescription("Delete a tracked time entry"),
+		to.WithResult[string](),
+		mcp.WithString("owner", mcp.Required(), mcp.Description(params.Owner)),
+		mcp.WithString("repo", mcp.Required(), mcp.Description(params.Repo)),
+		mcp.WithNumber("index", mcp.Required(), mcp.Description(params.Index)),
+		mcp.WithNumber("id", mcp.Required(), mcp.Description("Tracked time ID")),
+	)
+
+	StartStopwatchTool = mcp.NewTool(
+		StartStopwatchToolName,
+		mcp.WithDescription("Start stopwatch on an issue"),
+		to.WithResult[string](),
+		mcp.WithString("owner", mcp.Required(), mcp.Description(params.Owner)),
+		mcp.WithString("repo", mcp.Required(), mcp.Description(params.Repo)),
+		mcp.WithNumber("index", mcp.Required(), mcp.Description(params.Index)),
+	)
+
+	StopStopwatchTool = mcp.NewTool(
+		StopStopwatchToolName,
+		mcp.WithDescription("Stop stopwatch and record the time"),
+		to.WithResult[string](),
+		mcp.WithString("owner", mcp.Required(), mcp.Description(params.Owner)),
+		mcp.WithString("repo", mcp.Required(), mcp.Description(params.Repo)),
+		mcp.WithNumber("index", mcp.Required(), mcp.Description(params.Index)),
+	)
+
+	CancelStopwatchTool = mcp.NewTool(
+		CancelStopwatchToolName,
+		mcp.WithDescription("Cancel stopwatch without recording time"),
+		to.WithResult[string](),
+		mcp.WithString("owner", mcp.Required(), mcp.Description(params.Owner)),
+		mcp.WithString("repo", mcp.Required(), mcp.Description(params.Repo)),
+		mcp.WithNumber("index", mcp.Required(), mcp.Description(params.Index)),
+	)
+
+	ListMyStopwatchesTool = mcp.NewTool(
+		ListMyStopwatchesToolName,
+		mcp.WithDescription("List my running stopwatches"),
+		to.WithResult[*to.Page[*forgejo_sdk.StopWatch]](),
+	)
+
+	SummarizeTrackedTimeTool = mcp.NewTool(
+		SummarizeTrackedTimeToolName,
+		mcp.WithDescription("Sum tracked time per user of a repo, or my time per repo"),
+		to.WithResult[*TimeSummary](),
+		mcp.WithString("group_by", mcp.Required(), mcp.Description("user (needs owner and repo) or repo (my times)")),
+		mcp.WithString("owner", mcp.Description(params.Owner)),
+		mcp.WithString("repo", mcp.Description(params.Repo)),
+		mcp.WithString("since", mcp.Description(params.Since)),
+		mcp.WithString("before", mcp.Description(params.Before)),
+	)
+)
+
+// TimeSummaryEntry is the tracked time of one user or repo
+type TimeSummaryEntry struct {
+	Key      string `json:"key"`
+	Seconds  int64  `json:"seconds"`
+	Duration string `json:"duration"`
+	Entries  int    `json:"entries"`
+}
+
+// TimeSummary is the result of summarize_tracked_time
+type TimeSummary struct {
+	GroupBy      string              `json:"group_by"`
+	Since        string              `json:"since,omitempty"`
+	Before       string              `json:"before,omitempty"`
+	TotalSeconds int64               `json:"total_seconds"`
+	Total        string              `json:"total"`
+	Groups       []*TimeSummaryEntry `json:"groups"`
+	Truncated    bool                `json:"truncated"`
+}
+
+// parseTimeRange parses the optional since and before arguments
+func parseTimeRange(req mcp.CallToolRequest) (since, before time.Time, err error) {
+	if s := req.GetString("since", ""); s != "" {
+		if since, err = time.Parse(time.RFC3339, s); err != nil {
+			return since, before, to.InvalidArgument("invalid since time format (expected RFC3339): %v", err)
+		}
+	}
+	if b := req.GetString("before", ""); b != "" {
+		if before, err = time.Parse(time.RFC3339, b); err != nil {
+			return since, before, to.InvalidArgument("invalid before time format (expected RFC3339): %v", err)
+		}
+	}
+	return since, before, nil
+}
+
+// summarizeTimes sums tracked times by the key returned for each entry
+func summarizeTimes(groupBy string, times []*forgejo_sdk.TrackedTime, key func(*forgejo_sdk.TrackedTime) string) *TimeSummary {
+	summary := &TimeSummary{GroupBy: groupBy, Groups: []*TimeSummaryEntry{}}
+	groups := map[string]*TimeSummaryEntry{}
+	for _, t := range times {
+		k := key(t)
+		entry, ok := groups[k]
+		if !ok {
+			entry = &TimeSummaryEntry{Key: k}
+			groups[k] = entry
+			summary.Groups = append(summary.Groups, entry)
+		}
+		entry.Seconds += t.Time
+		entry.Entries++
+		summary.TotalSeconds += t.Time
+	}
+	for _, entry := range summary.Groups {
+		entry.Duration = (time.Duration(entry.Seconds) * time.Second).String()
+	}
+	sort.SliceStable(summary.Groups, func(i, j int) bool {
+		if summary.Groups[i].Seconds != summary.Groups[j].Seconds {
+			return summary.Groups[i].Seconds > summary.Groups[j].Seconds
+		}
+		return summary.Groups[i].Key < summary.Groups[j].Key
+	})
+	summary.Total = (time.Duration(summary.TotalSeconds) * time.Second).String()
+	return summary
+}
+
+func repoOfTrackedTime(t *forgejo_sdk.TrackedTime) string {
+	if t.Issue != nil && t.Issue.Repository != nil && t.Issue.Repository.FullName != "" {
+		return t.Issue.Repository.FullName
+	}
+	return "unknown"
+}
+
+func AddTrackedTimeFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called AddTrackedTimeFn")
+	owner, err := req.RequireString("owner")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	repo, err := req.RequireString("repo")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	index, err := req.RequireFloat("index")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	durationArg, err := req.RequireString("duration")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	duration, err := time.ParseDuration(durationArg)
+	if err != nil || duration < time.Second {
+		return to.ErrorResult(to.InvalidArgument("invalid duration '%s': use a positive value like 1h30m", durationArg))
+	}
+
+	opt := forgejo_sdk.AddTimeOption{
+		Time: int64(duration / time.Second),
+		User: req.GetString("user", ""),
+	}
+	if created := req.GetString("created", ""); created != "" {
+		opt.Created, err = time.Parse(time.RFC3339, created)
+		if err != nil {
+			return to.ErrorResult(to.InvalidArgument("invalid created time format (expected RFC3339): %v", err))
+		}
+	}
+
+	trackedTime, _, err := forgejo.ClientCtx(ctx).AddTime(owner, repo, int64(index), opt)
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("add tracked time err: %v", err))
+	}
+	return to.TextResult(trackedTime)
+}
+
+func ListTrackedTimesFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called ListTrackedTimesFn")
+	owner, err := req.RequireString("owner")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	repo, err := req.RequireString("repo")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	since, before, err := parseTimeRange(req)
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	paging, err := paginate.FromRequest(req, 20)
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+
+	opt := forgejo_sdk.ListTrackedTimesOptions{
+		Since:  since,
+		Before: before,
+	}
+	_, byIssue := req.GetArguments()["index"]
+	index := req.GetFloat("index", 0)
+	if !byIssue {
+		opt.User = req.GetString("user", "")
+	}
+
+	times, err := paginate.List(ctx, paging, func(lo forgejo_sdk.ListOptions) ([]*forgejo_sdk.TrackedTime, *forgejo_sdk.Response, error) {
+		opt.ListOptions = lo
+		if byIssue {
+			return forgejo.ClientCtx(ctx).ListIssueTrackedTimes(owner, repo, int64(index), opt)
+		}
+		return forgejo.ClientCtx(ctx).ListRepoTrackedTimes(owner, repo, opt)
+	})
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("list tracked times err: %v", err))
+	}
+	return to.TextResult(times)
+}
+
+func DeleteTrackedTimeFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called DeleteTrackedTimeFn")
+	owner, err := req.RequireString("owner")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	repo, err := req.RequireString("repo")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	index, err := req.RequireFloat("index")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	id, err := req.RequireFloat("id")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+
+	_, err = forgejo.ClientCtx(ctx).DeleteTime(owner, repo, int64(index), int64(id))
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("delete tracked time err: %v", err))
+	}
+	return to.TextResult("Delete tracked time success")
+}
+
+func StartStopwatchFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called StartStopwatchFn")
+	owner, repo, index, err := issueArgs(req)
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+
+	_, err = forgejo.ClientCtx(ctx).StartIssueStopWatch(owner, repo, index)
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("start stopwatch err: %v", err))
+	}
+	return to.TextResult("Stopwatch started")
+}
+
+func StopStopwatchFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called StopStopwatchFn")
+	owner, repo, index, err := issueArgs(req)
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+
+	_, err = forgejo.ClientCtx(ctx).StopIssueStopWatch(owner, repo, index)
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("stop stopwatch err: %v", err))
+	}
+	return to.TextResult("Stopwatch stopped, time recorded")
+}
+
+func CancelStopwatchFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called CancelStopwatchFn")
+	owner, repo, index, err := issueArgs(req)
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+
+	_, err = forgejo.ClientCtx(ctx).DeleteIssueStopwatch(owner, repo, index)
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("cancel stopwatch err: %v", err))
+	}
+	return to.TextResult("Stopwatch cancelled")
+}
+
+func ListMyStopwatchesFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called ListMyStopwatchesFn")
+	stopwatches, _, err := forgejo.ClientCtx(ctx).GetMyStopwatches()
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("list stopwatches err: %v", err))
+	}
+	return to.TextResult(to.SinglePage(stopwatches))
+}
+
+func SummarizeTrackedTimeFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called SummarizeTrackedTimeFn")
+	groupBy, err := req.RequireString("group_by")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	since, before, err := parseTimeRange(req)
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	owner := req.GetString("owner", "")
+	repo := req.GetString("repo", "")
+
+	var fetch func(page, limit int) ([]*forgejo_sdk.TrackedTime, *http.Response, error)
+	var key func(*forgejo_sdk.TrackedTime) string
+	switch groupBy {
+	case "user":
+		if owner == "" || repo == "" {
+			return to.ErrorResult(to.InvalidArgument("owner and repo are required to group by user"))
+		}
+		fetch = func(page, limit int) ([]*forgejo_sdk.TrackedTime, *http.Response, error) {
+			opt := forgejo_sdk.ListTrackedTimesOptions{
+				ListOptions: forgejo_sdk.ListOptions{Page: page, PageSize: limit},
+				Since:       since,
+				Before:      before,
+			}
+			times, resp, err := forgejo.ClientCtx(ctx).ListRepoTrackedTimes(owner, repo, opt)
+			if resp == nil {
+				return times, nil, err
+			}
+			return times, resp.Response, err
+		}
+		key = func(t *forgejo_sdk.TrackedTime) string { return t.UserName }
+	case "repo":
+		// The SDK's GetMyTrackedTimes takes no filters, query /user/times directly
+		fetch = func(page, limit int) ([]*forgejo_sdk.TrackedTime, *http.Response, error) {
+			query := url.Values{}
+			if !since.IsZero() {
+				query.Set("since", since.Format(time.RFC3339))
+			}
+			if !before.IsZero() {
+				query.Set("before", before.Format(time.RFC3339))
+			}
+			times := []*forgejo_sdk.TrackedTime{}
+			resp, err := forgejo.Do(ctx, "GET", "/user/times", paginate.Query(query, page, limit), nil, &times)
+			return times, resp, err
+		}
+		key = repoOfTrackedTime
+	default:
+		return to.ErrorResult(to.InvalidArgument("invalid group_by '%s': must be user or repo", groupBy))
+	}
+
+	times, err := paginate.Fetch(ctx, paginate.Options{Page: 1, Limit: summaryPageSize, All: true, Max: summaryMaxItems}, fetch)
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("list tracked times err: %v", err))
+	}
+
+	summary := summarizeTimes(groupBy, times.Items, key)
+	summary.Truncated = times.HasMore
+	summary.Since = req.GetString("since", "")
+	summary.Before = req.GetString("before", "")
+	return to.TextResult(summary)
+}
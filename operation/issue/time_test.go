@@ -0,0 +1,145 @@
+package issue
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"codeberg.org/goern/forgejo-mcp/v2/pkg/flag"
+
+	forgejo_sdk "codeberg.org/mvdkleijn/forgejo-sdk/forgejo/v2"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSummarizeTimes tests grouping, totals and ordering of tracked times
+func TestSummarizeTimes(t *testing.T) {
+	times := []*forgejo_sdk.TrackedTime{
+		{Time: 1800, UserName: "alice"},
+		{Time: 3600, UserName: "bob"},
+		{Time: 3600, UserName: "alice"},
+	}
+
+	summary := summarizeTimes("user", times, func(t *forgejo_sdk.TrackedTime) string { return t.UserName })
+
+	assert.Equal(t, int64(9000), summary.TotalSeconds)
+	assert.Equal(t, "2h30m0s", summary.Total)
+	assert.Len(t, summary.Groups, 2)
+	assert.Equal(t, &TimeSummaryEntry{Key: "alice", Seconds: 5400, Duration: "1h30m0s", Entries: 2}, summary.Groups[0])
+	assert.Equal(t, &TimeSummaryEntry{Key: "bob", Seconds: 3600, Duration: "1h0m0s", Entries: 1}, summary.Groups[1])
+}
+
+// TestRepoOfTrackedTime tests the repo key used when grouping by repo
+func TestRepoOfTrackedTime(t *testing.T) {
+	withRepo := &forgejo_sdk.TrackedTime{Issue: &forgejo_sdk.Issue{Repository: &forgejo_sdk.RepositoryMeta{FullName: "acme/website"}}}
+	assert.Equal(t, "acme/website", repoOfTrackedTime(withRepo))
+	assert.Equal(t, "unknown", repoOfTrackedTime(&forgejo_sdk.TrackedTime{}))
+}
+
+// TestAddTrackedTimeFn_InvalidDuration tests duration validation before any API call
+func TestAddTrackedTimeFn_InvalidDuration(t *testing.T) {
+	for _, duration := range []string{"ninety minutes", "-1h", "0s"} {
+		t.Run(duration, func(t *testing.T) {
+			req := mcp.CallToolRequest{
+				Params: mcp.CallToolParams{
+					Arguments: map[string]interface{}{
+						"owner":    "acme",
+						"repo":     "website",
+						"index":    float64(1),
+						"duration": duration,
+					},
+				},
+			}
+
+			result, err := AddTrackedTimeFn(nil, req)
+			assert.NoError(t, err)
+			require.True(t, result.IsError)
+			assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "invalid duration")
+		})
+	}
+}
+
+// TestSummarizeTrackedTimeFn_InvalidArgs tests argument validation before any API call
+func TestSummarizeTrackedTimeFn_InvalidArgs(t *testing.T) {
+	tests := []struct {
+		name        string
+		args        map[string]interface{}
+		errContains string
+	}{
+		{
+			name:        "invalid group_by",
+			args:        map[string]interface{}{"group_by": "issue"},
+			errContains: "invalid group_by",
+		},
+		{
+			name:        "user without repo",
+			args:        map[string]interface{}{"group_by": "user", "owner": "acme"},
+			errContains: "owner and repo are required",
+		},
+		{
+			name:        "invalid since",
+			args:        map[string]interface{}{"group_by": "repo", "since": "last week"},
+			errContains: "invalid since time format",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := mcp.CallToolRequest{
+				Params: mcp.CallToolParams{
+					Arguments: tt.args,
+				},
+			}
+
+			result, err := SummarizeTrackedTimeFn(nil, req)
+			assert.NoError(t, err)
+			require.True(t, result.IsError)
+			assert.Contains(t, result.Content[0].(mcp.TextContent).Text, tt.errContains)
+		})
+	}
+}
+
+// TestSummarizeTrackedTimeFn_SmallServerPages follows all pages when the
+// server caps the page size below the requested one
+func TestSummarizeTrackedTimeFn_SmallServerPages(t *testing.T) {
+	const entries, serverLimit = 25, 10
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/user/times" {
+			http.NotFound(w, r)
+			return
+		}
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		first := (page - 1) * serverLimit
+		last := min(first+serverLimit, entries)
+		w.Header().Set("X-Total-Count", strconv.Itoa(entries))
+		if last < entries {
+			w.Header().Set("Link", fmt.Sprintf(`<%s/api/v1/user/times?page=%d>; rel="next"`, "http://"+r.Host, page+1))
+		}
+		var times []string
+		for i := first; i < last; i++ {
+			times = append(times, fmt.Sprintf(`{"id":%d,"time":60,"issue":{"repository":{"full_name":"acme/website"}}}`, i))
+		}
+		fmt.Fprintf(w, "[%s]", strings.Join(times, ","))
+	}))
+	defer srv.Close()
+	defer func(url string) { flag.URL = url }(flag.URL)
+	flag.URL = srv.URL
+
+	req := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Arguments: map[string]interface{}{"group_by": "repo"},
+		},
+	}
+	result, err := SummarizeTrackedTimeFn(context.Background(), req)
+	require.NoError(t, err)
+	require.False(t, result.IsError, result.Content)
+
+	text := result.Content[0].(mcp.TextContent).Text
+	assert.Contains(t, text, `"entries":25`)
+	assert.Contains(t, text, `"truncated":false`)
+}
@@ -0,0 +1,127 @@
+package issue
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"codeberg.org/goern/forgejo-mcp/v2/operation/params"
+	"codeberg.org/goern/forgejo-mcp/v2/pkg/forgejo"
+	"codeberg.org/goern/forgejo-mcp/v2/pkg/log"
+	"codeberg.org/goern/forgejo-mcp/v2/pkg/paginate"
+	"codeberg.org/goern/forgejo-mcp/v2/pkg/to"
+
+	forgejo_sdk "codeberg.org/mvdkleijn/forgejo-sdk/forgejo/v2"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const (
+	ListIssueTimelineToolName = "list_issue_timeline"
+)
+
+var ListIssueTimelineTool = mcp.NewTool(
+	ListIssueTimelineToolName,
+	mcp.WithDescription("List issue/PR timeline: comments, labels, assignments, references, commits and state changes"),
+	to.WithResult[*to.Page[*TimelineEvent]](),
+	mcp.WithString("owner", mcp.Required(), mcp.Description(params.Owner)),
+	mcp.WithString("repo", mcp.Required(), mcp.Description(params.Repo)),
+	mcp.WithNumber("index", mcp.Required(), mcp.Description(params.IssueIndex)),
+	mcp.WithString("since", mcp.Description(params.Since)),
+	mcp.WithString("before", mcp.Description(params.Before)),
+	mcp.WithNumber("page", mcp.Description(params.Page), mcp.DefaultNumber(1)),
+	mcp.WithNumber("limit", mcp.Description(params.Limit), mcp.DefaultNumber(50)),
+)
+
+// TimelineEvent is one issue timeline entry. Type names the event, e.g.
+// comment, label, assignees, change_title, commit_ref, pull_push, close or
+// reopen; only the fields relevant to that type are set.
+type TimelineEvent struct {
+	ID        int64             `json:"id"`
+	Type      string            `json:"type"`
+	User      *forgejo_sdk.User `json:"user"`
+	Body      string            `json:"body,omitempty"`
+	HTMLURL   string            `json:"html_url,omitempty"`
+	CreatedAt time.Time         `json:"created_at"`
+	UpdatedAt time.Time         `json:"updated_at"`
+
+	Label           *forgejo_sdk.Label     `json:"label,omitempty"`
+	Assignee        *forgejo_sdk.User      `json:"assignee,omitempty"`
+	AssigneeTeam    *forgejo_sdk.Team      `json:"assignee_team,omitempty"`
+	RemovedAssignee bool                   `json:"removed_assignee,omitempty"`
+	Milestone       *forgejo_sdk.Milestone `json:"milestone,omitempty"`
+	OldMilestone    *forgejo_sdk.Milestone `json:"old_milestone,omitempty"`
+	OldTitle        string                 `json:"old_title,omitempty"`
+	NewTitle        string                 `json:"new_title,omitempty"`
+	OldRef          string                 `json:"old_ref,omitempty"`
+	NewRef          string                 `json:"new_ref,omitempty"`
+
+	RefAction      string                   `json:"ref_action,omitempty"`
+	RefCommitSHA   string                   `json:"ref_commit_sha,omitempty"`
+	RefIssue       *forgejo_sdk.Issue       `json:"ref_issue,omitempty"`
+	RefComment     *forgejo_sdk.Comment     `json:"ref_comment,omitempty"`
+	DependentIssue *forgejo_sdk.Issue       `json:"dependent_issue,omitempty"`
+	ReviewID       int64                    `json:"review_id,omitempty"`
+	TrackedTime    *forgejo_sdk.TrackedTime `json:"tracked_time,omitempty"`
+	ResolveDoer    *forgejo_sdk.User        `json:"resolve_doer,omitempty"`
+}
+
+// timelineEventView is the compact form of a TimelineEvent; its fields
+// hide those of the embedded event in JSON
+type timelineEventView struct {
+	*TimelineEvent
+	User           *to.UserView  `json:"user"`
+	Assignee       *to.UserView  `json:"assignee,omitempty"`
+	RefIssue       *to.IssueView `json:"ref_issue,omitempty"`
+	DependentIssue *to.IssueView `json:"dependent_issue,omitempty"`
+	ResolveDoer    *to.UserView  `json:"resolve_doer,omitempty"`
+}
+
+func (e *TimelineEvent) CompactView() any {
+	if e == nil {
+		return (*timelineEventView)(nil)
+	}
+	return &timelineEventView{
+		TimelineEvent:  e,
+		User:           to.View[*to.UserView](e.User),
+		Assignee:       to.View[*to.UserView](e.Assignee),
+		RefIssue:       to.View[*to.IssueView](e.RefIssue),
+		DependentIssue: to.View[*to.IssueView](e.DependentIssue),
+		ResolveDoer:    to.View[*to.UserView](e.ResolveDoer),
+	}
+}
+
+func ListIssueTimelineFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called ListIssueTimelineFn")
+	owner, repo, index, err := issueArgs(req)
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	since, before, err := parseTimeRange(req)
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	paging, err := paginate.FromRequest(req, 50)
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+
+	query := url.Values{}
+	if !since.IsZero() {
+		query.Set("since", since.Format(time.RFC3339))
+	}
+	if !before.IsZero() {
+		query.Set("before", before.Format(time.RFC3339))
+	}
+
+	events, err := paginate.Fetch(ctx, paging, func(page, limit int) ([]*TimelineEvent, *http.Response, error) {
+		events := []*TimelineEvent{}
+		resp, err := forgejo.Do(ctx, "GET", issuePath(owner, repo, index, "timeline"), paginate.Query(query, page, limit), nil, &events)
+		return events, resp, err
+	})
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("list issue timeline err: %v", err))
+	}
+	return to.TextResult(events)
+}
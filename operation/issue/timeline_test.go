@@ -0,0 +1,47 @@
+package issue
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestTimelineEvent tests decoding of non-comment timeline events
+func TestTimelineEvent(t *testing.T) {
+	data := `[
+		{"id": 1, "type": "label", "user": {"login": "alice"}, "label": {"id": 7, "name": "bug"}, "body": "1"},
+		{"id": 2, "type": "assignees", "user": {"login": "alice"}, "assignee": {"login": "bob"}, "removed_assignee": false},
+		{"id": 3, "type": "commit_ref", "user": {"login": "bob"}, "ref_commit_sha": "abc123"},
+		{"id": 4, "type": "issue_ref", "user": {"login": "bob"}, "ref_issue": {"number": 9, "title": "Follow-up"}}
+	]`
+
+	var events []*TimelineEvent
+	assert.NoError(t, json.Unmarshal([]byte(data), &events))
+	assert.Len(t, events, 4)
+	assert.Equal(t, "bug", events[0].Label.Name)
+	assert.Equal(t, "bob", events[1].Assignee.UserName)
+	assert.Equal(t, "abc123", events[2].RefCommitSHA)
+	assert.Equal(t, int64(9), events[3].RefIssue.Index)
+}
+
+// TestListIssueTimelineFn_InvalidSince tests time validation before any API call
+func TestListIssueTimelineFn_InvalidSince(t *testing.T) {
+	req := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Arguments: map[string]interface{}{
+				"owner": "acme",
+				"repo":  "website",
+				"index": float64(3),
+				"since": "last week",
+			},
+		},
+	}
+
+	result, err := ListIssueTimelineFn(nil, req)
+	assert.NoError(t, err)
+	require.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "invalid since time format")
+}
@@ -0,0 +1,288 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"codeberg.org/goern/forgejo-mcp/v2/operation/params"
+	"codeberg.org/goern/forgejo-mcp/v2/pkg/forgejo"
+	"codeberg.org/goern/forgejo-mcp/v2/pkg/log"
+	"codeberg.org/goern/forgejo-mcp/v2/pkg/paginate"
+	"codeberg.org/goern/forgejo-mcp/v2/pkg/to"
+
+	forgejo_sdk "codeberg.org/mvdkleijn/forgejo-sdk/forgejo/v2"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const (
+	ListNotificationsToolName      = "list_notifications"
+	MarkNotificationToolName       = "mark_notification"
+	MarkAllNotificationsToolName   = "mark_all_notifications"
+	GetNotificationSubjectToolName = "get_notification_subject"
+)
+
+var (
+	ListNotificationsTool = mcp.NewTool(
+		ListNotificationsToolName,
+		mcp.WithDescription("List my notifications"),
+		to.WithResult[*to.Page[*forgejo_sdk.NotificationThread]](),
+		mcp.WithString("owner", mcp.Description("Repository owner (with repo, filters by repo)")),
+		mcp.WithString("repo", mcp.Description("Repository name (with owner, filters by repo)")),
+		mcp.WithString("status", mcp.Description("Statuses (comma-separated: unread,read,pinned)"), mcp.DefaultString("unread,pinned")),
+		mcp.WithString("subject_type", mcp.Description("Subject types (comma-separated: issue,pull,commit,repository)")),
+		mcp.WithString("since", mcp.Description(params.Since)),
+		mcp.WithString("before", mcp.Description(params.Before)),
+		mcp.WithNumber("page", mcp.Description(params.Page), mcp.DefaultNumber(1)),
+		mcp.WithNumber("limit", mcp.Description(params.Limit), mcp.DefaultNumber(20)),
+	)
+
+	MarkNotificationTool = mcp.NewTool(
+		MarkNotificationToolName,
+		mcp.WithDescription("Mark notification thread as read, unread or pinned"),
+		to.WithResult[*forgejo_sdk.NotificationThread](),
+		mcp.WithNumber("id", mcp.Required(), mcp.Description(params.ThreadID)),
+		mcp.WithString("status", mcp.Description("New status (read|unread|pinned)"), mcp.DefaultString("read")),
+	)
+
+	MarkAllNotificationsTool = mcp.NewTool(
+		MarkAllNotificationsToolName,
+		mcp.WithDescription("Mark all notification threads, optionally of one repo"),
+		to.WithResult[[]*forgejo_sdk.NotificationThread](),
+		mcp.WithString("owner", mcp.Description("Repository owner (with repo, limits to repo)")),
+		mcp.WithString("repo", mcp.Description("Repository name (with owner, limits to repo)")),
+		mcp.WithString("status", mcp.Description("New status (read|unread|pinned)"), mcp.DefaultString("read")),
+		mcp.WithString("from_status", mcp.Description("Only threads with these statuses (comma-separated: unread,read,pinned)"), mcp.DefaultString("unread")),
+		mcp.WithString("last_read_at", mcp.Description("Only threads updated before this time (RFC3339)")),
+	)
+
+	GetNotificationSubjectTool = mcp.NewTool(
+		GetNotificationSubjectToolName,
+		mcp.WithDescription("Get notification thread with its linked issue or PR"),
+		to.WithResult[NotificationSubject](),
+		mcp.WithNumber("id", mcp.Required(), mcp.Description(params.ThreadID)),
+	)
+)
+
+// subjectURLPattern extracts owner, repo, kind and index from an API subject URL
+var subjectURLPattern = regexp.MustCompile(`/repos/([^/]+)/([^/]+)/(issues|pulls)/(\d+)$`)
+
+// NotificationSubject is a thread together with the issue or pull request it refers to
+type NotificationSubject struct {
+	Thread      *forgejo_sdk.NotificationThread `json:"thread"`
+	Issue       *forgejo_sdk.Issue              `json:"issue,omitempty"`
+	PullRequest *forgejo_sdk.PullRequest        `json:"pull_request,omitempty"`
+}
+
+func RegisterTool(s *server.MCPServer) {
+	s.AddTool(ListNotificationsTool, ListNotificationsFn)
+	s.AddTool(MarkNotificationTool, MarkNotificationFn)
+	s.AddTool(MarkAllNotificationsTool, MarkAllNotificationsFn)
+	s.AddTool(GetNotificationSubjectTool, GetNotificationSubjectFn)
+}
+
+// parseStatus validates a single notification status
+func parseStatus(status string) (forgejo_sdk.NotifyStatus, error) {
+	switch s := forgejo_sdk.NotifyStatus(strings.ToLower(strings.TrimSpace(status))); s {
+	case forgejo_sdk.NotifyStatusRead, forgejo_sdk.NotifyStatusUnread, forgejo_sdk.NotifyStatusPinned:
+		return s, nil
+	default:
+		return "", to.InvalidArgument("invalid status '%s': must be read, unread or pinned", status)
+	}
+}
+
+// parseStatuses validates a comma-separated list of notification statuses
+func parseStatuses(statuses string) ([]forgejo_sdk.NotifyStatus, error) {
+	var result []forgejo_sdk.NotifyStatus
+	for _, s := range strings.Split(statuses, ",") {
+		if strings.TrimSpace(s) == "" {
+			continue
+		}
+		status, err := parseStatus(s)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, status)
+	}
+	return result, nil
+}
+
+// parseSubjectTypes validates a comma-separated list of subject types
+func parseSubjectTypes(types string) ([]forgejo_sdk.NotifySubjectType, error) {
+	var result []forgejo_sdk.NotifySubjectType
+	for _, t := range strings.Split(types, ",") {
+		t = strings.ToLower(strings.TrimSpace(t))
+		switch t {
+		case "":
+			continue
+		case "issue", "pull", "commit", "repository":
+			result = append(result, forgejo_sdk.NotifySubjectType(t))
+		default:
+			return nil, to.InvalidArgument("invalid subject type '%s': must be issue, pull, commit or repository", t)
+		}
+	}
+	return result, nil
+}
+
+func ListNotificationsFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called ListNotificationsFn")
+	owner := req.GetString("owner", "")
+	repo := req.GetString("repo", "")
+	status := req.GetString("status", "unread,pinned")
+	subjectType := req.GetString("subject_type", "")
+	since := req.GetString("since", "")
+	before := req.GetString("before", "")
+	paging, err := paginate.FromRequest(req, 20)
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+
+	if (owner == "") != (repo == "") {
+		return to.ErrorResult(to.InvalidArgument("owner and repo must be provided together"))
+	}
+
+	statuses, err := parseStatuses(status)
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	opt := forgejo_sdk.ListNotificationOptions{Status: statuses}
+	subjectTypes, err := parseSubjectTypes(subjectType)
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	opt.SubjectTypes = subjectTypes
+
+	if since != "" {
+		sinceTime, err := time.Parse(time.RFC3339, since)
+		if err != nil {
+			return to.ErrorResult(to.InvalidArgument("invalid since time format (expected RFC3339): %v", err))
+		}
+		opt.Since = sinceTime
+	}
+	if before != "" {
+		beforeTime, err := time.Parse(time.RFC3339, before)
+		if err != nil {
+			return to.ErrorResult(to.InvalidArgument("invalid before time format (expected RFC3339): %v", err))
+		}
+		opt.Before = beforeTime
+	}
+
+	threads, err := paginate.List(ctx, paging, func(lo forgejo_sdk.ListOptions) ([]*forgejo_sdk.NotificationThread, *forgejo_sdk.Response, error) {
+		opt.ListOptions = lo
+		if repo != "" {
+			return forgejo.ClientCtx(ctx).ListRepoNotifications(owner, repo, opt)
+		}
+		return forgejo.ClientCtx(ctx).ListNotifications(opt)
+	})
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("list notifications err: %v", err))
+	}
+	return to.TextResult(threads)
+}
+
+func MarkNotificationFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called MarkNotificationFn")
+	id, err := req.RequireFloat("id")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	status, err := parseStatus(req.GetString("status", "read"))
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+
+	thread, _, err := forgejo.ClientCtx(ctx).ReadNotification(int64(id), status)
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("mark notification err: %v", err))
+	}
+	return to.TextResult(thread)
+}
+
+func MarkAllNotificationsFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called MarkAllNotificationsFn")
+	owner := req.GetString("owner", "")
+	repo := req.GetString("repo", "")
+	lastReadAt := req.GetString("last_read_at", "")
+
+	if (owner == "") != (repo == "") {
+		return to.ErrorResult(to.InvalidArgument("owner and repo must be provided together"))
+	}
+	status, err := parseStatus(req.GetString("status", "read"))
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	fromStatus, err := parseStatuses(req.GetString("from_status", "unread"))
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	if len(fromStatus) == 0 {
+		return to.ErrorResult(to.InvalidArgument("from_status must name at least one status"))
+	}
+
+	opt := forgejo_sdk.MarkNotificationOptions{
+		ToStatus: status,
+		Status:   fromStatus,
+	}
+	if lastReadAt != "" {
+		lastRead, err := time.Parse(time.RFC3339, lastReadAt)
+		if err != nil {
+			return to.ErrorResult(to.InvalidArgument("invalid last_read_at time format (expected RFC3339): %v", err))
+		}
+		opt.LastReadAt = lastRead
+	}
+
+	var threads []*forgejo_sdk.NotificationThread
+	if repo != "" {
+		threads, _, err = forgejo.ClientCtx(ctx).ReadRepoNotifications(owner, repo, opt)
+	} else {
+		threads, _, err = forgejo.ClientCtx(ctx).ReadNotifications(opt)
+	}
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("mark all notifications err: %v", err))
+	}
+	return to.TextResult(threads)
+}
+
+func GetNotificationSubjectFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called GetNotificationSubjectFn")
+	id, err := req.RequireFloat("id")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+
+	thread, _, err := forgejo.ClientCtx(ctx).GetNotification(int64(id))
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("get notification err: %v", err))
+	}
+	result := NotificationSubject{Thread: thread}
+	if thread.Subject == nil {
+		return to.TextResult(result)
+	}
+
+	match := subjectURLPattern.FindStringSubmatch(thread.Subject.URL)
+	if match == nil {
+		// Commits and repositories have no issue or PR to fetch
+		return to.TextResult(result)
+	}
+	owner, repo, kind := match[1], match[2], match[3]
+	index, _ := strconv.ParseInt(match[4], 10, 64)
+
+	if kind == "pulls" {
+		pr, _, err := forgejo.ClientCtx(ctx).GetPullRequest(owner, repo, index)
+		if err != nil {
+			return to.ErrorResult(fmt.Errorf("get notification pull request err: %v", err))
+		}
+		result.PullRequest = pr
+	} else {
+		issue, _, err := forgejo.ClientCtx(ctx).GetIssue(owner, repo, index)
+		if err != nil {
+			return to.ErrorResult(fmt.Errorf("get notification issue err: %v", err))
+		}
+		result.Issue = issue
+	}
+	return to.TextResult(result)
+}
@@ -0,0 +1,150 @@
+package notification
+
+import (
+	"testing"
+
+	forgejo_sdk "codeberg.org/mvdkleijn/forgejo-sdk/forgejo/v2"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestListNotificationsTool verifies the tool definition is correctly configured
+func TestListNotificationsTool(t *testing.T) {
+	tool := ListNotificationsTool
+
+	assert.Equal(t, "list_notifications", tool.Name)
+	assert.NotNil(t, tool.Description)
+
+	params := tool.InputSchema.Properties
+	assert.Contains(t, params, "owner")
+	assert.Contains(t, params, "repo")
+	assert.Contains(t, params, "status")
+	assert.Contains(t, params, "subject_type")
+	assert.Contains(t, params, "since")
+
+	// All filters are optional
+	assert.Empty(t, tool.InputSchema.Required)
+}
+
+// TestParseStatus tests notification status validation
+func TestParseStatus(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  string
+		want    forgejo_sdk.NotifyStatus
+		wantErr bool
+	}{
+		{name: "read", status: "read", want: forgejo_sdk.NotifyStatusRead},
+		{name: "pinned upper case", status: "PINNED", want: forgejo_sdk.NotifyStatusPinned},
+		{name: "unread with spaces", status: " unread ", want: forgejo_sdk.NotifyStatusUnread},
+		{name: "unknown", status: "archived", wantErr: true},
+		{name: "empty", status: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseStatus(tt.status)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+// TestParseStatuses tests status list parsing
+func TestParseStatuses(t *testing.T) {
+	got, err := parseStatuses("unread, PINNED,,")
+	assert.NoError(t, err)
+	assert.Equal(t, []forgejo_sdk.NotifyStatus{forgejo_sdk.NotifyStatusUnread, forgejo_sdk.NotifyStatusPinned}, got)
+
+	_, err = parseStatuses("unread,archived")
+	assert.Error(t, err)
+}
+
+// TestParseSubjectTypes tests subject type list parsing
+func TestParseSubjectTypes(t *testing.T) {
+	got, err := parseSubjectTypes("Issue, pull,,commit")
+	assert.NoError(t, err)
+	assert.Equal(t, []forgejo_sdk.NotifySubjectType{"issue", "pull", "commit"}, got)
+
+	_, err = parseSubjectTypes("issue,release")
+	assert.Error(t, err)
+}
+
+// TestSubjectURLPattern tests extraction of issue and PR coordinates from subject URLs
+func TestSubjectURLPattern(t *testing.T) {
+	match := subjectURLPattern.FindStringSubmatch("https://codeberg.org/api/v1/repos/goern/forgejo-mcp/pulls/42")
+	assert.Equal(t, []string{"/repos/goern/forgejo-mcp/pulls/42", "goern", "forgejo-mcp", "pulls", "42"}, match)
+
+	match = subjectURLPattern.FindStringSubmatch("https://codeberg.org/api/v1/repos/goern/forgejo-mcp/issues/7")
+	assert.Equal(t, "issues", match[3])
+
+	assert.Nil(t, subjectURLPattern.FindStringSubmatch("https://codeberg.org/api/v1/repos/goern/forgejo-mcp/git/commits/abc"))
+}
+
+// TestListNotificationsFn_InvalidArgs tests argument validation before any API call
+func TestListNotificationsFn_InvalidArgs(t *testing.T) {
+	tests := []struct {
+		name        string
+		args        map[string]interface{}
+		errContains string
+	}{
+		{
+			name:        "owner without repo",
+			args:        map[string]interface{}{"owner": "goern"},
+			errContains: "owner and repo must be provided together",
+		},
+		{
+			name:        "invalid status",
+			args:        map[string]interface{}{"status": "archived"},
+			errContains: "invalid status",
+		},
+		{
+			name:        "invalid subject type",
+			args:        map[string]interface{}{"subject_type": "release"},
+			errContains: "invalid subject type",
+		},
+		{
+			name:        "invalid since",
+			args:        map[string]interface{}{"since": "yesterday"},
+			errContains: "invalid since time format",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := mcp.CallToolRequest{
+				Params: mcp.CallToolParams{
+					Arguments: tt.args,
+				},
+			}
+
+			result, err := ListNotificationsFn(nil, req)
+			assert.NoError(t, err)
+			require.True(t, result.IsError)
+			assert.Contains(t, result.Content[0].(mcp.TextContent).Text, tt.errContains)
+		})
+	}
+}
+
+// TestMarkAllNotificationsFn_FromStatus tests that only unread threads are
+// marked by default and that from_status is validated before any API call
+func TestMarkAllNotificationsFn_FromStatus(t *testing.T) {
+	from := MarkAllNotificationsTool.InputSchema.Properties["from_status"].(map[string]any)
+	assert.Equal(t, "unread", from["default"])
+
+	for _, fromStatus := range []string{"archived", " , "} {
+		req := mcp.CallToolRequest{
+			Params: mcp.CallToolParams{
+				Arguments: map[string]interface{}{"from_status": fromStatus},
+			},
+		}
+		result, err := MarkAllNotificationsFn(nil, req)
+		assert.NoError(t, err)
+		require.True(t, result.IsError, fromStatus)
+	}
+}
@@ -1,18 +1,32 @@
 package operation
 
 import (
+	"context"
 	"fmt"
+	"maps"
+	"net/http"
+	"strings"
 
+	"codeberg.org/goern/forgejo-mcp/v2/operation/actions"
+	"codeberg.org/goern/forgejo-mcp/v2/operation/activity"
 	"codeberg.org/goern/forgejo-mcp/v2/operation/issue"
+	"codeberg.org/goern/forgejo-mcp/v2/operation/notification"
+	"codeberg.org/goern/forgejo-mcp/v2/operation/org"
+	"codeberg.org/goern/forgejo-mcp/v2/operation/packages"
+	"codeberg.org/goern/forgejo-mcp/v2/operation/params"
 	"codeberg.org/goern/forgejo-mcp/v2/operation/pull"
 	"codeberg.org/goern/forgejo-mcp/v2/operation/repo"
+	"codeberg.org/goern/forgejo-mcp/v2/operation/resource"
 	"codeberg.org/goern/forgejo-mcp/v2/operation/search"
 	"codeberg.org/goern/forgejo-mcp/v2/operation/user"
 	"codeberg.org/goern/forgejo-mcp/v2/operation/version"
+	"codeberg.org/goern/forgejo-mcp/v2/operation/webhook"
 	"codeberg.org/goern/forgejo-mcp/v2/pkg/flag"
 	"codeberg.org/goern/forgejo-mcp/v2/pkg/forgejo"
 	"codeberg.org/goern/forgejo-mcp/v2/pkg/log"
+	"codeberg.org/goern/forgejo-mcp/v2/pkg/to"
 
+	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 )
 
@@ -39,6 +53,30 @@ func RegisterTool(s *server.MCPServer) {
 	pull.RegisterTool(s)
 	log.Debug("Registered pull request tools")
 
+	// Org Tool
+	org.RegisterTool(s)
+	log.Debug("Registered organization tools")
+
+	// Actions Tool
+	actions.RegisterTool(s)
+	log.Debug("Registered actions tools")
+
+	// Webhook Tool
+	webhook.RegisterTool(s)
+	log.Debug("Registered webhook tools")
+
+	// Packages Tool
+	packages.RegisterTool(s)
+	log.Debug("Registered package tools")
+
+	// Activity Tool
+	activity.RegisterTool(s)
+	log.Debug("Registered activity tools")
+
+	// Notification Tool
+	notification.RegisterTool(s)
+	log.Debug("Registered notification tools")
+
 	// Search Tool
 	search.RegisterTool(s)
 	log.Debug("Registered search tools")
@@ -47,13 +85,94 @@ func RegisterTool(s *server.MCPServer) {
 	version.RegisterTool(s)
 	log.Debug("Registered version tools")
 
+	addOutputOptions(s)
+
 	log.Info("All MCP tools registered successfully")
 }
 
+func RegisterResource(s *server.MCPServer) {
+	log.Info("Registering MCP resources")
+	resource.RegisterResource(s)
+	log.Info("All MCP resources registered successfully")
+}
+
+// fieldsToolPrefixes name the tools that take the fields argument
+var fieldsToolPrefixes = []string{"list_", "get_", "search_"}
+
+// addOutputOptions adds the optional format argument to every tool, the
+// fields argument to list, get and search tools, the all argument to tools
+// that take a page, and the output schema. Schemas of declared result types
+// are derived again here, after the verbosity flag is parsed; other tools
+// get the generic result schema. outputMiddleware applies format and fields
+// to the result, and handlers read all through paginate.FromRequest.
+func addOutputOptions(s *server.MCPServer) {
+	for name, t := range s.ListTools() {
+		tool := t.Tool
+		tool.InputSchema.Properties = maps.Clone(tool.InputSchema.Properties)
+		if tool.InputSchema.Properties == nil {
+			tool.InputSchema.Properties = map[string]any{}
+		}
+		tool.InputSchema.Properties["format"] = map[string]any{
+			"type":        "string",
+			"description": params.Format,
+			"enum":        []string{to.FormatJSON, to.FormatMarkdown},
+		}
+		if _, paged := tool.InputSchema.Properties["page"]; paged {
+			tool.InputSchema.Properties["all"] = map[string]any{
+				"type":        "boolean",
+				"description": params.All,
+			}
+		}
+		if hasAnyPrefix(name, fieldsToolPrefixes) {
+			tool.InputSchema.Properties["fields"] = map[string]any{
+				"type":        "string",
+				"description": params.Fields,
+			}
+		}
+		if typ, ok := to.ResultType(name); ok {
+			tool.OutputSchema = to.ResultSchema(typ)
+		} else if tool.OutputSchema.Type == "" && tool.RawOutputSchema == nil {
+			tool.OutputSchema = to.ResultOutputSchema()
+		}
+		s.AddTool(tool, t.Handler)
+	}
+}
+
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(s, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// outputMiddleware projects tool results onto the fields argument and
+// renders them in the requested format
+func outputMiddleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		format := req.GetString("format", flag.Format)
+		if format != "" && format != to.FormatJSON && format != to.FormatMarkdown {
+			return to.ErrorResult(fmt.Errorf("invalid format '%s': must be json or markdown", format))
+		}
+
+		result, err := next(ctx, req)
+		if err != nil || result == nil || result.IsError {
+			return result, err
+		}
+		result = to.SelectFields(result, req.GetString("fields", ""))
+		if format == to.FormatMarkdown {
+			result = to.RenderMarkdown(result)
+		}
+		return result, nil
+	}
+}
+
 func Run(transport, version string) error {
 	flag.Version = version
 	mcpServer = newMCPServer(version)
 	RegisterTool(mcpServer)
+	RegisterResource(mcpServer)
 
 	// Test connection to Forgejo instance before starting the server
 	log.Info("Testing connection to Forgejo instance",
@@ -74,7 +193,7 @@ func Run(transport, version string) error {
 	case "stdio":
 		log.Info("Starting MCP server with stdio transport")
 		log.Info("MCP server ready for stdio communication")
-		if err := server.ServeStdio(mcpServer); err != nil {
+		if err := serveStdio(mcpServer); err != nil {
 			log.Error("MCP stdio server failed",
 				log.ErrorField(err),
 			)
@@ -82,7 +201,9 @@ func Run(transport, version string) error {
 		}
 		log.Info("MCP stdio server shutdown")
 	case "sse":
-		sseServer := server.NewSSEServer(mcpServer)
+		httpServer := &http.Server{}
+		sseServer := server.NewSSEServer(mcpServer, server.WithHTTPServer(httpServer))
+		httpServer.Handler = subscriptionHandler(sseServer)
 		log.Info("Starting MCP SSE server",
 			log.IntField("port", flag.SSEPort),
 		)
@@ -112,11 +233,22 @@ func testConnection() error {
 	return forgejo.VerifyConnection()
 }
 
-
 func newMCPServer(version string) *server.MCPServer {
-	return server.NewMCPServer(
+	hooks := &server.Hooks{}
+	hooks.AddBeforeCallTool(tagRequest)
+	hooks.AddAfterListResources(resource.ListResources)
+	hooks.AddOnUnregisterSession(dropSubscriptions)
+	s := server.NewMCPServer(
 		"Forgejo MCP Server",
 		version,
 		server.WithLogging(),
+		server.WithResourceCapabilities(true, false),
+		server.WithHooks(hooks),
+		server.WithToolHandlerMiddleware(callMiddleware),
+		server.WithToolHandlerMiddleware(outputMiddleware),
+		server.WithResourceHandlerMiddleware(resourceMiddleware),
 	)
+	s.AddNotificationHandler("notifications/cancelled", handleCancelled)
+	subscriptions = newSubscriptions(s)
+	return s
 }
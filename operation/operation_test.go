@@ -0,0 +1,286 @@
+package operation
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"codeberg.org/goern/forgejo-mcp/v2/pkg/flag"
+	"codeberg.org/goern/forgejo-mcp/v2/pkg/to"
+
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sampleDepth bounds sample values of recursive types such as a repository
+// and its parent
+const sampleDepth = 4
+
+// sampleValue builds a value of typ with every exported field, slice and
+// map filled in
+func sampleValue(typ reflect.Type, depth int) reflect.Value {
+	v := reflect.New(typ).Elem()
+	if typ == reflect.TypeFor[time.Time]() {
+		v.Set(reflect.ValueOf(time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)))
+		return v
+	}
+	if depth == 0 {
+		return v
+	}
+	switch typ.Kind() {
+	case reflect.Pointer:
+		v.Set(sampleValue(typ.Elem(), depth-1).Addr())
+	case reflect.Struct:
+		for i := 0; i < typ.NumField(); i++ {
+			if field := v.Field(i); field.CanSet() {
+				field.Set(sampleValue(typ.Field(i).Type, depth-1))
+			}
+		}
+	case reflect.Slice:
+		v.Set(reflect.Append(reflect.MakeSlice(typ, 0, 1), sampleValue(typ.Elem(), depth-1)))
+	case reflect.Map:
+		v.Set(reflect.MakeMap(typ))
+		v.SetMapIndex(sampleValue(typ.Key(), depth-1), sampleValue(typ.Elem(), depth-1))
+	case reflect.String:
+		v.SetString("sample")
+	case reflect.Bool:
+		v.SetBool(true)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v.SetInt(7)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		v.SetUint(7)
+	case reflect.Float32, reflect.Float64:
+		v.SetFloat(1.5)
+	}
+	return v
+}
+
+// validate checks a value decoded with UseNumber against the subset of JSON
+// Schema that the derived output schemas use
+func validate(schema any, defs map[string]any, v any, path string) error {
+	s, ok := schema.(map[string]any)
+	if !ok {
+		if b, ok := schema.(bool); ok && !b {
+			return fmt.Errorf("%s: not allowed", path)
+		}
+		return nil
+	}
+
+	if ref, ok := s["$ref"].(string); ok {
+		def, ok := defs[strings.TrimPrefix(ref, "#/$defs/")]
+		if !ok {
+			return fmt.Errorf("%s: unresolved $ref %s", path, ref)
+		}
+		if err := validate(def, defs, v, path); err != nil {
+			return err
+		}
+	}
+	if anyOf, ok := s["anyOf"].([]any); ok {
+		var errs []string
+		for _, alt := range anyOf {
+			err := validate(alt, defs, v, path)
+			if err == nil {
+				errs = nil
+				break
+			}
+			errs = append(errs, err.Error())
+		}
+		if errs != nil {
+			return fmt.Errorf("%s: no anyOf alternative matches: %s", path, strings.Join(errs, "; "))
+		}
+	}
+
+	if typ, ok := s["type"]; ok {
+		var types []any
+		switch t := typ.(type) {
+		case string:
+			types = []any{t}
+		case []any:
+			types = t
+		}
+		matched := false
+		for _, t := range types {
+			if hasType(t.(string), v) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return fmt.Errorf("%s: %T does not match type %v", path, v, typ)
+		}
+	}
+
+	switch val := v.(type) {
+	case map[string]any:
+		props, _ := s["properties"].(map[string]any)
+		if required, ok := s["required"].([]any); ok {
+			for _, name := range required {
+				if _, ok := val[name.(string)]; !ok {
+					return fmt.Errorf("%s: missing required property %s", path, name)
+				}
+			}
+		}
+		for name, item := range val {
+			itemSchema, ok := props[name]
+			if !ok {
+				itemSchema, ok = s["additionalProperties"]
+				if !ok {
+					continue
+				}
+			}
+			if err := validate(itemSchema, defs, item, path+"."+name); err != nil {
+				return err
+			}
+		}
+	case []any:
+		if items, ok := s["items"]; ok {
+			for i, item := range val {
+				if err := validate(items, defs, item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func hasType(typ string, v any) bool {
+	switch val := v.(type) {
+	case nil:
+		return typ == "null"
+	case bool:
+		return typ == "boolean"
+	case string:
+		return typ == "string"
+	case json.Number:
+		if typ == "integer" {
+			_, err := val.Int64()
+			return err == nil
+		}
+		return typ == "number"
+	case []any:
+		return typ == "array"
+	case map[string]any:
+		return typ == "object"
+	}
+	return false
+}
+
+// TestToolOutputSchemas walks all registered tools and validates a sample
+// result of each declared result type against the tool's output schema
+func TestToolOutputSchemas(t *testing.T) {
+	defer func(verbosity string) { flag.Verbosity = verbosity }(flag.Verbosity)
+
+	for _, verbosity := range []string{to.VerbosityCompact, to.VerbosityFull} {
+		t.Run(verbosity, func(t *testing.T) {
+			flag.Verbosity = verbosity
+			s := server.NewMCPServer("test", "0.0.0")
+			RegisterTool(s)
+
+			tools := s.ListTools()
+			require.NotEmpty(t, tools)
+			for name, tool := range tools {
+				typ, ok := to.ResultType(name)
+				if !assert.True(t, ok, "%s declares no result type", name) {
+					continue
+				}
+				schema := tool.Tool.OutputSchema
+				assert.Equal(t, "object", schema.Type, name)
+
+				result, err := to.TextResult(sampleValue(typ, sampleDepth).Interface())
+				require.NoError(t, err, name)
+				require.NotNil(t, result.StructuredContent, name)
+
+				data, err := json.Marshal(result.StructuredContent)
+				require.NoError(t, err, name)
+				dec := json.NewDecoder(bytes.NewReader(data))
+				dec.UseNumber()
+				var structured any
+				require.NoError(t, dec.Decode(&structured), name)
+
+				root := map[string]any{
+					"type":       schema.Type,
+					"properties": schema.Properties,
+					"required":   toAnySlice(schema.Required),
+				}
+				assert.NoError(t, validate(root, schema.Defs, structured, name))
+			}
+		})
+	}
+}
+
+// TestToolOutputSchemas_RejectsMismatch makes sure the validator catches a
+// result that does not fit the schema
+func TestToolOutputSchemas_RejectsMismatch(t *testing.T) {
+	schema := to.ResultSchema(reflect.TypeFor[[]string]())
+	root := map[string]any{"type": schema.Type, "properties": schema.Properties, "required": toAnySlice(schema.Required)}
+
+	assert.NoError(t, validate(root, schema.Defs, map[string]any{"Result": []any{"a"}}, "result"))
+	assert.Error(t, validate(root, schema.Defs, map[string]any{"Result": []any{json.Number("1")}}, "result"))
+	assert.Error(t, validate(root, schema.Defs, map[string]any{}, "result"))
+}
+
+func toAnySlice(in []string) []any {
+	out := make([]any, 0, len(in))
+	for _, s := range in {
+		out = append(out, s)
+	}
+	return out
+}
+
+// TestResources lists the resource templates and the user's repositories
+// and reads an issue through a fake Forgejo
+func TestResources(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/version":
+			io.WriteString(w, `{"version":"11.0.0"}`)
+		case "/api/v1/user/repos":
+			io.WriteString(w, `[{"name":"website","full_name":"acme/website","owner":{"login":"acme"}}]`)
+		case "/api/v1/repos/acme/website/issues/42":
+			io.WriteString(w, `{"number":42,"title":"Broken link","state":"open"}`)
+		case "/api/v1/repos/acme/website/issues/42/comments":
+			io.WriteString(w, `[{"id":1,"body":"Confirmed"}]`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	defer func(url string) { flag.URL = url }(flag.URL)
+	flag.URL = srv.URL
+
+	s := newMCPServer("test")
+	RegisterResource(s)
+	message := func(body string) map[string]any {
+		data, err := json.Marshal(s.HandleMessage(context.Background(), []byte(body)))
+		require.NoError(t, err)
+		var msg struct {
+			Result map[string]any `json:"result"`
+		}
+		require.NoError(t, json.Unmarshal(data, &msg), string(data))
+		require.NotNil(t, msg.Result, string(data))
+		return msg.Result
+	}
+
+	templates := message(`{"jsonrpc":"2.0","id":1,"method":"resources/templates/list"}`)
+	assert.Len(t, templates["resourceTemplates"], 6)
+
+	resources := message(`{"jsonrpc":"2.0","id":2,"method":"resources/list"}`)
+	require.Len(t, resources["resources"], 1)
+	assert.Equal(t, "forgejo://acme/website", resources["resources"].([]any)[0].(map[string]any)["uri"])
+
+	read := message(`{"jsonrpc":"2.0","id":3,"method":"resources/read","params":{"uri":"forgejo://acme/website/issues/42"}}`)
+	contents := read["contents"].([]any)[0].(map[string]any)
+	assert.Equal(t, "application/json", contents["mimeType"])
+	assert.Contains(t, contents["text"], `"title":"Broken link"`)
+	assert.Contains(t, contents["text"], `"body":"Confirmed"`)
+}
@@ -0,0 +1,216 @@
+package org
+
+import (
+	"context"
+	"fmt"
+
+	"codeberg.org/goern/forgejo-mcp/v2/operation/params"
+	"codeberg.org/goern/forgejo-mcp/v2/pkg/forgejo"
+	"codeberg.org/goern/forgejo-mcp/v2/pkg/log"
+	"codeberg.org/goern/forgejo-mcp/v2/pkg/paginate"
+	"codeberg.org/goern/forgejo-mcp/v2/pkg/to"
+
+	forgejo_sdk "codeberg.org/mvdkleijn/forgejo-sdk/forgejo/v2"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const (
+	ListMyOrgsToolName             = "list_my_orgs"
+	GetOrgToolName                 = "get_org"
+	ListOrgReposToolName           = "list_org_repos"
+	ListOrgMembersToolName         = "list_org_members"
+	RemoveOrgMemberToolName        = "remove_org_member"
+	SetOrgMemberVisibilityToolName = "set_org_member_visibility"
+)
+
+var (
+	ListMyOrgsTool = mcp.NewTool(
+		ListMyOrgsToolName,
+		mcp.WithDescription("List my organizations"),
+		to.WithResult[*to.Page[*forgejo_sdk.Organization]](),
+		mcp.WithNumber("page", mcp.Description(params.Page), mcp.DefaultNumber(1)),
+		mcp.WithNumber("limit", mcp.Description(params.Limit), mcp.DefaultNumber(50)),
+	)
+
+	GetOrgTool = mcp.NewTool(
+		GetOrgToolName,
+		mcp.WithDescription("Get organization details"),
+		to.WithResult[*forgejo_sdk.Organization](),
+		mcp.WithString("org", mcp.Required(), mcp.Description(params.Org)),
+	)
+
+	ListOrgReposTool = mcp.NewTool(
+		ListOrgReposToolName,
+		mcp.WithDescription("List organization repos"),
+		to.WithResult[*to.Page[*forgejo_sdk.Repository]](),
+		mcp.WithString("org", mcp.Required(), mcp.Description(params.Org)),
+		mcp.WithNumber("page", mcp.Description(params.Page), mcp.DefaultNumber(1)),
+		mcp.WithNumber("limit", mcp.Description(params.Limit), mcp.DefaultNumber(50)),
+	)
+
+	ListOrgMembersTool = mcp.NewTool(
+		ListOrgMembersToolName,
+		mcp.WithDescription("List organization members"),
+		to.WithResult[*to.Page[*forgejo_sdk.User]](),
+		mcp.WithString("org", mcp.Required(), mcp.Description(params.Org)),
+		mcp.WithBoolean("public_only", mcp.Description("Only publicly visible members")),
+		mcp.WithNumber("page", mcp.Description(params.Page), mcp.DefaultNumber(1)),
+		mcp.WithNumber("limit", mcp.Description(params.Limit), mcp.DefaultNumber(50)),
+	)
+
+	RemoveOrgMemberTool = mcp.NewTool(
+		RemoveOrgMemberToolName,
+		mcp.WithDescription("Remove a member from an organization and all its teams"),
+		to.WithResult[string](),
+		mcp.WithString("org", mcp.Required(), mcp.Description(params.Org)),
+		mcp.WithString("user", mcp.Required(), mcp.Description(params.User)),
+	)
+
+	SetOrgMemberVisibilityTool = mcp.NewTool(
+		SetOrgMemberVisibilityToolName,
+		mcp.WithDescription("Publicize or conceal an organization membership"),
+		to.WithResult[string](),
+		mcp.WithString("org", mcp.Required(), mcp.Description(params.Org)),
+		mcp.WithString("user", mcp.Required(), mcp.Description(params.User)),
+		mcp.WithBoolean("public", mcp.Required(), mcp.Description("Make membership public")),
+	)
+)
+
+func RegisterTool(s *server.MCPServer) {
+	s.AddTool(ListMyOrgsTool, ListMyOrgsFn)
+	s.AddTool(GetOrgTool, GetOrgFn)
+	s.AddTool(ListOrgReposTool, ListOrgReposFn)
+	s.AddTool(ListOrgMembersTool, ListOrgMembersFn)
+	s.AddTool(RemoveOrgMemberTool, RemoveOrgMemberFn)
+	s.AddTool(SetOrgMemberVisibilityTool, SetOrgMemberVisibilityFn)
+
+	// Team
+	s.AddTool(ListOrgTeamsTool, ListOrgTeamsFn)
+	s.AddTool(CreateTeamTool, CreateTeamFn)
+	s.AddTool(EditTeamTool, EditTeamFn)
+	s.AddTool(ListTeamMembersTool, ListTeamMembersFn)
+	s.AddTool(AddTeamMemberTool, AddTeamMemberFn)
+	s.AddTool(RemoveTeamMemberTool, RemoveTeamMemberFn)
+	s.AddTool(ListTeamReposTool, ListTeamReposFn)
+	s.AddTool(AddTeamRepoTool, AddTeamRepoFn)
+	s.AddTool(RemoveTeamRepoTool, RemoveTeamRepoFn)
+}
+
+func ListMyOrgsFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called ListMyOrgsFn")
+	paging, err := paginate.FromRequest(req, 50)
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+
+	orgs, err := paginate.List(ctx, paging, func(lo forgejo_sdk.ListOptions) ([]*forgejo_sdk.Organization, *forgejo_sdk.Response, error) {
+		return forgejo.ClientCtx(ctx).ListMyOrgs(forgejo_sdk.ListOrgsOptions{ListOptions: lo})
+	})
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("list my orgs err: %v", err))
+	}
+	return to.TextResult(orgs)
+}
+
+func GetOrgFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called GetOrgFn")
+	org, err := req.RequireString("org")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+
+	organization, _, err := forgejo.ClientCtx(ctx).GetOrg(org)
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("get org err: %v", err))
+	}
+	return to.TextResult(organization)
+}
+
+func ListOrgReposFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called ListOrgReposFn")
+	org, err := req.RequireString("org")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	paging, err := paginate.FromRequest(req, 50)
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+
+	repos, err := paginate.List(ctx, paging, func(lo forgejo_sdk.ListOptions) ([]*forgejo_sdk.Repository, *forgejo_sdk.Response, error) {
+		return forgejo.ClientCtx(ctx).ListOrgRepos(org, forgejo_sdk.ListOrgReposOptions{ListOptions: lo})
+	})
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("list org repos err: %v", err))
+	}
+	return to.TextResult(repos)
+}
+
+func ListOrgMembersFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called ListOrgMembersFn")
+	org, err := req.RequireString("org")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	publicOnly := req.GetBool("public_only", false)
+	paging, err := paginate.FromRequest(req, 50)
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+
+	members, err := paginate.List(ctx, paging, func(lo forgejo_sdk.ListOptions) ([]*forgejo_sdk.User, *forgejo_sdk.Response, error) {
+		opt := forgejo_sdk.ListOrgMembershipOption{ListOptions: lo}
+		if publicOnly {
+			return forgejo.ClientCtx(ctx).ListPublicOrgMembership(org, opt)
+		}
+		return forgejo.ClientCtx(ctx).ListOrgMembership(org, opt)
+	})
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("list org members err: %v", err))
+	}
+	return to.TextResult(members)
+}
+
+func RemoveOrgMemberFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called RemoveOrgMemberFn")
+	org, err := req.RequireString("org")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	user, err := req.RequireString("user")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+
+	_, err = forgejo.ClientCtx(ctx).DeleteOrgMembership(org, user)
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("remove org member err: %v", err))
+	}
+	return to.TextResult("Remove org member success")
+}
+
+func SetOrgMemberVisibilityFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called SetOrgMemberVisibilityFn")
+	org, err := req.RequireString("org")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	user, err := req.RequireString("user")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	public, err := req.RequireBool("public")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+
+	_, err = forgejo.ClientCtx(ctx).SetPublicOrgMembership(org, user, public)
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("set org member visibility err: %v", err))
+	}
+	if public {
+		return to.TextResult("Membership is now public")
+	}
+	return to.TextResult("Membership is now concealed")
+}
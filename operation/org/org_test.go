@@ -0,0 +1,104 @@
+package org
+
+import (
+	"testing"
+
+	forgejo_sdk "codeberg.org/mvdkleijn/forgejo-sdk/forgejo/v2"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestCreateTeamTool verifies the tool definition is correctly configured
+func TestCreateTeamTool(t *testing.T) {
+	tool := CreateTeamTool
+
+	assert.Equal(t, "create_team", tool.Name)
+	assert.NotNil(t, tool.Description)
+
+	params := tool.InputSchema.Properties
+	assert.Contains(t, params, "org")
+	assert.Contains(t, params, "name")
+	assert.Contains(t, params, "permission")
+	assert.Contains(t, params, "units")
+
+	assert.Contains(t, tool.InputSchema.Required, "org")
+	assert.Contains(t, tool.InputSchema.Required, "name")
+}
+
+// TestAddTeamRepoTool verifies the tool definition is correctly configured
+func TestAddTeamRepoTool(t *testing.T) {
+	tool := AddTeamRepoTool
+
+	assert.Equal(t, "add_team_repo", tool.Name)
+	assert.NotNil(t, tool.Description)
+
+	assert.Contains(t, tool.InputSchema.Required, "id")
+	assert.Contains(t, tool.InputSchema.Required, "org")
+	assert.Contains(t, tool.InputSchema.Required, "repo")
+}
+
+// TestParseUnits tests unit list parsing with and without the repo. prefix
+func TestParseUnits(t *testing.T) {
+	got := parseUnits("code, repo.issues,,pulls")
+	assert.Equal(t, []forgejo_sdk.RepoUnitType{
+		forgejo_sdk.RepoUnitCode,
+		forgejo_sdk.RepoUnitIssues,
+		forgejo_sdk.RepoUnitPulls,
+	}, got)
+
+	assert.Empty(t, parseUnits(""))
+}
+
+// TestCreateTeamFn_InvalidPermission tests permission validation before any API call
+func TestCreateTeamFn_InvalidPermission(t *testing.T) {
+	req := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Arguments: map[string]interface{}{
+				"org":        "my-org",
+				"name":       "devs",
+				"permission": "superuser",
+			},
+		},
+	}
+
+	result, err := CreateTeamFn(nil, req)
+	assert.NoError(t, err)
+	require.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "permission mode invalid")
+}
+
+// TestAddTeamMemberFn_MissingRequiredParams tests error handling for missing required parameters
+func TestAddTeamMemberFn_MissingRequiredParams(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     map[string]interface{}
+		errField string
+	}{
+		{
+			name:     "missing id",
+			args:     map[string]interface{}{"user": "alice"},
+			errField: "id",
+		},
+		{
+			name:     "missing user",
+			args:     map[string]interface{}{"id": float64(3)},
+			errField: "user",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := mcp.CallToolRequest{
+				Params: mcp.CallToolParams{
+					Arguments: tt.args,
+				},
+			}
+
+			result, err := AddTeamMemberFn(nil, req)
+			assert.NoError(t, err)
+			require.True(t, result.IsError)
+			assert.Contains(t, result.Content[0].(mcp.TextContent).Text, tt.errField)
+		})
+	}
+}
@@ -0,0 +1,374 @@
+package org
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"codeberg.org/goern/forgejo-mcp/v2/operation/params"
+	"codeberg.org/goern/forgejo-mcp/v2/pkg/forgejo"
+	"codeberg.org/goern/forgejo-mcp/v2/pkg/log"
+	"codeberg.org/goern/forgejo-mcp/v2/pkg/paginate"
+	"codeberg.org/goern/forgejo-mcp/v2/pkg/to"
+
+	forgejo_sdk "codeberg.org/mvdkleijn/forgejo-sdk/forgejo/v2"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const (
+	ListOrgTeamsToolName     = "list_org_teams"
+	CreateTeamToolName       = "create_team"
+	EditTeamToolName         = "edit_team"
+	ListTeamMembersToolName  = "list_team_members"
+	AddTeamMemberToolName    = "add_team_member"
+	RemoveTeamMemberToolName = "remove_team_member"
+	ListTeamReposToolName    = "list_team_repos"
+	AddTeamRepoToolName      = "add_team_repo"
+	RemoveTeamRepoToolName   = "remove_team_repo"
+)
+
+var (
+	ListOrgTeamsTool = mcp.NewTool(
+		ListOrgTeamsToolName,
+		mcp.WithDescription("List organization teams"),
+		to.WithResult[*to.Page[*forgejo_sdk.Team]](),
+		mcp.WithString("org", mcp.Required(), mcp.Description(params.Org)),
+		mcp.WithNumber("page", mcp.Description(params.Page), mcp.DefaultNumber(1)),
+		mcp.WithNumber("limit", mcp.Description(params.Limit), mcp.DefaultNumber(50)),
+	)
+
+	CreateTeamTool = mcp.NewTool(
+		CreateTeamToolName,
+		mcp.WithDescription("Create organization team"),
+		to.WithResult[*forgejo_sdk.Team](),
+		mcp.WithString("org", mcp.Required(), mcp.Description(params.Org)),
+		mcp.WithString("name", mcp.Required(), mcp.Description("Team name")),
+		mcp.WithString("description", mcp.Description(params.Description)),
+		mcp.WithString("permission", mcp.Description(params.TeamPermission), mcp.DefaultString("read")),
+		mcp.WithBoolean("can_create_org_repo", mcp.Description("Members can create org repos")),
+		mcp.WithBoolean("includes_all_repositories", mcp.Description("Team has access to all org repos")),
+		mcp.WithString("units", mcp.Description(params.TeamUnits)),
+	)
+
+	EditTeamTool = mcp.NewTool(
+		EditTeamToolName,
+		mcp.WithDescription("Edit organization team"),
+		to.WithResult[*forgejo_sdk.Team](),
+		mcp.WithNumber("id", mcp.Required(), mcp.Description(params.TeamID)),
+		mcp.WithString("name", mcp.Description("New team name")),
+		mcp.WithString("description", mcp.Description("New description")),
+		mcp.WithString("permission", mcp.Description(params.TeamPermission)),
+		mcp.WithBoolean("can_create_org_repo", mcp.Description("Members can create org repos")),
+		mcp.WithBoolean("includes_all_repositories", mcp.Description("Team has access to all org repos")),
+		mcp.WithString("units", mcp.Description(params.TeamUnits)),
+	)
+
+	ListTeamMembersTool = mcp.NewTool(
+		ListTeamMembersToolName,
+		mcp.WithDescription("List team members"),
+		to.WithResult[*to.Page[*forgejo_sdk.User]](),
+		mcp.WithNumber("id", mcp.Required(), mcp.Description(params.TeamID)),
+		mcp.WithNumber("page", mcp.Description(params.Page), mcp.DefaultNumber(1)),
+		mcp.WithNumber("limit", mcp.Description(params.Limit), mcp.DefaultNumber(50)),
+	)
+
+	AddTeamMemberTool = mcp.NewTool(
+		AddTeamMemberToolName,
+		mcp.WithDescription("Add user to team (also adds them to the org)"),
+		to.WithResult[string](),
+		mcp.WithNumber("id", mcp.Required(), mcp.Description(params.TeamID)),
+		mcp.WithString("user", mcp.Required(), mcp.Description(params.User)),
+	)
+
+	RemoveTeamMemberTool = mcp.NewTool(
+		RemoveTeamMemberToolName,
+		mcp.WithDescription("Remove user from team"),
+		to.WithResult[string](),
+		mcp.WithNumber("id", mcp.Required(), mcp.Description(params.TeamID)),
+		mcp.WithString("user", mcp.Required(), mcp.Description(params.User)),
+	)
+
+	ListTeamReposTool = mcp.NewTool(
+		ListTeamReposToolName,
+		mcp.WithDescription("List team repos"),
+		to.WithResult[*to.Page[*forgejo_sdk.Repository]](),
+		mcp.WithNumber("id", mcp.Required(), mcp.Description(params.TeamID)),
+		mcp.WithNumber("page", mcp.Description(params.Page), mcp.DefaultNumber(1)),
+		mcp.WithNumber("limit", mcp.Description(params.Limit), mcp.DefaultNumber(50)),
+	)
+
+	AddTeamRepoTool = mcp.NewTool(
+		AddTeamRepoToolName,
+		mcp.WithDescription("Grant team access to an org repo"),
+		to.WithResult[string](),
+		mcp.WithNumber("id", mcp.Required(), mcp.Description(params.TeamID)),
+		mcp.WithString("org", mcp.Required(), mcp.Description(params.Org)),
+		mcp.WithString("repo", mcp.Required(), mcp.Description(params.Repo)),
+	)
+
+	RemoveTeamRepoTool = mcp.NewTool(
+		RemoveTeamRepoToolName,
+		mcp.WithDescription("Revoke team access to an org repo"),
+		to.WithResult[string](),
+		mcp.WithNumber("id", mcp.Required(), mcp.Description(params.TeamID)),
+		mcp.WithString("org", mcp.Required(), mcp.Description(params.Org)),
+		mcp.WithString("repo", mcp.Required(), mcp.Description(params.Repo)),
+	)
+)
+
+// parseUnits turns a comma-separated unit list into repo unit types,
+// accepting both "code" and "repo.code" forms
+func parseUnits(units string) []forgejo_sdk.RepoUnitType {
+	var result []forgejo_sdk.RepoUnitType
+	for _, unit := range strings.Split(units, ",") {
+		unit = strings.TrimSpace(unit)
+		if unit == "" {
+			continue
+		}
+		if !strings.HasPrefix(unit, "repo.") {
+			unit = "repo." + unit
+		}
+		result = append(result, forgejo_sdk.RepoUnitType(unit))
+	}
+	return result
+}
+
+func ListOrgTeamsFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called ListOrgTeamsFn")
+	org, err := req.RequireString("org")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	paging, err := paginate.FromRequest(req, 50)
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+
+	teams, err := paginate.List(ctx, paging, func(lo forgejo_sdk.ListOptions) ([]*forgejo_sdk.Team, *forgejo_sdk.Response, error) {
+		return forgejo.ClientCtx(ctx).ListOrgTeams(org, forgejo_sdk.ListTeamsOptions{ListOptions: lo})
+	})
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("list org teams err: %v", err))
+	}
+	return to.TextResult(teams)
+}
+
+func CreateTeamFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called CreateTeamFn")
+	org, err := req.RequireString("org")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	name, err := req.RequireString("name")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	description := req.GetString("description", "")
+	permission := req.GetString("permission", "read")
+	canCreateOrgRepo := req.GetBool("can_create_org_repo", false)
+	includesAllRepos := req.GetBool("includes_all_repositories", false)
+	units := req.GetString("units", "")
+
+	opt := forgejo_sdk.CreateTeamOption{
+		Name:                    name,
+		Description:             description,
+		Permission:              forgejo_sdk.AccessMode(permission),
+		CanCreateOrgRepo:        canCreateOrgRepo,
+		IncludesAllRepositories: includesAllRepos,
+		Units:                   parseUnits(units),
+	}
+	if len(opt.Units) == 0 {
+		opt.Units = []forgejo_sdk.RepoUnitType{
+			forgejo_sdk.RepoUnitCode,
+			forgejo_sdk.RepoUnitIssues,
+			forgejo_sdk.RepoUnitPulls,
+			forgejo_sdk.RepoUnitReleases,
+			forgejo_sdk.RepoUnitWiki,
+		}
+	}
+	if err := opt.Validate(); err != nil {
+		return to.ErrorResult(to.InvalidArgument("invalid team options: %v", err))
+	}
+
+	team, _, err := forgejo.ClientCtx(ctx).CreateTeam(org, opt)
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("create team err: %v", err))
+	}
+	return to.TextResult(team)
+}
+
+func EditTeamFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called EditTeamFn")
+	id, err := req.RequireFloat("id")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	args := req.GetArguments()
+
+	// Forgejo requires name and permission on every edit, so start from the current team
+	team, _, err := forgejo.ClientCtx(ctx).GetTeam(int64(id))
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("get team err: %v", err))
+	}
+
+	opt := forgejo_sdk.EditTeamOption{
+		Name:       team.Name,
+		Permission: team.Permission,
+		Units:      team.Units,
+	}
+	if name := req.GetString("name", ""); name != "" {
+		opt.Name = name
+	}
+	if _, ok := args["description"]; ok {
+		description := req.GetString("description", "")
+		opt.Description = &description
+	}
+	if permission := req.GetString("permission", ""); permission != "" {
+		opt.Permission = forgejo_sdk.AccessMode(permission)
+	}
+	if _, ok := args["can_create_org_repo"]; ok {
+		canCreateOrgRepo := req.GetBool("can_create_org_repo", false)
+		opt.CanCreateOrgRepo = &canCreateOrgRepo
+	}
+	if _, ok := args["includes_all_repositories"]; ok {
+		includesAllRepos := req.GetBool("includes_all_repositories", false)
+		opt.IncludesAllRepositories = &includesAllRepos
+	}
+	if units := req.GetString("units", ""); units != "" {
+		opt.Units = parseUnits(units)
+	}
+	if err := opt.Validate(); err != nil {
+		return to.ErrorResult(to.InvalidArgument("invalid team options: %v", err))
+	}
+
+	_, err = forgejo.ClientCtx(ctx).EditTeam(int64(id), opt)
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("edit team err: %v", err))
+	}
+
+	team, _, err = forgejo.ClientCtx(ctx).GetTeam(int64(id))
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("get updated team err: %v", err))
+	}
+	return to.TextResult(team)
+}
+
+func ListTeamMembersFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called ListTeamMembersFn")
+	id, err := req.RequireFloat("id")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	paging, err := paginate.FromRequest(req, 50)
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+
+	members, err := paginate.List(ctx, paging, func(lo forgejo_sdk.ListOptions) ([]*forgejo_sdk.User, *forgejo_sdk.Response, error) {
+		return forgejo.ClientCtx(ctx).ListTeamMembers(int64(id), forgejo_sdk.ListTeamMembersOptions{ListOptions: lo})
+	})
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("list team members err: %v", err))
+	}
+	return to.TextResult(members)
+}
+
+func AddTeamMemberFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called AddTeamMemberFn")
+	id, err := req.RequireFloat("id")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	user, err := req.RequireString("user")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+
+	_, err = forgejo.ClientCtx(ctx).AddTeamMember(int64(id), user)
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("add team member err: %v", err))
+	}
+	return to.TextResult("Add team member success")
+}
+
+func RemoveTeamMemberFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called RemoveTeamMemberFn")
+	id, err := req.RequireFloat("id")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	user, err := req.RequireString("user")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+
+	_, err = forgejo.ClientCtx(ctx).RemoveTeamMember(int64(id), user)
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("remove team member err: %v", err))
+	}
+	return to.TextResult("Remove team member success")
+}
+
+func ListTeamReposFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called ListTeamReposFn")
+	id, err := req.RequireFloat("id")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	paging, err := paginate.FromRequest(req, 50)
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+
+	repos, err := paginate.List(ctx, paging, func(lo forgejo_sdk.ListOptions) ([]*forgejo_sdk.Repository, *forgejo_sdk.Response, error) {
+		return forgejo.ClientCtx(ctx).ListTeamRepositories(int64(id), forgejo_sdk.ListTeamRepositoriesOptions{ListOptions: lo})
+	})
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("list team repos err: %v", err))
+	}
+	return to.TextResult(repos)
+}
+
+func AddTeamRepoFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called AddTeamRepoFn")
+	id, err := req.RequireFloat("id")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	org, err := req.RequireString("org")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	repo, err := req.RequireString("repo")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+
+	_, err = forgejo.ClientCtx(ctx).AddTeamRepository(int64(id), org, repo)
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("add team repo err: %v", err))
+	}
+	return to.TextResult("Add team repo success")
+}
+
+func RemoveTeamRepoFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called RemoveTeamRepoFn")
+	id, err := req.RequireFloat("id")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	org, err := req.RequireString("org")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	repo, err := req.RequireString("repo")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+
+	_, err = forgejo.ClientCtx(ctx).RemoveTeamRepository(int64(id), org, repo)
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("remove team repo err: %v", err))
+	}
+	return to.TextResult("Remove team repo success")
+}
@@ -0,0 +1,304 @@
+package packages
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"codeberg.org/goern/forgejo-mcp/v2/operation/params"
+	"codeberg.org/goern/forgejo-mcp/v2/pkg/forgejo"
+	"codeberg.org/goern/forgejo-mcp/v2/pkg/log"
+	"codeberg.org/goern/forgejo-mcp/v2/pkg/paginate"
+	"codeberg.org/goern/forgejo-mcp/v2/pkg/to"
+
+	forgejo_sdk "codeberg.org/mvdkleijn/forgejo-sdk/forgejo/v2"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const (
+	ListPackagesToolName         = "list_packages"
+	ListPackageVersionsToolName  = "list_package_versions"
+	GetPackageVersionToolName    = "get_package_version"
+	DeletePackageVersionToolName = "delete_package_version"
+	LinkPackageToolName          = "link_package"
+	UnlinkPackageToolName        = "unlink_package"
+)
+
+// PackageVersion is a package version together with its files
+type PackageVersion struct {
+	Package *forgejo_sdk.Package       `json:"package"`
+	Files   []*forgejo_sdk.PackageFile `json:"files"`
+}
+
+// packageVersionView is the compact form of a PackageVersion
+type packageVersionView struct {
+	Package *to.PackageView            `json:"package"`
+	Files   []*forgejo_sdk.PackageFile `json:"files"`
+}
+
+func (v *PackageVersion) CompactView() any {
+	if v == nil {
+		return (*packageVersionView)(nil)
+	}
+	return &packageVersionView{Package: to.View[*to.PackageView](v.Package), Files: v.Files}
+}
+
+var (
+	ListPackagesTool = mcp.NewTool(
+		ListPackagesToolName,
+		mcp.WithDescription("List packages of a user or org (one entry per version)"),
+		to.WithResult[*to.Page[*forgejo_sdk.Package]](),
+		mcp.WithString("owner", mcp.Required(), mcp.Description(params.PackageOwner)),
+		mcp.WithString("type", mcp.Description(params.PackageType)),
+		mcp.WithString("name", mcp.Description("Name filter (substring match)")),
+		mcp.WithNumber("page", mcp.Description(params.Page), mcp.DefaultNumber(1)),
+		mcp.WithNumber("limit", mcp.Description(params.Limit), mcp.DefaultNumber(20)),
+	)
+
+	ListPackageVersionsTool = mcp.NewTool(
+		ListPackageVersionsToolName,
+		mcp.WithDescription("List versions of a package"),
+		to.WithResult[*to.Page[*forgejo_sdk.Package]](),
+		mcp.WithString("owner", mcp.Required(), mcp.Description(params.PackageOwner)),
+		mcp.WithString("type", mcp.Required(), mcp.Description(params.PackageType)),
+		mcp.WithString("name", mcp.Required(), mcp.Description(params.PackageName)),
+		mcp.WithNumber("page", mcp.Description(params.Page), mcp.DefaultNumber(1)),
+		mcp.WithNumber("limit", mcp.Description(params.Limit), mcp.DefaultNumber(20)),
+	)
+
+	GetPackageVersionTool = mcp.NewTool(
+		GetPackageVersionToolName,
+		mcp.WithDescription("Get package version details and files"),
+		to.WithResult[*PackageVersion](),
+		mcp.WithString("owner", mcp.Required(), mcp.Description(params.PackageOwner)),
+		mcp.WithString("type", mcp.Required(), mcp.Description(params.PackageType)),
+		mcp.WithString("name", mcp.Required(), mcp.Description(params.PackageName)),
+		mcp.WithString("version", mcp.Required(), mcp.Description(params.PackageVersion)),
+	)
+
+	DeletePackageVersionTool = mcp.NewTool(
+		DeletePackageVersionToolName,
+		mcp.WithDescription("Delete package version"),
+		to.WithResult[string](),
+		mcp.WithString("owner", mcp.Required(), mcp.Description(params.PackageOwner)),
+		mcp.WithString("type", mcp.Required(), mcp.Description(params.PackageType)),
+		mcp.WithString("name", mcp.Required(), mcp.Description(params.PackageName)),
+		mcp.WithString("version", mcp.Required(), mcp.Description(params.PackageVersion)),
+	)
+
+	LinkPackageTool = mcp.NewTool(
+		LinkPackageToolName,
+		mcp.WithDescription("Link package to a repo of the same owner"),
+		to.WithResult[string](),
+		mcp.WithString("owner", mcp.Required(), mcp.Description(params.PackageOwner)),
+		mcp.WithString("type", mcp.Required(), mcp.Description(params.PackageType)),
+		mcp.WithString("name", mcp.Required(), mcp.Description(params.PackageName)),
+		mcp.WithString("repo", mcp.Required(), mcp.Description(params.Repo)),
+	)
+
+	UnlinkPackageTool = mcp.NewTool(
+		UnlinkPackageToolName,
+		mcp.WithDescription("Unlink package from its repo"),
+		to.WithResult[string](),
+		mcp.WithString("owner", mcp.Required(), mcp.Description(params.PackageOwner)),
+		mcp.WithString("type", mcp.Required(), mcp.Description(params.PackageType)),
+		mcp.WithString("name", mcp.Required(), mcp.Description(params.PackageName)),
+	)
+)
+
+func RegisterTool(s *server.MCPServer) {
+	s.AddTool(ListPackagesTool, ListPackagesFn)
+	s.AddTool(ListPackageVersionsTool, ListPackageVersionsFn)
+	s.AddTool(GetPackageVersionTool, GetPackageVersionFn)
+	s.AddTool(DeletePackageVersionTool, DeletePackageVersionFn)
+	s.AddTool(LinkPackageTool, LinkPackageFn)
+	s.AddTool(UnlinkPackageTool, UnlinkPackageFn)
+}
+
+// packageArgs reads the owner, type and name arguments shared by most tools
+func packageArgs(req mcp.CallToolRequest) (owner, packageType, name string, err error) {
+	if owner, err = req.RequireString("owner"); err != nil {
+		return
+	}
+	if packageType, err = req.RequireString("type"); err != nil {
+		return
+	}
+	name, err = req.RequireString("name")
+	return
+}
+
+// listPackages queries the package list with the type and name filters the SDK does not expose
+func listPackages(ctx context.Context, owner, packageType, name string, paging paginate.Options) (*to.Page[*forgejo_sdk.Package], error) {
+	query := url.Values{}
+	if packageType != "" {
+		query.Set("type", packageType)
+	}
+	if name != "" {
+		query.Set("q", name)
+	}
+
+	return paginate.Fetch(ctx, paging, func(page, limit int) ([]*forgejo_sdk.Package, *http.Response, error) {
+		packages := []*forgejo_sdk.Package{}
+		resp, err := forgejo.Do(ctx, "GET", fmt.Sprintf("/packages/%s", url.PathEscape(owner)), paginate.Query(query, page, limit), nil, &packages)
+		return packages, resp, err
+	})
+}
+
+func ListPackagesFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called ListPackagesFn")
+	owner, err := req.RequireString("owner")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	paging, err := paginate.FromRequest(req, 20)
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+
+	packages, err := listPackages(ctx, owner, req.GetString("type", ""), req.GetString("name", ""), paging)
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("list packages err: %v", err))
+	}
+	return to.TextResult(packages)
+}
+
+func ListPackageVersionsFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called ListPackageVersionsFn")
+	owner, packageType, name, err := packageArgs(req)
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	paging, err := paginate.FromRequest(req, 20)
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+
+	versions, err := listVersions(ctx, owner, packageType, name, paging)
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("list package versions err: %v", err))
+	}
+	return to.TextResult(versions)
+}
+
+// listVersions returns the versions of exactly the package name. The name
+// filter of the API is a substring match, so whole pages of matches are
+// fetched and filtered while they fit in limit versions, or with All
+// MaxItems. next_page continues in the unfiltered pages and the total is
+// unknown. At most MaxItems unfiltered packages are scanned per call.
+func listVersions(ctx context.Context, owner, packageType, name string, paging paginate.Options) (*to.Page[*forgejo_sdk.Package], error) {
+	want := paging.Limit
+	if paging.All {
+		want = paginate.MaxItems()
+	}
+	result := &to.Page[*forgejo_sdk.Package]{Items: []*forgejo_sdk.Package{}, Page: paging.Page, Limit: paging.Limit}
+
+	scanned := 0
+	for page := paging.Page; ; {
+		packages, err := listPackages(ctx, owner, packageType, name, paginate.Options{Page: page, Limit: paging.Limit})
+		if err != nil {
+			return nil, err
+		}
+		scanned += len(packages.Items)
+		var matches []*forgejo_sdk.Package
+		for _, p := range packages.Items {
+			if p.Name == name {
+				matches = append(matches, p)
+			}
+		}
+		if page > paging.Page && len(result.Items)+len(matches) > want {
+			// Leave the page for next_page, which then repeats no version
+			result.HasMore = true
+			result.NextPage = &page
+			return result, nil
+		}
+		result.Items = append(result.Items, matches...)
+
+		if !packages.HasMore {
+			return result, nil
+		}
+		page = *packages.NextPage
+		if len(result.Items) >= want || scanned >= paginate.MaxItems() {
+			result.HasMore = true
+			result.NextPage = &page
+			return result, nil
+		}
+	}
+}
+
+func GetPackageVersionFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called GetPackageVersionFn")
+	owner, packageType, name, err := packageArgs(req)
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	version, err := req.RequireString("version")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+
+	pkg, _, err := forgejo.ClientCtx(ctx).GetPackage(owner, packageType, name, version)
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("get package version err: %v", err))
+	}
+	files, _, err := forgejo.ClientCtx(ctx).ListPackageFiles(owner, packageType, name, version)
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("list package files err: %v", err))
+	}
+	return to.TextResult(&PackageVersion{Package: pkg, Files: files})
+}
+
+func DeletePackageVersionFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called DeletePackageVersionFn")
+	owner, packageType, name, err := packageArgs(req)
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	version, err := req.RequireString("version")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+
+	_, err = forgejo.ClientCtx(ctx).DeletePackage(owner, packageType, name, version)
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("delete package version err: %v", err))
+	}
+	return to.TextResult("Delete package version success")
+}
+
+func LinkPackageFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called LinkPackageFn")
+	owner, packageType, name, err := packageArgs(req)
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	repo, err := req.RequireString("repo")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+
+	path := fmt.Sprintf("/packages/%s/%s/%s/-/link/%s",
+		url.PathEscape(owner), url.PathEscape(packageType), url.PathEscape(name), url.PathEscape(repo))
+	_, err = forgejo.Do(ctx, "POST", path, nil, nil, nil)
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("link package err: %v", err))
+	}
+	return to.TextResult(fmt.Sprintf("Package %s linked to %s/%s", name, owner, repo))
+}
+
+func UnlinkPackageFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called UnlinkPackageFn")
+	owner, packageType, name, err := packageArgs(req)
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+
+	path := fmt.Sprintf("/packages/%s/%s/%s/-/unlink",
+		url.PathEscape(owner), url.PathEscape(packageType), url.PathEscape(name))
+	_, err = forgejo.Do(ctx, "POST", path, nil, nil, nil)
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("unlink package err: %v", err))
+	}
+	return to.TextResult(fmt.Sprintf("Package %s unlinked", name))
+}
@@ -0,0 +1,128 @@
+package packages
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"codeberg.org/goern/forgejo-mcp/v2/pkg/flag"
+	"codeberg.org/goern/forgejo-mcp/v2/pkg/paginate"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestGetPackageVersionTool verifies the tool definition is correctly configured
+func TestGetPackageVersionTool(t *testing.T) {
+	tool := GetPackageVersionTool
+
+	assert.Equal(t, "get_package_version", tool.Name)
+	assert.NotNil(t, tool.Description)
+
+	for _, param := range []string{"owner", "type", "name", "version"} {
+		assert.Contains(t, tool.InputSchema.Required, param)
+	}
+}
+
+// TestListPackagesTool verifies type and name are optional filters
+func TestListPackagesTool(t *testing.T) {
+	tool := ListPackagesTool
+
+	assert.Equal(t, "list_packages", tool.Name)
+	assert.Contains(t, tool.InputSchema.Properties, "type")
+	assert.Contains(t, tool.InputSchema.Properties, "name")
+	assert.Equal(t, []string{"owner"}, tool.InputSchema.Required)
+}
+
+// TestLinkPackageFn_MissingRequiredParams tests error handling for missing required parameters
+func TestLinkPackageFn_MissingRequiredParams(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     map[string]interface{}
+		errField string
+	}{
+		{
+			name:     "missing type",
+			args:     map[string]interface{}{"owner": "goern", "name": "forgejo-mcp", "repo": "forgejo-mcp"},
+			errField: "type",
+		},
+		{
+			name:     "missing repo",
+			args:     map[string]interface{}{"owner": "goern", "type": "container", "name": "forgejo-mcp"},
+			errField: "repo",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := mcp.CallToolRequest{
+				Params: mcp.CallToolParams{
+					Arguments: tt.args,
+				},
+			}
+
+			result, err := LinkPackageFn(nil, req)
+			assert.NoError(t, err)
+			require.True(t, result.IsError)
+			assert.Contains(t, result.Content[0].(mcp.TextContent).Text, tt.errField)
+		})
+	}
+}
+
+// TestListVersions tests that versions are filtered to the exact package
+// name and paged until limit matches are collected
+func TestListVersions(t *testing.T) {
+	pages := [][]string{{"web", "website"}, {"website", "web"}, {"web", "web"}}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		if r.URL.Path != "/api/v1/packages/acme" || page < 1 || page > len(pages) {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("X-Total-Count", "6")
+		fmt.Fprint(w, "[")
+		for i, name := range pages[page-1] {
+			if i > 0 {
+				fmt.Fprint(w, ",")
+			}
+			fmt.Fprintf(w, `{"name":%q,"version":"%d.%d"}`, name, page, i)
+		}
+		fmt.Fprint(w, "]")
+	}))
+	defer srv.Close()
+	defer func(url string) { flag.URL = url }(flag.URL)
+	flag.URL = srv.URL
+
+	versions, err := listVersions(context.Background(), "acme", "npm", "web", paginate.Options{Page: 1, Limit: 2})
+	require.NoError(t, err)
+	require.Len(t, versions.Items, 2)
+	assert.Equal(t, "1.0", versions.Items[0].Version)
+	assert.Equal(t, "2.1", versions.Items[1].Version)
+	assert.Nil(t, versions.Total)
+	assert.True(t, versions.HasMore)
+	assert.Equal(t, 3, *versions.NextPage)
+
+	// Page 3 does not fit in the one free slot and is left for next_page
+	versions, err = listVersions(context.Background(), "acme", "npm", "web", paginate.Options{Page: 2, Limit: 2})
+	require.NoError(t, err)
+	require.Len(t, versions.Items, 1)
+	assert.Equal(t, "2.1", versions.Items[0].Version)
+	assert.True(t, versions.HasMore)
+	assert.Equal(t, 3, *versions.NextPage)
+
+	versions, err = listVersions(context.Background(), "acme", "npm", "web", paginate.Options{Page: 3, Limit: 2})
+	require.NoError(t, err)
+	require.Len(t, versions.Items, 2)
+	assert.Equal(t, "3.0", versions.Items[0].Version)
+	assert.False(t, versions.HasMore)
+
+	versions, err = listVersions(context.Background(), "acme", "npm", "web", paginate.Options{Page: 1, Limit: 2, All: true})
+	require.NoError(t, err)
+	assert.Len(t, versions.Items, 4)
+	assert.False(t, versions.HasMore)
+	assert.Nil(t, versions.NextPage)
+}
@@ -19,6 +19,14 @@ const (
 	Labels       = "Label IDs"
 	Milestone    = "Milestone ID"
 
+	// Issue or comment target parameters
+	TargetIndex     = "Issue/PR index (or comment_id)"
+	TargetCommentID = "Comment ID (instead of index)"
+	AttachmentID    = "Attachment ID"
+
+	// Reaction parameters
+	Reaction = "Reaction (e.g. +1, -1, laugh, hooray, confused, heart, rocket, eyes)"
+
 	// Branch parameters
 	Branch       = "Branch name"
 	OldBranch    = "Source branch"
@@ -37,10 +45,12 @@ const (
 	// Pagination parameters
 	Page  = "Page number (1-based)"
 	Limit = "Page size"
+	All   = "Follow all pages from page on, up to the server's item cap"
 
 	// Time parameters
-	Since  = "After time (RFC3339)"
-	Before = "Before time (RFC3339)"
+	Since    = "After time (RFC3339)"
+	Before   = "Before time (RFC3339)"
+	FeedDate = "Only activity on this day (YYYY-MM-DD)"
 
 	// Sort/filter parameters
 	Sort    = "Sort order"
@@ -51,11 +61,47 @@ const (
 	User = "Username"
 	Org  = "Organization name"
 
+	// Key parameters
+	KeyID    = "Key ID"
+	KeyTitle = "Key title"
+
+	// Team parameters
+	TeamID         = "Team ID"
+	TeamPermission = "Permission (read|write|admin)"
+	TeamUnits      = "Repo units (comma-separated, e.g. code,issues,pulls,releases,wiki)"
+
 	// Wiki parameters
 	WikiTitle   = "Wiki page title"
 	WikiContent = "Wiki page content"
 	WikiPage    = "Wiki page name"
 
+	// Actions parameters
+	ScopeRepo    = "Repository name (omit for org-level, owner is then the org)"
+	VariableName = "Variable name"
+	SecretName   = "Secret name"
+
+	// Webhook parameters
+	HookID           = "Webhook ID"
+	HookType         = "Hook type (forgejo|gitea|slack|matrix|discord)"
+	HookURL          = "Target URL"
+	HookSecret       = "Signing secret (write-only, never returned)"
+	HookConfig       = "Extra type-specific config, e.g. {\"channel\": \"#ci\"} for slack or {\"room_id\": \"!x:matrix.org\"} for matrix"
+	HookEvents       = "Events (comma-separated, e.g. push,pull_request,issues)"
+	HookBranchFilter = "Branch filter glob (e.g. main or {main,release/*})"
+
+	// Package parameters
+	PackageOwner   = "Package owner (user/org)"
+	PackageType    = "Package type (e.g. container, npm, go, generic)"
+	PackageName    = "Package name"
+	PackageVersion = "Package version"
+
+	// Notification parameters
+	ThreadID = "Notification thread ID"
+
+	// Output parameters
+	Format = "Output format (json|markdown, default from server config)"
+	Fields = "Only return these fields (comma-separated, e.g. number,title,user or user.login in full verbosity)"
+
 	// Misc parameters
 	Description = "Description"
 	Private     = "Private repo"
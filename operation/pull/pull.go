@@ -8,6 +8,7 @@ import (
 	"codeberg.org/goern/forgejo-mcp/v2/operation/params"
 	"codeberg.org/goern/forgejo-mcp/v2/pkg/forgejo"
 	"codeberg.org/goern/forgejo-mcp/v2/pkg/log"
+	"codeberg.org/goern/forgejo-mcp/v2/pkg/paginate"
 	"codeberg.org/goern/forgejo-mcp/v2/pkg/to"
 
 	forgejo_sdk "codeberg.org/mvdkleijn/forgejo-sdk/forgejo/v2"
@@ -26,6 +27,7 @@ var (
 	GetPullRequestByIndexTool = mcp.NewTool(
 		GetPullRequestByIndexToolName,
 		mcp.WithDescription("Get pull request by index"),
+		to.WithResult[*forgejo_sdk.PullRequest](),
 		mcp.WithString("owner", mcp.Required(), mcp.Description(params.Owner)),
 		mcp.WithString("repo", mcp.Required(), mcp.Description(params.Repo)),
 		mcp.WithNumber("index", mcp.Required(), mcp.Description(params.PRIndex)),
@@ -34,6 +36,7 @@ var (
 	ListRepoPullRequestsTool = mcp.NewTool(
 		ListRepoPullRequestsToolName,
 		mcp.WithDescription("List repo pull requests"),
+		to.WithResult[*to.Page[*forgejo_sdk.PullRequest]](),
 		mcp.WithString("owner", mcp.Required(), mcp.Description(params.Owner)),
 		mcp.WithString("repo", mcp.Required(), mcp.Description(params.Repo)),
 		mcp.WithString("state", mcp.Description("State (open|closed|all)"), mcp.DefaultString("open")),
@@ -47,6 +50,7 @@ var (
 	CreatePullRequestTool = mcp.NewTool(
 		CreatePullRequestToolName,
 		mcp.WithDescription("Create pull request"),
+		to.WithResult[*forgejo_sdk.PullRequest](),
 		mcp.WithString("owner", mcp.Required(), mcp.Description(params.Owner)),
 		mcp.WithString("repo", mcp.Required(), mcp.Description(params.Repo)),
 		mcp.WithString("head", mcp.Required(), mcp.Description(params.Head)),
@@ -58,6 +62,7 @@ var (
 	UpdatePullRequestTool = mcp.NewTool(
 		UpdatePullRequestToolName,
 		mcp.WithDescription("Update pull request"),
+		to.WithResult[*forgejo_sdk.PullRequest](),
 		mcp.WithString("owner", mcp.Required(), mcp.Description(params.Owner)),
 		mcp.WithString("repo", mcp.Required(), mcp.Description(params.Repo)),
 		mcp.WithNumber("index", mcp.Required(), mcp.Description(params.PRIndex)),
@@ -91,7 +96,7 @@ func GetPullRequestByIndexFn(ctx context.Context, req mcp.CallToolRequest) (*mcp
 		return to.ErrorResult(err)
 	}
 
-	pr, _, err := forgejo.Client().GetPullRequest(owner, repo, int64(index))
+	pr, _, err := forgejo.ClientCtx(ctx).GetPullRequest(owner, repo, int64(index))
 	if err != nil {
 		return to.ErrorResult(fmt.Errorf("get pull request err: %v", err))
 	}
@@ -110,8 +115,10 @@ func ListRepoPullRequestsFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.
 	}
 	state := req.GetString("state", "open")
 	sort := req.GetString("sort", "")
-	page := req.GetFloat("page", 1)
-	limit := req.GetFloat("limit", 20)
+	paging, err := paginate.FromRequest(req, 20)
+	if err != nil {
+		return to.ErrorResult(err)
+	}
 
 	// Convert milestone from string to int64 if provided
 	// Note: Not using milestoneID since it's not supported in the current Forgejo SDK
@@ -121,16 +128,15 @@ func ListRepoPullRequestsFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.
 	opt := forgejo_sdk.ListPullRequestsOptions{
 		State: forgejo_sdk.StateType(state),
 		Sort:  sort,
-		ListOptions: forgejo_sdk.ListOptions{
-			Page:     int(page),
-			PageSize: int(limit),
-		},
 	}
 
 	// Only set milestone if provided and valid
 	// Note: Not using milestone as it's not supported in the current Forgejo SDK
 
-	prs, _, err := forgejo.Client().ListRepoPullRequests(owner, repo, opt)
+	prs, err := paginate.List(ctx, paging, func(lo forgejo_sdk.ListOptions) ([]*forgejo_sdk.PullRequest, *forgejo_sdk.Response, error) {
+		opt.ListOptions = lo
+		return forgejo.ClientCtx(ctx).ListRepoPullRequests(owner, repo, opt)
+	})
 	if err != nil {
 		return to.ErrorResult(fmt.Errorf("get pull request list err: %v", err))
 	}
@@ -167,7 +173,7 @@ func CreatePullRequestFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.Cal
 		Title: title,
 		Body:  body,
 	}
-	pr, _, err := forgejo.Client().CreatePullRequest(owner, repo, opt)
+	pr, _, err := forgejo.ClientCtx(ctx).CreatePullRequest(owner, repo, opt)
 	if err != nil {
 		return to.ErrorResult(fmt.Errorf("create pull request err: %v", err))
 	}
@@ -211,12 +217,12 @@ func UpdatePullRequestFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.Cal
 	if milestone != "" {
 		milestoneID, err := strconv.ParseInt(milestone, 10, 64)
 		if err != nil {
-			return to.ErrorResult(fmt.Errorf("invalid milestone ID: %v", err))
+			return to.ErrorResult(to.InvalidArgument("invalid milestone ID: %v", err))
 		}
 		opt.Milestone = milestoneID
 	}
 
-	pr, _, err := forgejo.Client().EditPullRequest(owner, repo, int64(index), opt)
+	pr, _, err := forgejo.ClientCtx(ctx).EditPullRequest(owner, repo, int64(index), opt)
 	if err != nil {
 		return to.ErrorResult(fmt.Errorf("update pull request err: %v", err))
 	}
@@ -7,6 +7,7 @@ import (
 	"codeberg.org/goern/forgejo-mcp/v2/operation/params"
 	"codeberg.org/goern/forgejo-mcp/v2/pkg/forgejo"
 	"codeberg.org/goern/forgejo-mcp/v2/pkg/log"
+	"codeberg.org/goern/forgejo-mcp/v2/pkg/paginate"
 	"codeberg.org/goern/forgejo-mcp/v2/pkg/to"
 
 	forgejo_sdk "codeberg.org/mvdkleijn/forgejo-sdk/forgejo/v2"
@@ -23,6 +24,7 @@ var (
 	CreateBranchTool = mcp.NewTool(
 		CreateBranchToolName,
 		mcp.WithDescription("Create branch"),
+		to.WithResult[string](),
 		mcp.WithString("owner", mcp.Required(), mcp.Description(params.Owner)),
 		mcp.WithString("repo", mcp.Required(), mcp.Description(params.Repo)),
 		mcp.WithString("branch", mcp.Required(), mcp.Description(params.Branch)),
@@ -32,6 +34,7 @@ var (
 	DeleteBranchTool = mcp.NewTool(
 		DeleteBranchToolName,
 		mcp.WithDescription("Delete branch"),
+		to.WithResult[string](),
 		mcp.WithString("owner", mcp.Required(), mcp.Description(params.Owner)),
 		mcp.WithString("repo", mcp.Required(), mcp.Description(params.Repo)),
 		mcp.WithString("branch", mcp.Required(), mcp.Description(params.Branch)),
@@ -40,10 +43,11 @@ var (
 	ListBranchesTool = mcp.NewTool(
 		ListBranchesToolName,
 		mcp.WithDescription("List branches"),
+		to.WithResult[*to.Page[*forgejo_sdk.Branch]](),
 		mcp.WithString("owner", mcp.Required(), mcp.Description(params.Owner)),
 		mcp.WithString("repo", mcp.Required(), mcp.Description(params.Repo)),
-		mcp.WithNumber("page", mcp.Required(), mcp.Description(params.Page), mcp.DefaultNumber(1), mcp.Min(1)),
-		mcp.WithNumber("limit", mcp.Required(), mcp.Description(params.Limit), mcp.DefaultNumber(100), mcp.Min(1)),
+		mcp.WithNumber("page", mcp.Description(params.Page), mcp.DefaultNumber(1), mcp.Min(1)),
+		mcp.WithNumber("limit", mcp.Description(params.Limit), mcp.DefaultNumber(100), mcp.Min(1)),
 	)
 )
 
@@ -66,7 +70,7 @@ func CreateBranchFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallTool
 		return to.ErrorResult(err)
 	}
 
-	_, _, err = forgejo.Client().CreateBranch(owner, repo, forgejo_sdk.CreateBranchOption{
+	_, _, err = forgejo.ClientCtx(ctx).CreateBranch(owner, repo, forgejo_sdk.CreateBranchOption{
 		BranchName:    branch,
 		OldBranchName: oldBranch,
 	})
@@ -74,7 +78,7 @@ func CreateBranchFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallTool
 		return to.ErrorResult(fmt.Errorf("create branch error: %v", err))
 	}
 
-	return mcp.NewToolResultText("Branch Created"), nil
+	return to.TextResult("Branch Created")
 }
 
 func DeleteBranchFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -92,7 +96,7 @@ func DeleteBranchFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallTool
 		return to.ErrorResult(err)
 	}
 
-	success, _, err := forgejo.Client().DeleteRepoBranch(owner, repo, branch)
+	success, _, err := forgejo.ClientCtx(ctx).DeleteRepoBranch(owner, repo, branch)
 	if err != nil {
 		return to.ErrorResult(fmt.Errorf("delete branch err: %v", err))
 	}
@@ -112,23 +116,14 @@ func ListBranchesFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallTool
 	if err != nil {
 		return to.ErrorResult(err)
 	}
-	page, err := req.RequireFloat("page")
-	if err != nil {
-		return to.ErrorResult(err)
-	}
-	limit, err := req.RequireFloat("limit")
+	paging, err := paginate.FromRequest(req, 100)
 	if err != nil {
 		return to.ErrorResult(err)
 	}
 
-	opt := forgejo_sdk.ListRepoBranchesOptions{
-		ListOptions: forgejo_sdk.ListOptions{
-			Page:     int(page),
-			PageSize: int(limit),
-		},
-	}
-
-	branches, _, err := forgejo.Client().ListRepoBranches(owner, repo, opt)
+	branches, err := paginate.List(ctx, paging, func(lo forgejo_sdk.ListOptions) ([]*forgejo_sdk.Branch, *forgejo_sdk.Response, error) {
+		return forgejo.ClientCtx(ctx).ListRepoBranches(owner, repo, forgejo_sdk.ListRepoBranchesOptions{ListOptions: lo})
+	})
 	if err != nil {
 		return to.ErrorResult(fmt.Errorf("list branches err: %v", err))
 	}
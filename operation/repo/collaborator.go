@@ -0,0 +1,167 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+
+	"codeberg.org/goern/forgejo-mcp/v2/operation/params"
+	"codeberg.org/goern/forgejo-mcp/v2/pkg/forgejo"
+	"codeberg.org/goern/forgejo-mcp/v2/pkg/log"
+	"codeberg.org/goern/forgejo-mcp/v2/pkg/paginate"
+	"codeberg.org/goern/forgejo-mcp/v2/pkg/to"
+
+	forgejo_sdk "codeberg.org/mvdkleijn/forgejo-sdk/forgejo/v2"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const (
+	ListRepoCollaboratorsToolName         = "list_repo_collaborators"
+	GetRepoCollaboratorPermissionToolName = "get_repo_collaborator_permission"
+	AddRepoCollaboratorToolName           = "add_repo_collaborator"
+	RemoveRepoCollaboratorToolName        = "remove_repo_collaborator"
+)
+
+var (
+	ListRepoCollaboratorsTool = mcp.NewTool(
+		ListRepoCollaboratorsToolName,
+		mcp.WithDescription("List repo collaborators"),
+		to.WithResult[*to.Page[*forgejo_sdk.User]](),
+		mcp.WithString("owner", mcp.Required(), mcp.Description(params.Owner)),
+		mcp.WithString("repo", mcp.Required(), mcp.Description(params.Repo)),
+		mcp.WithNumber("page", mcp.Description(params.Page), mcp.DefaultNumber(1)),
+		mcp.WithNumber("limit", mcp.Description(params.Limit), mcp.DefaultNumber(20)),
+	)
+
+	GetRepoCollaboratorPermissionTool = mcp.NewTool(
+		GetRepoCollaboratorPermissionToolName,
+		mcp.WithDescription("Get permission of a user on a repo"),
+		to.WithResult[*forgejo_sdk.CollaboratorPermissionResult](),
+		mcp.WithString("owner", mcp.Required(), mcp.Description(params.Owner)),
+		mcp.WithString("repo", mcp.Required(), mcp.Description(params.Repo)),
+		mcp.WithString("user", mcp.Required(), mcp.Description(params.User)),
+	)
+
+	AddRepoCollaboratorTool = mcp.NewTool(
+		AddRepoCollaboratorToolName,
+		mcp.WithDescription("Add or update repo collaborator"),
+		to.WithResult[*forgejo_sdk.CollaboratorPermissionResult](),
+		mcp.WithString("owner", mcp.Required(), mcp.Description(params.Owner)),
+		mcp.WithString("repo", mcp.Required(), mcp.Description(params.Repo)),
+		mcp.WithString("user", mcp.Required(), mcp.Description(params.User)),
+		mcp.WithString("permission", mcp.Description("Permission (read|write|admin)"), mcp.DefaultString("write")),
+	)
+
+	RemoveRepoCollaboratorTool = mcp.NewTool(
+		RemoveRepoCollaboratorToolName,
+		mcp.WithDescription("Remove repo collaborator"),
+		to.WithResult[string](),
+		mcp.WithString("owner", mcp.Required(), mcp.Description(params.Owner)),
+		mcp.WithString("repo", mcp.Required(), mcp.Description(params.Repo)),
+		mcp.WithString("user", mcp.Required(), mcp.Description(params.User)),
+	)
+)
+
+func ListRepoCollaboratorsFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called ListRepoCollaboratorsFn")
+	owner, err := req.RequireString("owner")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	repo, err := req.RequireString("repo")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	paging, err := paginate.FromRequest(req, 20)
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+
+	users, err := paginate.List(ctx, paging, func(lo forgejo_sdk.ListOptions) ([]*forgejo_sdk.User, *forgejo_sdk.Response, error) {
+		return forgejo.ClientCtx(ctx).ListCollaborators(owner, repo, forgejo_sdk.ListCollaboratorsOptions{ListOptions: lo})
+	})
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("list repo collaborators err: %v", err))
+	}
+	return to.TextResult(users)
+}
+
+func GetRepoCollaboratorPermissionFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called GetRepoCollaboratorPermissionFn")
+	owner, err := req.RequireString("owner")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	repo, err := req.RequireString("repo")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	user, err := req.RequireString("user")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+
+	perm, _, err := forgejo.ClientCtx(ctx).CollaboratorPermission(owner, repo, user)
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("get collaborator permission err: %v", err))
+	}
+	return to.TextResult(perm)
+}
+
+func AddRepoCollaboratorFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called AddRepoCollaboratorFn")
+	owner, err := req.RequireString("owner")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	repo, err := req.RequireString("repo")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	user, err := req.RequireString("user")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	permission := forgejo_sdk.AccessMode(req.GetString("permission", "write"))
+
+	switch permission {
+	case forgejo_sdk.AccessModeRead, forgejo_sdk.AccessModeWrite, forgejo_sdk.AccessModeAdmin:
+	default:
+		return to.ErrorResult(to.InvalidArgument("invalid permission '%s': must be read, write or admin", permission))
+	}
+	opt := forgejo_sdk.AddCollaboratorOption{
+		Permission: &permission,
+	}
+
+	_, err = forgejo.ClientCtx(ctx).AddCollaborator(owner, repo, user, opt)
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("add repo collaborator err: %v", err))
+	}
+
+	perm, _, err := forgejo.ClientCtx(ctx).CollaboratorPermission(owner, repo, user)
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("get collaborator permission err: %v", err))
+	}
+	return to.TextResult(perm)
+}
+
+func RemoveRepoCollaboratorFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called RemoveRepoCollaboratorFn")
+	owner, err := req.RequireString("owner")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	repo, err := req.RequireString("repo")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	user, err := req.RequireString("user")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+
+	_, err = forgejo.ClientCtx(ctx).DeleteCollaborator(owner, repo, user)
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("remove repo collaborator err: %v", err))
+	}
+	return to.TextResult("Remove collaborator success")
+}
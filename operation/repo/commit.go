@@ -7,6 +7,7 @@ import (
 	"codeberg.org/goern/forgejo-mcp/v2/operation/params"
 	"codeberg.org/goern/forgejo-mcp/v2/pkg/forgejo"
 	"codeberg.org/goern/forgejo-mcp/v2/pkg/log"
+	"codeberg.org/goern/forgejo-mcp/v2/pkg/paginate"
 	"codeberg.org/goern/forgejo-mcp/v2/pkg/to"
 
 	forgejo_sdk "codeberg.org/mvdkleijn/forgejo-sdk/forgejo/v2"
@@ -21,12 +22,13 @@ var (
 	ListRepoCommitsTool = mcp.NewTool(
 		ListRepoCommitsToolName,
 		mcp.WithDescription("List repo commits"),
+		to.WithResult[*to.Page[*forgejo_sdk.Commit]](),
 		mcp.WithString("owner", mcp.Required(), mcp.Description(params.Owner)),
 		mcp.WithString("repo", mcp.Required(), mcp.Description(params.Repo)),
 		mcp.WithString("path", mcp.Description("File/dir path")),
 		mcp.WithString("sha", mcp.Description("SHA/branch to start from")),
-		mcp.WithNumber("page", mcp.Required(), mcp.Description(params.Page), mcp.DefaultNumber(1), mcp.Min(1)),
-		mcp.WithNumber("limit", mcp.Required(), mcp.Description(params.Limit), mcp.DefaultNumber(100), mcp.Min(1)),
+		mcp.WithNumber("page", mcp.Description(params.Page), mcp.DefaultNumber(1), mcp.Min(1)),
+		mcp.WithNumber("limit", mcp.Description(params.Limit), mcp.DefaultNumber(100), mcp.Min(1)),
 	)
 )
 
@@ -42,23 +44,19 @@ func ListRepoCommitsFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallT
 	}
 	path := req.GetString("path", "")
 	sha := req.GetString("sha", "")
-	page, err := req.RequireFloat("page")
-	if err != nil {
-		return to.ErrorResult(err)
-	}
-	limit, err := req.RequireFloat("limit")
+	paging, err := paginate.FromRequest(req, 100)
 	if err != nil {
 		return to.ErrorResult(err)
 	}
+
 	opt := forgejo_sdk.ListCommitOptions{
 		Path: path,
 		SHA:  sha,
-		ListOptions: forgejo_sdk.ListOptions{
-			Page:     int(page),
-			PageSize: int(limit),
-		},
 	}
-	commits, _, err := forgejo.Client().ListRepoCommits(owner, repo, opt)
+	commits, err := paginate.List(ctx, paging, func(lo forgejo_sdk.ListOptions) ([]*forgejo_sdk.Commit, *forgejo_sdk.Response, error) {
+		opt.ListOptions = lo
+		return forgejo.ClientCtx(ctx).ListRepoCommits(owner, repo, opt)
+	})
 	if err != nil {
 		return to.ErrorResult(fmt.Errorf("list repo commits error: %v", err))
 	}
@@ -0,0 +1,135 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+
+	"codeberg.org/goern/forgejo-mcp/v2/operation/params"
+	"codeberg.org/goern/forgejo-mcp/v2/pkg/forgejo"
+	"codeberg.org/goern/forgejo-mcp/v2/pkg/log"
+	"codeberg.org/goern/forgejo-mcp/v2/pkg/paginate"
+	"codeberg.org/goern/forgejo-mcp/v2/pkg/to"
+
+	forgejo_sdk "codeberg.org/mvdkleijn/forgejo-sdk/forgejo/v2"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const (
+	ListDeployKeysToolName  = "list_deploy_keys"
+	AddDeployKeyToolName    = "add_deploy_key"
+	DeleteDeployKeyToolName = "delete_deploy_key"
+)
+
+var (
+	ListDeployKeysTool = mcp.NewTool(
+		ListDeployKeysToolName,
+		mcp.WithDescription("List repo deploy keys with fingerprints"),
+		to.WithResult[*to.Page[*forgejo_sdk.DeployKey]](),
+		mcp.WithString("owner", mcp.Required(), mcp.Description(params.Owner)),
+		mcp.WithString("repo", mcp.Required(), mcp.Description(params.Repo)),
+		mcp.WithString("fingerprint", mcp.Description("Only the key with this fingerprint")),
+		mcp.WithNumber("page", mcp.Description(params.Page), mcp.DefaultNumber(1)),
+		mcp.WithNumber("limit", mcp.Description(params.Limit), mcp.DefaultNumber(20)),
+	)
+
+	AddDeployKeyTool = mcp.NewTool(
+		AddDeployKeyToolName,
+		mcp.WithDescription("Add repo deploy key"),
+		to.WithResult[*forgejo_sdk.DeployKey](),
+		mcp.WithString("owner", mcp.Required(), mcp.Description(params.Owner)),
+		mcp.WithString("repo", mcp.Required(), mcp.Description(params.Repo)),
+		mcp.WithString("title", mcp.Required(), mcp.Description(params.KeyTitle)),
+		mcp.WithString("key", mcp.Required(), mcp.Description("Public SSH key")),
+		mcp.WithBoolean("read_only", mcp.Description("Read-only access (false grants push)"), mcp.DefaultBool(true)),
+	)
+
+	DeleteDeployKeyTool = mcp.NewTool(
+		DeleteDeployKeyToolName,
+		mcp.WithDescription("Delete repo deploy key"),
+		to.WithResult[string](),
+		mcp.WithString("owner", mcp.Required(), mcp.Description(params.Owner)),
+		mcp.WithString("repo", mcp.Required(), mcp.Description(params.Repo)),
+		mcp.WithNumber("id", mcp.Required(), mcp.Description(params.KeyID)),
+	)
+)
+
+func ListDeployKeysFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called ListDeployKeysFn")
+	owner, err := req.RequireString("owner")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	repo, err := req.RequireString("repo")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	paging, err := paginate.FromRequest(req, 20)
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+
+	opt := forgejo_sdk.ListDeployKeysOptions{
+		Fingerprint: req.GetString("fingerprint", ""),
+	}
+	keys, err := paginate.List(ctx, paging, func(lo forgejo_sdk.ListOptions) ([]*forgejo_sdk.DeployKey, *forgejo_sdk.Response, error) {
+		opt.ListOptions = lo
+		return forgejo.ClientCtx(ctx).ListDeployKeys(owner, repo, opt)
+	})
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("list deploy keys err: %v", err))
+	}
+	return to.TextResult(keys)
+}
+
+func AddDeployKeyFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called AddDeployKeyFn")
+	owner, err := req.RequireString("owner")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	repo, err := req.RequireString("repo")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	title, err := req.RequireString("title")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	key, err := req.RequireString("key")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+
+	opt := forgejo_sdk.CreateKeyOption{
+		Title:    title,
+		Key:      key,
+		ReadOnly: req.GetBool("read_only", true),
+	}
+	deployKey, _, err := forgejo.ClientCtx(ctx).CreateDeployKey(owner, repo, opt)
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("add deploy key err: %v", err))
+	}
+	return to.TextResult(deployKey)
+}
+
+func DeleteDeployKeyFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called DeleteDeployKeyFn")
+	owner, err := req.RequireString("owner")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	repo, err := req.RequireString("repo")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	id, err := req.RequireFloat("id")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+
+	_, err = forgejo.ClientCtx(ctx).DeleteDeployKey(owner, repo, int64(id))
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("delete deploy key err: %v", err))
+	}
+	return to.TextResult("Delete deploy key success")
+}
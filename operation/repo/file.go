@@ -24,6 +24,7 @@ var (
 	GetFileContentTool = mcp.NewTool(
 		GetFileToolName,
 		mcp.WithDescription("Get file content"),
+		to.WithResult[*forgejo_sdk.ContentsResponse](),
 		mcp.WithString("owner", mcp.Required(), mcp.Description(params.Owner)),
 		mcp.WithString("repo", mcp.Required(), mcp.Description(params.Repo)),
 		mcp.WithString("ref", mcp.Required(), mcp.Description(params.Ref)),
@@ -33,6 +34,7 @@ var (
 	CreateFileTool = mcp.NewTool(
 		CreateFileToolName,
 		mcp.WithDescription("Create file"),
+		to.WithResult[*forgejo_sdk.FileResponse](),
 		mcp.WithString("owner", mcp.Required(), mcp.Description(params.Owner)),
 		mcp.WithString("repo", mcp.Required(), mcp.Description(params.Repo)),
 		mcp.WithString("filePath", mcp.Required(), mcp.Description(params.FilePath)),
@@ -45,6 +47,7 @@ var (
 	UpdateFileTool = mcp.NewTool(
 		UpdateFileToolName,
 		mcp.WithDescription("Update file"),
+		to.WithResult[*forgejo_sdk.FileResponse](),
 		mcp.WithString("owner", mcp.Required(), mcp.Description(params.Owner)),
 		mcp.WithString("repo", mcp.Required(), mcp.Description(params.Repo)),
 		mcp.WithString("filePath", mcp.Required(), mcp.Description(params.FilePath)),
@@ -58,6 +61,7 @@ var (
 	DeleteFileTool = mcp.NewTool(
 		DeleteFileToolName,
 		mcp.WithDescription("Delete file"),
+		to.WithResult[string](),
 		mcp.WithString("owner", mcp.Required(), mcp.Description(params.Owner)),
 		mcp.WithString("repo", mcp.Required(), mcp.Description(params.Repo)),
 		mcp.WithString("filePath", mcp.Required(), mcp.Description(params.FilePath)),
@@ -86,7 +90,7 @@ func GetFileContentFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallTo
 	if err != nil {
 		return to.ErrorResult(err)
 	}
-	content, _, err := forgejo.Client().GetContents(owner, repo, ref, filePath)
+	content, _, err := forgejo.ClientCtx(ctx).GetContents(owner, repo, ref, filePath)
 	if err != nil {
 		return to.ErrorResult(fmt.Errorf("get file err: %v", err))
 	}
@@ -128,7 +132,7 @@ func CreateFileFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolRe
 		},
 		Content: content,
 	}
-	fileResp, _, err := forgejo.Client().CreateFile(owner, repo, filePath, opt)
+	fileResp, _, err := forgejo.ClientCtx(ctx).CreateFile(owner, repo, filePath, opt)
 	if err != nil {
 		return to.ErrorResult(fmt.Errorf("create file error: %v", err))
 	}
@@ -175,7 +179,7 @@ func UpdateFileFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolRe
 		SHA:     sha,
 		Content: content,
 	}
-	fileResp, _, err := forgejo.Client().UpdateFile(owner, repo, filePath, opt)
+	fileResp, _, err := forgejo.ClientCtx(ctx).UpdateFile(owner, repo, filePath, opt)
 	if err != nil {
 		return to.ErrorResult(fmt.Errorf("update file error: %v", err))
 	}
@@ -215,7 +219,7 @@ func DeleteFileFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolRe
 		},
 		SHA: sha,
 	}
-	_, err = forgejo.Client().DeleteFile(owner, repo, filePath, opt)
+	_, err = forgejo.ClientCtx(ctx).DeleteFile(owner, repo, filePath, opt)
 	if err != nil {
 		return to.ErrorResult(fmt.Errorf("delete file err: %v", err))
 	}
@@ -8,6 +8,7 @@ import (
 	"codeberg.org/goern/forgejo-mcp/v2/operation/params"
 	"codeberg.org/goern/forgejo-mcp/v2/pkg/forgejo"
 	"codeberg.org/goern/forgejo-mcp/v2/pkg/log"
+	"codeberg.org/goern/forgejo-mcp/v2/pkg/paginate"
 	"codeberg.org/goern/forgejo-mcp/v2/pkg/to"
 
 	forgejo_sdk "codeberg.org/mvdkleijn/forgejo-sdk/forgejo/v2"
@@ -25,6 +26,7 @@ var (
 	ListRepoLabelsTool = mcp.NewTool(
 		ListRepoLabelsToolName,
 		mcp.WithDescription("List all repository labels"),
+		to.WithResult[*to.Page[*forgejo_sdk.Label]](),
 		mcp.WithString("owner", mcp.Required(), mcp.Description(params.Owner)),
 		mcp.WithString("repo", mcp.Required(), mcp.Description(params.Repo)),
 		mcp.WithNumber("page", mcp.Description(params.Page), mcp.DefaultNumber(1)),
@@ -34,6 +36,7 @@ var (
 	CreateLabelTool = mcp.NewTool(
 		CreateLabelToolName,
 		mcp.WithDescription("Create a new repository label"),
+		to.WithResult[*forgejo_sdk.Label](),
 		mcp.WithString("owner", mcp.Required(), mcp.Description(params.Owner)),
 		mcp.WithString("repo", mcp.Required(), mcp.Description(params.Repo)),
 		mcp.WithString("name", mcp.Required(), mcp.Description("Label name")),
@@ -44,6 +47,7 @@ var (
 	EditLabelTool = mcp.NewTool(
 		EditLabelToolName,
 		mcp.WithDescription("Edit an existing label"),
+		to.WithResult[*forgejo_sdk.Label](),
 		mcp.WithString("owner", mcp.Required(), mcp.Description(params.Owner)),
 		mcp.WithString("repo", mcp.Required(), mcp.Description(params.Repo)),
 		mcp.WithNumber("id", mcp.Required(), mcp.Description("Label ID")),
@@ -55,6 +59,7 @@ var (
 	DeleteLabelTool = mcp.NewTool(
 		DeleteLabelToolName,
 		mcp.WithDescription("Delete a repository label"),
+		to.WithResult[string](),
 		mcp.WithString("owner", mcp.Required(), mcp.Description(params.Owner)),
 		mcp.WithString("repo", mcp.Required(), mcp.Description(params.Repo)),
 		mcp.WithNumber("id", mcp.Required(), mcp.Description("Label ID")),
@@ -77,17 +82,14 @@ func ListRepoLabelsFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallTo
 	if err != nil {
 		return to.ErrorResult(err)
 	}
-	page := req.GetFloat("page", 1)
-	limit := req.GetFloat("limit", 50)
-
-	opt := forgejo_sdk.ListLabelsOptions{
-		ListOptions: forgejo_sdk.ListOptions{
-			Page:     int(page),
-			PageSize: int(limit),
-		},
+	paging, err := paginate.FromRequest(req, 50)
+	if err != nil {
+		return to.ErrorResult(err)
 	}
 
-	labels, _, err := forgejo.Client().ListRepoLabels(owner, repo, opt)
+	labels, err := paginate.List(ctx, paging, func(lo forgejo_sdk.ListOptions) ([]*forgejo_sdk.Label, *forgejo_sdk.Response, error) {
+		return forgejo.ClientCtx(ctx).ListRepoLabels(owner, repo, forgejo_sdk.ListLabelsOptions{ListOptions: lo})
+	})
 	if err != nil {
 		return to.ErrorResult(fmt.Errorf("list repo labels err: %v", err))
 	}
@@ -116,7 +118,7 @@ func CreateLabelFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolR
 
 	// Validate color format (#RRGGBB)
 	if !isValidHexColor(color) {
-		return to.ErrorResult(fmt.Errorf("invalid color format '%s': must be #RRGGBB", color))
+		return to.ErrorResult(to.InvalidArgument("invalid color format '%s': must be #RRGGBB", color))
 	}
 
 	opt := forgejo_sdk.CreateLabelOption{
@@ -124,7 +126,7 @@ func CreateLabelFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolR
 		Color:       color,
 		Description: description,
 	}
-	label, _, err := forgejo.Client().CreateLabel(owner, repo, opt)
+	label, _, err := forgejo.ClientCtx(ctx).CreateLabel(owner, repo, opt)
 	if err != nil {
 		return to.ErrorResult(fmt.Errorf("create label err: %v", err))
 	}
@@ -151,12 +153,12 @@ func EditLabelFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolRes
 
 	// Validate at least one field is provided
 	if name == "" && color == "" && description == "" {
-		return to.ErrorResult(fmt.Errorf("at least one of name, color, or description must be provided"))
+		return to.ErrorResult(to.InvalidArgument("at least one of name, color, or description must be provided"))
 	}
 
 	// Validate color format if provided
 	if color != "" && !isValidHexColor(color) {
-		return to.ErrorResult(fmt.Errorf("invalid color format '%s': must be #RRGGBB", color))
+		return to.ErrorResult(to.InvalidArgument("invalid color format '%s': must be #RRGGBB", color))
 	}
 
 	opt := forgejo_sdk.EditLabelOption{}
@@ -172,7 +174,7 @@ func EditLabelFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolRes
 		opt.Description = &description
 	}
 
-	label, _, err := forgejo.Client().EditLabel(owner, repo, int64(id), opt)
+	label, _, err := forgejo.ClientCtx(ctx).EditLabel(owner, repo, int64(id), opt)
 	if err != nil {
 		return to.ErrorResult(fmt.Errorf("edit label err: %v", err))
 	}
@@ -194,7 +196,7 @@ func DeleteLabelFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolR
 		return to.ErrorResult(err)
 	}
 
-	_, err = forgejo.Client().DeleteLabel(owner, repo, int64(id))
+	_, err = forgejo.ClientCtx(ctx).DeleteLabel(owner, repo, int64(id))
 	if err != nil {
 		return to.ErrorResult(fmt.Errorf("delete label err: %v", err))
 	}
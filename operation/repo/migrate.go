@@ -0,0 +1,193 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"codeberg.org/goern/forgejo-mcp/v2/operation/params"
+	"codeberg.org/goern/forgejo-mcp/v2/pkg/forgejo"
+	"codeberg.org/goern/forgejo-mcp/v2/pkg/log"
+	"codeberg.org/goern/forgejo-mcp/v2/pkg/to"
+
+	forgejo_sdk "codeberg.org/mvdkleijn/forgejo-sdk/forgejo/v2"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const (
+	GenerateRepoFromTemplateToolName = "generate_repo_from_template"
+	MigrateRepoToolName              = "migrate_repo"
+	SyncMirrorToolName               = "sync_mirror"
+)
+
+var (
+	GenerateRepoFromTemplateTool = mcp.NewTool(
+		GenerateRepoFromTemplateToolName,
+		mcp.WithDescription("Create repo from a template repo"),
+		to.WithResult[*forgejo_sdk.Repository](),
+		mcp.WithString("template_owner", mcp.Required(), mcp.Description("Template repo owner")),
+		mcp.WithString("template_repo", mcp.Required(), mcp.Description("Template repo name")),
+		mcp.WithString("owner", mcp.Required(), mcp.Description("Owner/org of the new repo")),
+		mcp.WithString("name", mcp.Required(), mcp.Description("Repo name")),
+		mcp.WithString("description", mcp.Description(params.Description)),
+		mcp.WithBoolean("private", mcp.Description(params.Private)),
+		mcp.WithString("items", mcp.Description("Template items to copy (comma-separated: git_content, topics, git_hooks, webhooks, avatar, labels)"), mcp.DefaultString("git_content")),
+	)
+
+	MigrateRepoTool = mcp.NewTool(
+		MigrateRepoToolName,
+		mcp.WithDescription("Migrate repo from a Git URL or another forge"),
+		to.WithResult[*forgejo_sdk.Repository](),
+		mcp.WithString("clone_addr", mcp.Required(), mcp.Description("Clone URL of the source repo")),
+		mcp.WithString("name", mcp.Required(), mcp.Description("Repo name")),
+		mcp.WithString("owner", mcp.Description("Owner/org of the new repo (default: authenticated user)")),
+		mcp.WithString("service", mcp.Description("Source service (git|forgejo|gitea|github|gitlab|gogs)"), mcp.DefaultString("git")),
+		mcp.WithString("auth_username", mcp.Description("Source username")),
+		mcp.WithString("auth_password", mcp.Description("Source password")),
+		mcp.WithString("auth_token", mcp.Description("Source access token (required for non-git services)")),
+		mcp.WithString("description", mcp.Description(params.Description)),
+		mcp.WithBoolean("private", mcp.Description(params.Private)),
+		mcp.WithBoolean("mirror", mcp.Description("Create a pull mirror")),
+		mcp.WithString("mirror_interval", mcp.Description("Mirror sync interval (e.g. 8h0m0s)")),
+		mcp.WithBoolean("wiki", mcp.Description("Migrate wiki")),
+		mcp.WithBoolean("issues", mcp.Description("Migrate issues")),
+		mcp.WithBoolean("pull_requests", mcp.Description("Migrate pull requests")),
+		mcp.WithBoolean("labels", mcp.Description("Migrate labels")),
+		mcp.WithBoolean("milestones", mcp.Description("Migrate milestones")),
+		mcp.WithBoolean("releases", mcp.Description("Migrate releases")),
+		mcp.WithBoolean("lfs", mcp.Description("Migrate LFS objects")),
+	)
+
+	SyncMirrorTool = mcp.NewTool(
+		SyncMirrorToolName,
+		mcp.WithDescription("Trigger a pull mirror sync"),
+		to.WithResult[string](),
+		mcp.WithString("owner", mcp.Required(), mcp.Description(params.Owner)),
+		mcp.WithString("repo", mcp.Required(), mcp.Description(params.Repo)),
+	)
+)
+
+// parseTemplateItems maps a comma-separated item list onto the template option flags
+func parseTemplateItems(items string, opt *forgejo_sdk.CreateRepoFromTemplateOption) error {
+	for _, item := range strings.Split(items, ",") {
+		switch strings.TrimSpace(item) {
+		case "":
+		case "git_content":
+			opt.GitContent = true
+		case "topics":
+			opt.Topics = true
+		case "git_hooks":
+			opt.GitHooks = true
+		case "webhooks":
+			opt.Webhooks = true
+		case "avatar":
+			opt.Avatar = true
+		case "labels":
+			opt.Labels = true
+		default:
+			return to.InvalidArgument("invalid template item '%s'", strings.TrimSpace(item))
+		}
+	}
+	return nil
+}
+
+func GenerateRepoFromTemplateFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called GenerateRepoFromTemplateFn")
+	templateOwner, err := req.RequireString("template_owner")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	templateRepo, err := req.RequireString("template_repo")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	owner, err := req.RequireString("owner")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	name, err := req.RequireString("name")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+
+	opt := forgejo_sdk.CreateRepoFromTemplateOption{
+		Owner:       owner,
+		Name:        name,
+		Description: req.GetString("description", ""),
+		Private:     req.GetBool("private", false),
+	}
+	if err := parseTemplateItems(req.GetString("items", "git_content"), &opt); err != nil {
+		return to.ErrorResult(err)
+	}
+
+	repo, _, err := forgejo.ClientCtx(ctx).CreateRepoFromTemplate(templateOwner, templateRepo, opt)
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("generate repo from template err: %v", err))
+	}
+	return to.TextResult(repo)
+}
+
+func MigrateRepoFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called MigrateRepoFn")
+	cloneAddr, err := req.RequireString("clone_addr")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	name, err := req.RequireString("name")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+
+	service := forgejo_sdk.GitServiceType(req.GetString("service", "git"))
+	switch service {
+	case forgejo_sdk.GitServicePlain, forgejo_sdk.GitServiceForgejo, forgejo_sdk.GitServiceGitea,
+		forgejo_sdk.GitServiceGithub, forgejo_sdk.GitServiceGitlab, forgejo_sdk.GitServiceGogs:
+	default:
+		return to.ErrorResult(to.InvalidArgument("invalid service '%s': must be git, forgejo, gitea, github, gitlab or gogs", service))
+	}
+
+	opt := forgejo_sdk.MigrateRepoOption{
+		RepoName:       name,
+		RepoOwner:      req.GetString("owner", ""),
+		CloneAddr:      cloneAddr,
+		Service:        service,
+		AuthUsername:   req.GetString("auth_username", ""),
+		AuthPassword:   req.GetString("auth_password", ""),
+		AuthToken:      req.GetString("auth_token", ""),
+		Mirror:         req.GetBool("mirror", false),
+		Private:        req.GetBool("private", false),
+		Description:    req.GetString("description", ""),
+		Wiki:           req.GetBool("wiki", false),
+		Milestones:     req.GetBool("milestones", false),
+		Labels:         req.GetBool("labels", false),
+		Issues:         req.GetBool("issues", false),
+		PullRequests:   req.GetBool("pull_requests", false),
+		Releases:       req.GetBool("releases", false),
+		MirrorInterval: req.GetString("mirror_interval", ""),
+		LFS:            req.GetBool("lfs", false),
+	}
+
+	repo, _, err := forgejo.ClientCtx(ctx).MigrateRepo(opt)
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("migrate repo err: %v", err))
+	}
+	return to.TextResult(repo)
+}
+
+func SyncMirrorFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called SyncMirrorFn")
+	owner, err := req.RequireString("owner")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	repo, err := req.RequireString("repo")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+
+	_, err = forgejo.ClientCtx(ctx).MirrorSync(owner, repo)
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("sync mirror err: %v", err))
+	}
+	return to.TextResult("Mirror sync queued")
+}
@@ -3,10 +3,13 @@ package repo
 import (
 	"context"
 	"fmt"
+	"strconv"
+	"strings"
 
 	"codeberg.org/goern/forgejo-mcp/v2/operation/params"
 	"codeberg.org/goern/forgejo-mcp/v2/pkg/forgejo"
 	"codeberg.org/goern/forgejo-mcp/v2/pkg/log"
+	"codeberg.org/goern/forgejo-mcp/v2/pkg/paginate"
 	"codeberg.org/goern/forgejo-mcp/v2/pkg/ptr"
 	"codeberg.org/goern/forgejo-mcp/v2/pkg/to"
 	forgejo_sdk "codeberg.org/mvdkleijn/forgejo-sdk/forgejo/v2"
@@ -16,15 +19,20 @@ import (
 )
 
 const (
-	CreateRepoToolName  = "create_repo"
-	ForkRepoToolName    = "fork_repo"
-	ListMyReposToolName = "list_my_repos"
+	CreateRepoToolName   = "create_repo"
+	ForkRepoToolName     = "fork_repo"
+	ListMyReposToolName  = "list_my_repos"
+	GetRepoToolName      = "get_repo"
+	EditRepoToolName     = "edit_repo"
+	DeleteRepoToolName   = "delete_repo"
+	TransferRepoToolName = "transfer_repo"
 )
 
 var (
 	CreateRepoTool = mcp.NewTool(
 		CreateRepoToolName,
 		mcp.WithDescription("Create repo"),
+		to.WithResult[*forgejo_sdk.Repository](),
 		mcp.WithString("name", mcp.Required(), mcp.Description("Repo name")),
 		mcp.WithString("description", mcp.Description(params.Description)),
 		mcp.WithString("owner", mcp.Description("Owner/org name")),
@@ -41,6 +49,7 @@ var (
 	ForkRepoTool = mcp.NewTool(
 		ForkRepoToolName,
 		mcp.WithDescription("Fork repo"),
+		to.WithResult[string](),
 		mcp.WithString("user", mcp.Required(), mcp.Description(params.User)),
 		mcp.WithString("repo", mcp.Required(), mcp.Description(params.Repo)),
 		mcp.WithString("organization", mcp.Description("Org name")),
@@ -50,8 +59,63 @@ var (
 	ListMyReposTool = mcp.NewTool(
 		ListMyReposToolName,
 		mcp.WithDescription("List my repos"),
-		mcp.WithNumber("page", mcp.Required(), mcp.Description(params.Page), mcp.DefaultNumber(1), mcp.Min(1)),
-		mcp.WithNumber("limit", mcp.Required(), mcp.Description(params.Limit), mcp.DefaultNumber(100), mcp.Min(1)),
+		to.WithResult[*to.Page[*forgejo_sdk.Repository]](),
+		mcp.WithNumber("page", mcp.Description(params.Page), mcp.DefaultNumber(1), mcp.Min(1)),
+		mcp.WithNumber("limit", mcp.Description(params.Limit), mcp.DefaultNumber(100), mcp.Min(1)),
+	)
+
+	GetRepoTool = mcp.NewTool(
+		GetRepoToolName,
+		mcp.WithDescription("Get repo details and settings"),
+		to.WithResult[*forgejo_sdk.Repository](),
+		mcp.WithString("owner", mcp.Required(), mcp.Description(params.Owner)),
+		mcp.WithString("repo", mcp.Required(), mcp.Description(params.Repo)),
+	)
+
+	EditRepoTool = mcp.NewTool(
+		EditRepoToolName,
+		mcp.WithDescription("Edit repo settings (only provided fields change)"),
+		to.WithResult[*forgejo_sdk.Repository](),
+		mcp.WithString("owner", mcp.Required(), mcp.Description(params.Owner)),
+		mcp.WithString("repo", mcp.Required(), mcp.Description(params.Repo)),
+		mcp.WithString("name", mcp.Description("New repo name")),
+		mcp.WithString("description", mcp.Description(params.Description)),
+		mcp.WithString("website", mcp.Description("Website URL")),
+		mcp.WithBoolean("private", mcp.Description(params.Private)),
+		mcp.WithBoolean("template", mcp.Description("Template repo")),
+		mcp.WithBoolean("archived", mcp.Description("Archive (true) or unarchive (false)")),
+		mcp.WithString("default_branch", mcp.Description("Default branch")),
+		mcp.WithBoolean("has_issues", mcp.Description("Enable issues")),
+		mcp.WithBoolean("has_wiki", mcp.Description("Enable wiki")),
+		mcp.WithBoolean("has_pull_requests", mcp.Description("Enable pull requests")),
+		mcp.WithBoolean("has_projects", mcp.Description("Enable projects")),
+		mcp.WithBoolean("has_releases", mcp.Description("Enable releases")),
+		mcp.WithBoolean("has_packages", mcp.Description("Enable packages")),
+		mcp.WithBoolean("has_actions", mcp.Description("Enable Actions")),
+		mcp.WithBoolean("allow_merge_commits", mcp.Description("Allow merge commits")),
+		mcp.WithBoolean("allow_rebase", mcp.Description("Allow rebase")),
+		mcp.WithBoolean("allow_rebase_explicit", mcp.Description("Allow rebase with merge commit")),
+		mcp.WithBoolean("allow_squash_merge", mcp.Description("Allow squash merge")),
+		mcp.WithString("default_merge_style", mcp.Description("Default merge style (merge|rebase|rebase-merge|squash)")),
+	)
+
+	DeleteRepoTool = mcp.NewTool(
+		DeleteRepoToolName,
+		mcp.WithDescription("Permanently delete repo"),
+		to.WithResult[string](),
+		mcp.WithString("owner", mcp.Required(), mcp.Description(params.Owner)),
+		mcp.WithString("repo", mcp.Required(), mcp.Description(params.Repo)),
+		mcp.WithString("confirm", mcp.Required(), mcp.Description("Must equal owner/repo to confirm deletion")),
+	)
+
+	TransferRepoTool = mcp.NewTool(
+		TransferRepoToolName,
+		mcp.WithDescription("Transfer repo to another user or org"),
+		to.WithResult[*forgejo_sdk.Repository](),
+		mcp.WithString("owner", mcp.Required(), mcp.Description(params.Owner)),
+		mcp.WithString("repo", mcp.Required(), mcp.Description(params.Repo)),
+		mcp.WithString("new_owner", mcp.Required(), mcp.Description("New owner user/org name")),
+		mcp.WithString("team_ids", mcp.Description("Team IDs to grant access (comma-separated, org targets only)")),
 	)
 )
 
@@ -59,6 +123,30 @@ func RegisterTool(s *server.MCPServer) {
 	s.AddTool(CreateRepoTool, CreateRepoFn)
 	s.AddTool(ForkRepoTool, ForkRepoFn)
 	s.AddTool(ListMyReposTool, ListMyReposFn)
+	s.AddTool(GetRepoTool, GetRepoFn)
+	s.AddTool(EditRepoTool, EditRepoFn)
+	s.AddTool(DeleteRepoTool, DeleteRepoFn)
+	s.AddTool(TransferRepoTool, TransferRepoFn)
+
+	// Template, migration and mirror
+	s.AddTool(GenerateRepoFromTemplateTool, GenerateRepoFromTemplateFn)
+	s.AddTool(MigrateRepoTool, MigrateRepoFn)
+	s.AddTool(SyncMirrorTool, SyncMirrorFn)
+
+	// Topics
+	s.AddTool(GetRepoTopicsTool, GetRepoTopicsFn)
+	s.AddTool(SetRepoTopicsTool, SetRepoTopicsFn)
+
+	// Collaborators
+	s.AddTool(ListRepoCollaboratorsTool, ListRepoCollaboratorsFn)
+	s.AddTool(GetRepoCollaboratorPermissionTool, GetRepoCollaboratorPermissionFn)
+	s.AddTool(AddRepoCollaboratorTool, AddRepoCollaboratorFn)
+	s.AddTool(RemoveRepoCollaboratorTool, RemoveRepoCollaboratorFn)
+
+	// Deploy keys
+	s.AddTool(ListDeployKeysTool, ListDeployKeysFn)
+	s.AddTool(AddDeployKeyTool, AddDeployKeyFn)
+	s.AddTool(DeleteDeployKeyTool, DeleteDeployKeyFn)
 
 	// Labels
 	s.AddTool(ListRepoLabelsTool, ListRepoLabelsFn)
@@ -112,9 +200,9 @@ func CreateRepoFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolRe
 	}
 	var repo *forgejo_sdk.Repository
 	if owner != "" {
-		repo, _, err = forgejo.Client().CreateOrgRepo(owner, opt)
+		repo, _, err = forgejo.ClientCtx(ctx).CreateOrgRepo(owner, opt)
 	} else {
-		repo, _, err = forgejo.Client().CreateRepo(opt)
+		repo, _, err = forgejo.ClientCtx(ctx).CreateRepo(opt)
 	}
 	if err != nil {
 		return to.ErrorResult(fmt.Errorf("create repo err: %v", err))
@@ -146,7 +234,7 @@ func ForkRepoFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResu
 		Organization: organizationPtr,
 		Name:         namePtr,
 	}
-	_, _, err = forgejo.Client().CreateFork(user, repo, opt)
+	_, _, err = forgejo.ClientCtx(ctx).CreateFork(user, repo, opt)
 	if err != nil {
 		return to.ErrorResult(fmt.Errorf("fork repository error %v", err))
 	}
@@ -155,24 +243,169 @@ func ForkRepoFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResu
 
 func ListMyReposFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	log.Debugf("Called ListMyReposFn")
-	page, err := req.RequireFloat("page")
+	paging, err := paginate.FromRequest(req, 100)
 	if err != nil {
 		return to.ErrorResult(err)
 	}
-	limit, err := req.RequireFloat("limit")
+
+	repos, err := paginate.List(ctx, paging, func(lo forgejo_sdk.ListOptions) ([]*forgejo_sdk.Repository, *forgejo_sdk.Response, error) {
+		return forgejo.ClientCtx(ctx).ListMyRepos(forgejo_sdk.ListReposOptions{ListOptions: lo})
+	})
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("list my repositories error: %v", err))
+	}
+
+	return to.TextResult(repos)
+}
+
+// stringArg returns a pointer to the named string argument, or nil when it was not provided
+func stringArg(req mcp.CallToolRequest, name string) *string {
+	if _, ok := req.GetArguments()[name]; !ok {
+		return nil
+	}
+	return ptr.To(req.GetString(name, ""))
+}
+
+// boolArg returns a pointer to the named boolean argument, or nil when it was not provided
+func boolArg(req mcp.CallToolRequest, name string) *bool {
+	if _, ok := req.GetArguments()[name]; !ok {
+		return nil
+	}
+	return ptr.To(req.GetBool(name, false))
+}
+
+func GetRepoFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called GetRepoFn")
+	owner, err := req.RequireString("owner")
 	if err != nil {
 		return to.ErrorResult(err)
 	}
-	opt := forgejo_sdk.ListReposOptions{
-		ListOptions: forgejo_sdk.ListOptions{
-			Page:     int(page),
-			PageSize: int(limit),
-		},
+	repo, err := req.RequireString("repo")
+	if err != nil {
+		return to.ErrorResult(err)
 	}
-	repos, _, err := forgejo.Client().ListMyRepos(opt)
+
+	repository, _, err := forgejo.ClientCtx(ctx).GetRepo(owner, repo)
 	if err != nil {
-		return to.ErrorResult(fmt.Errorf("list my repositories error: %v", err))
+		return to.ErrorResult(fmt.Errorf("get repo err: %v", err))
 	}
+	return to.TextResult(repository)
+}
 
-	return to.TextResult(repos)
+func EditRepoFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called EditRepoFn")
+	owner, err := req.RequireString("owner")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	repo, err := req.RequireString("repo")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+
+	opt := forgejo_sdk.EditRepoOption{
+		Name:             stringArg(req, "name"),
+		Description:      stringArg(req, "description"),
+		Website:          stringArg(req, "website"),
+		Private:          boolArg(req, "private"),
+		Template:         boolArg(req, "template"),
+		Archived:         boolArg(req, "archived"),
+		DefaultBranch:    stringArg(req, "default_branch"),
+		HasIssues:        boolArg(req, "has_issues"),
+		HasWiki:          boolArg(req, "has_wiki"),
+		HasPullRequests:  boolArg(req, "has_pull_requests"),
+		HasProjects:      boolArg(req, "has_projects"),
+		HasReleases:      boolArg(req, "has_releases"),
+		HasPackages:      boolArg(req, "has_packages"),
+		HasActions:       boolArg(req, "has_actions"),
+		AllowMerge:       boolArg(req, "allow_merge_commits"),
+		AllowRebase:      boolArg(req, "allow_rebase"),
+		AllowRebaseMerge: boolArg(req, "allow_rebase_explicit"),
+		AllowSquash:      boolArg(req, "allow_squash_merge"),
+	}
+	if style := req.GetString("default_merge_style", ""); style != "" {
+		mergeStyle := forgejo_sdk.MergeStyle(style)
+		switch mergeStyle {
+		case forgejo_sdk.MergeStyleMerge, forgejo_sdk.MergeStyleRebase, forgejo_sdk.MergeStyleRebaseMerge, forgejo_sdk.MergeStyleSquash:
+			opt.DefaultMergeStyle = &mergeStyle
+		default:
+			return to.ErrorResult(to.InvalidArgument("invalid default_merge_style '%s': must be merge, rebase, rebase-merge or squash", style))
+		}
+	}
+
+	if ptr.AllPtrFieldsNil(opt) {
+		return to.ErrorResult(to.InvalidArgument("at least one setting must be provided"))
+	}
+
+	repository, _, err := forgejo.ClientCtx(ctx).EditRepo(owner, repo, opt)
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("edit repo err: %v", err))
+	}
+	return to.TextResult(repository)
+}
+
+func DeleteRepoFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called DeleteRepoFn")
+	owner, err := req.RequireString("owner")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	repo, err := req.RequireString("repo")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	confirm, err := req.RequireString("confirm")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+
+	fullName := owner + "/" + repo
+	if confirm != fullName {
+		return to.ErrorResult(to.InvalidArgument("confirmation mismatch: confirm must be '%s' to delete this repository", fullName))
+	}
+
+	_, err = forgejo.ClientCtx(ctx).DeleteRepo(owner, repo)
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("delete repo err: %v", err))
+	}
+	return to.TextResult(fmt.Sprintf("Repository %s deleted", fullName))
+}
+
+func TransferRepoFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called TransferRepoFn")
+	owner, err := req.RequireString("owner")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	repo, err := req.RequireString("repo")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	newOwner, err := req.RequireString("new_owner")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	teamIDs := req.GetString("team_ids", "")
+
+	opt := forgejo_sdk.TransferRepoOption{
+		NewOwner: newOwner,
+	}
+	if teamIDs != "" {
+		ids := []int64{}
+		for _, idStr := range strings.Split(teamIDs, ",") {
+			idStr = strings.TrimSpace(idStr)
+			id, err := strconv.ParseInt(idStr, 10, 64)
+			if err != nil {
+				return to.ErrorResult(to.InvalidArgument("invalid team ID '%s': %v", idStr, err))
+			}
+			ids = append(ids, id)
+		}
+		opt.TeamIDs = &ids
+	}
+
+	repository, _, err := forgejo.ClientCtx(ctx).TransferRepo(owner, repo, opt)
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("transfer repo err: %v", err))
+	}
+	return to.TextResult(repository)
 }
@@ -3,8 +3,11 @@ package repo
 import (
 	"testing"
 
+	forgejo_sdk "codeberg.org/mvdkleijn/forgejo-sdk/forgejo/v2"
+
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // TestListRepoLabelsTool verifies the tool definition is correctly configured
@@ -211,8 +214,8 @@ func TestListRepoLabelsFn_MissingOwner(t *testing.T) {
 	}
 
 	result, err := ListRepoLabelsFn(nil, req)
-	assert.Error(t, err)
-	assert.Nil(t, result)
+	assert.NoError(t, err)
+	require.True(t, result.IsError)
 }
 
 // TestListRepoLabelsFn_MissingRepo tests error handling when repo is missing
@@ -226,8 +229,8 @@ func TestListRepoLabelsFn_MissingRepo(t *testing.T) {
 	}
 
 	result, err := ListRepoLabelsFn(nil, req)
-	assert.Error(t, err)
-	assert.Nil(t, result)
+	assert.NoError(t, err)
+	require.True(t, result.IsError)
 }
 
 // TestCreateLabelFn_MissingRequiredParams tests error handling for missing required parameters
@@ -290,9 +293,9 @@ func TestCreateLabelFn_MissingRequiredParams(t *testing.T) {
 
 			result, err := CreateLabelFn(nil, req)
 			if tt.wantErr {
-				assert.Error(t, err)
-				assert.Nil(t, result)
-				assert.Contains(t, err.Error(), tt.errField)
+				assert.NoError(t, err)
+				require.True(t, result.IsError)
+				assert.Contains(t, result.Content[0].(mcp.TextContent).Text, tt.errField)
 			} else {
 				assert.NoError(t, err)
 				assert.NotNil(t, result)
@@ -356,9 +359,9 @@ func TestCreateLabelFn_InvalidColorFormat(t *testing.T) {
 
 			result, err := CreateLabelFn(nil, req)
 			if tt.wantErr {
-				assert.Error(t, err)
-				assert.Nil(t, result)
-				assert.Contains(t, err.Error(), tt.errContains)
+				assert.NoError(t, err)
+				require.True(t, result.IsError)
+				assert.Contains(t, result.Content[0].(mcp.TextContent).Text, tt.errContains)
 			} else {
 				assert.NoError(t, err)
 				assert.NotNil(t, result)
@@ -410,9 +413,9 @@ func TestEditLabelFn_InvalidColorFormat(t *testing.T) {
 
 			result, err := EditLabelFn(nil, req)
 			if tt.wantErr {
-				assert.Error(t, err)
-				assert.Nil(t, result)
-				assert.Contains(t, err.Error(), tt.errContains)
+				assert.NoError(t, err)
+				require.True(t, result.IsError)
+				assert.Contains(t, result.Content[0].(mcp.TextContent).Text, tt.errContains)
 			} else {
 				assert.NoError(t, err)
 				assert.NotNil(t, result)
@@ -434,9 +437,9 @@ func TestEditLabelFn_NoChanges(t *testing.T) {
 	}
 
 	result, err := EditLabelFn(nil, req)
-	assert.Error(t, err)
-	assert.Nil(t, result)
-	assert.Contains(t, err.Error(), "at least one of name, color, or description must be provided")
+	assert.NoError(t, err)
+	require.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "at least one of name, color, or description must be provided")
 }
 
 // TestDeleteLabelFn_MissingRequiredParams tests error handling for missing required parameters
@@ -486,9 +489,9 @@ func TestDeleteLabelFn_MissingRequiredParams(t *testing.T) {
 
 			result, err := DeleteLabelFn(nil, req)
 			if tt.wantErr {
-				assert.Error(t, err)
-				assert.Nil(t, result)
-				assert.Contains(t, err.Error(), tt.errField)
+				assert.NoError(t, err)
+				require.True(t, result.IsError)
+				assert.Contains(t, result.Content[0].(mcp.TextContent).Text, tt.errField)
 			} else {
 				assert.NoError(t, err)
 				assert.NotNil(t, result)
@@ -496,3 +499,201 @@ func TestDeleteLabelFn_MissingRequiredParams(t *testing.T) {
 		})
 	}
 }
+
+// TestDeleteRepoTool verifies the confirmation argument is required
+func TestDeleteRepoTool(t *testing.T) {
+	tool := DeleteRepoTool
+
+	assert.Equal(t, "delete_repo", tool.Name)
+	assert.Contains(t, tool.InputSchema.Required, "owner")
+	assert.Contains(t, tool.InputSchema.Required, "repo")
+	assert.Contains(t, tool.InputSchema.Required, "confirm")
+}
+
+// TestDeleteRepoFn_ConfirmationMismatch tests that deletion is refused without an exact confirmation
+func TestDeleteRepoFn_ConfirmationMismatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		confirm string
+	}{
+		{name: "repo only", confirm: "test-repo"},
+		{name: "wrong owner", confirm: "other/test-repo"},
+		{name: "different case", confirm: "Test-Owner/test-repo"},
+		{name: "yes", confirm: "yes"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := mcp.CallToolRequest{
+				Params: mcp.CallToolParams{
+					Arguments: map[string]interface{}{
+						"owner":   "test-owner",
+						"repo":    "test-repo",
+						"confirm": tt.confirm,
+					},
+				},
+			}
+
+			result, err := DeleteRepoFn(nil, req)
+			assert.NoError(t, err)
+			require.True(t, result.IsError)
+			assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "confirmation mismatch")
+		})
+	}
+}
+
+// TestEditRepoFn_InvalidArgs tests argument validation before any API call
+func TestEditRepoFn_InvalidArgs(t *testing.T) {
+	tests := []struct {
+		name        string
+		args        map[string]interface{}
+		errContains string
+	}{
+		{
+			name: "no settings",
+			args: map[string]interface{}{
+				"owner": "test-owner",
+				"repo":  "test-repo",
+			},
+			errContains: "at least one setting must be provided",
+		},
+		{
+			name: "invalid merge style",
+			args: map[string]interface{}{
+				"owner":               "test-owner",
+				"repo":                "test-repo",
+				"default_merge_style": "octopus",
+			},
+			errContains: "invalid default_merge_style",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := mcp.CallToolRequest{
+				Params: mcp.CallToolParams{
+					Arguments: tt.args,
+				},
+			}
+
+			result, err := EditRepoFn(nil, req)
+			assert.NoError(t, err)
+			require.True(t, result.IsError)
+			assert.Contains(t, result.Content[0].(mcp.TextContent).Text, tt.errContains)
+		})
+	}
+}
+
+// TestBoolArg tests that only provided boolean arguments are turned into pointers
+func TestBoolArg(t *testing.T) {
+	req := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Arguments: map[string]interface{}{
+				"archived": false,
+			},
+		},
+	}
+
+	archived := boolArg(req, "archived")
+	assert.NotNil(t, archived)
+	assert.False(t, *archived)
+	assert.Nil(t, boolArg(req, "private"))
+}
+
+// TestAddRepoCollaboratorFn_InvalidPermission tests permission validation
+func TestAddRepoCollaboratorFn_InvalidPermission(t *testing.T) {
+	for _, permission := range []string{"owner", "none", "maintain"} {
+		t.Run(permission, func(t *testing.T) {
+			req := mcp.CallToolRequest{
+				Params: mcp.CallToolParams{
+					Arguments: map[string]interface{}{
+						"owner":      "test-owner",
+						"repo":       "test-repo",
+						"user":       "alice",
+						"permission": permission,
+					},
+				},
+			}
+
+			result, err := AddRepoCollaboratorFn(nil, req)
+			assert.NoError(t, err)
+			require.True(t, result.IsError)
+			assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "invalid permission")
+		})
+	}
+}
+
+// TestGetRepoCollaboratorPermissionTool verifies the tool definition is correctly configured
+func TestGetRepoCollaboratorPermissionTool(t *testing.T) {
+	tool := GetRepoCollaboratorPermissionTool
+
+	assert.Equal(t, "get_repo_collaborator_permission", tool.Name)
+	assert.ElementsMatch(t, []string{"owner", "repo", "user"}, tool.InputSchema.Required)
+}
+
+// TestTransferRepoFn_InvalidTeamIDs tests team ID parsing
+func TestTransferRepoFn_InvalidTeamIDs(t *testing.T) {
+	req := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Arguments: map[string]interface{}{
+				"owner":     "test-owner",
+				"repo":      "test-repo",
+				"new_owner": "my-org",
+				"team_ids":  "1,abc",
+			},
+		},
+	}
+
+	result, err := TransferRepoFn(nil, req)
+	assert.NoError(t, err)
+	require.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "invalid team ID")
+}
+
+// TestParseTemplateItems tests template item selection
+func TestParseTemplateItems(t *testing.T) {
+	opt := forgejo_sdk.CreateRepoFromTemplateOption{}
+	err := parseTemplateItems("git_content, labels,topics", &opt)
+	assert.NoError(t, err)
+	assert.True(t, opt.GitContent)
+	assert.True(t, opt.Labels)
+	assert.True(t, opt.Topics)
+	assert.False(t, opt.GitHooks)
+	assert.False(t, opt.Webhooks)
+	assert.False(t, opt.Avatar)
+
+	err = parseTemplateItems("git_content,issues", &forgejo_sdk.CreateRepoFromTemplateOption{})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid template item 'issues'")
+}
+
+// TestMigrateRepoFn_InvalidService tests service validation before any API call
+func TestMigrateRepoFn_InvalidService(t *testing.T) {
+	req := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Arguments: map[string]interface{}{
+				"clone_addr": "https://example.com/project.git",
+				"name":       "project",
+				"service":    "bitbucket",
+			},
+		},
+	}
+
+	result, err := MigrateRepoFn(nil, req)
+	assert.NoError(t, err)
+	require.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "invalid service")
+}
+
+// TestAddDeployKeyTool verifies deploy keys default to read-only
+func TestAddDeployKeyTool(t *testing.T) {
+	tool := AddDeployKeyTool
+
+	assert.Equal(t, "add_deploy_key", tool.Name)
+	assert.Contains(t, tool.InputSchema.Required, "title")
+	assert.Contains(t, tool.InputSchema.Required, "key")
+
+	readOnly, ok := tool.InputSchema.Properties["read_only"].(map[string]any)
+	assert.True(t, ok)
+	assert.Equal(t, true, readOnly["default"])
+}
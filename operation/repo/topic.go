@@ -0,0 +1,87 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"codeberg.org/goern/forgejo-mcp/v2/operation/params"
+	"codeberg.org/goern/forgejo-mcp/v2/pkg/forgejo"
+	"codeberg.org/goern/forgejo-mcp/v2/pkg/log"
+	"codeberg.org/goern/forgejo-mcp/v2/pkg/to"
+
+	forgejo_sdk "codeberg.org/mvdkleijn/forgejo-sdk/forgejo/v2"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const (
+	GetRepoTopicsToolName = "get_repo_topics"
+	SetRepoTopicsToolName = "set_repo_topics"
+)
+
+var (
+	GetRepoTopicsTool = mcp.NewTool(
+		GetRepoTopicsToolName,
+		mcp.WithDescription("Get repo topics"),
+		to.WithResult[[]string](),
+		mcp.WithString("owner", mcp.Required(), mcp.Description(params.Owner)),
+		mcp.WithString("repo", mcp.Required(), mcp.Description(params.Repo)),
+	)
+
+	SetRepoTopicsTool = mcp.NewTool(
+		SetRepoTopicsToolName,
+		mcp.WithDescription("Replace all repo topics"),
+		to.WithResult[[]string](),
+		mcp.WithString("owner", mcp.Required(), mcp.Description(params.Owner)),
+		mcp.WithString("repo", mcp.Required(), mcp.Description(params.Repo)),
+		mcp.WithString("topics", mcp.Required(), mcp.Description("Topics (comma-separated, empty clears all)")),
+	)
+)
+
+func GetRepoTopicsFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called GetRepoTopicsFn")
+	owner, err := req.RequireString("owner")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	repo, err := req.RequireString("repo")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+
+	topics, _, err := forgejo.ClientCtx(ctx).ListRepoTopics(owner, repo, forgejo_sdk.ListRepoTopicsOptions{})
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("get repo topics err: %v", err))
+	}
+	return to.TextResult(topics)
+}
+
+func SetRepoTopicsFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called SetRepoTopicsFn")
+	owner, err := req.RequireString("owner")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	repo, err := req.RequireString("repo")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	topics, err := req.RequireString("topics")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+
+	list := []string{}
+	for _, topic := range strings.Split(topics, ",") {
+		topic = strings.ToLower(strings.TrimSpace(topic))
+		if topic != "" {
+			list = append(list, topic)
+		}
+	}
+
+	_, err = forgejo.ClientCtx(ctx).SetRepoTopics(owner, repo, list)
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("set repo topics err: %v", err))
+	}
+	return to.TextResult(list)
+}
@@ -0,0 +1,290 @@
+package resource
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"codeberg.org/goern/forgejo-mcp/v2/pkg/flag"
+	"codeberg.org/goern/forgejo-mcp/v2/pkg/forgejo"
+	"codeberg.org/goern/forgejo-mcp/v2/pkg/log"
+	"codeberg.org/goern/forgejo-mcp/v2/pkg/paginate"
+	"codeberg.org/goern/forgejo-mcp/v2/pkg/to"
+
+	forgejo_sdk "codeberg.org/mvdkleijn/forgejo-sdk/forgejo/v2"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const (
+	RepoURITemplate   = "forgejo://{owner}/{repo}"
+	FileURITemplate   = "forgejo://{owner}/{repo}/file/{ref}/{+path}"
+	BranchURITemplate = "forgejo://{owner}/{repo}/branches/{branch}"
+	IssueURITemplate  = "forgejo://{owner}/{repo}/issues/{index}"
+	PullURITemplate   = "forgejo://{owner}/{repo}/pulls/{index}"
+	WikiURITemplate   = "forgejo://{owner}/{repo}/wiki/{page}"
+
+	mimeJSON     = "application/json"
+	mimeMarkdown = "text/markdown"
+
+	// listLimit is how many repositories resources/list returns
+	listLimit = 50
+	// maxFileSize bounds files returned as resources
+	maxFileSize = 10 << 20
+	// reviewPageSize is the page size used to read all reviews of a pull
+	// request, up to the item cap
+	reviewPageSize = 50
+)
+
+var (
+	RepoTemplate = mcp.NewResourceTemplate(
+		RepoURITemplate,
+		"Repository",
+		mcp.WithTemplateDescription("Repository metadata"),
+		mcp.WithTemplateMIMEType(mimeJSON),
+	)
+
+	FileTemplate = mcp.NewResourceTemplate(
+		FileURITemplate,
+		"Repository file",
+		mcp.WithTemplateDescription("File content at a branch, tag or commit; escape slashes in ref as %2F"),
+	)
+
+	BranchTemplate = mcp.NewResourceTemplate(
+		BranchURITemplate,
+		"Branch",
+		mcp.WithTemplateDescription("Branch head commit with its combined CI status; escape slashes in branch as %2F"),
+		mcp.WithTemplateMIMEType(mimeJSON),
+	)
+
+	IssueTemplate = mcp.NewResourceTemplate(
+		IssueURITemplate,
+		"Issue",
+		mcp.WithTemplateDescription("Issue with its comments"),
+		mcp.WithTemplateMIMEType(mimeJSON),
+	)
+
+	PullTemplate = mcp.NewResourceTemplate(
+		PullURITemplate,
+		"Pull request",
+		mcp.WithTemplateDescription("Pull request with its comments, reviews and CI status"),
+		mcp.WithTemplateMIMEType(mimeJSON),
+	)
+
+	WikiTemplate = mcp.NewResourceTemplate(
+		WikiURITemplate,
+		"Wiki page",
+		mcp.WithTemplateDescription("Wiki page content"),
+		mcp.WithTemplateMIMEType(mimeMarkdown),
+	)
+)
+
+// Thread is the content of issue and pull request resources. Pull requests
+// add their reviews, truncated at the item cap, and the combined CI status
+// of their head commit.
+type Thread struct {
+	Issue            any                         `json:"issue,omitempty"`
+	PullRequest      any                         `json:"pull_request,omitempty"`
+	Comments         []*forgejo_sdk.Comment      `json:"comments"`
+	Reviews          []*forgejo_sdk.PullReview   `json:"reviews,omitempty"`
+	ReviewsTruncated bool                        `json:"reviews_truncated,omitempty"`
+	Status           *forgejo_sdk.CombinedStatus `json:"status,omitempty"`
+}
+
+// BranchState is the content of branch resources
+type BranchState struct {
+	Branch *forgejo_sdk.Branch         `json:"branch"`
+	Status *forgejo_sdk.CombinedStatus `json:"status"`
+}
+
+// wikiPage holds the fields of Forgejo's wiki page answer that are read
+type wikiPage struct {
+	Title         string `json:"title"`
+	ContentBase64 string `json:"content_base64"`
+}
+
+func RegisterResource(s *server.MCPServer) {
+	s.AddResourceTemplate(RepoTemplate, RepoFn)
+	s.AddResourceTemplate(FileTemplate, FileFn)
+	s.AddResourceTemplate(BranchTemplate, BranchFn)
+	s.AddResourceTemplate(IssueTemplate, IssueFn)
+	s.AddResourceTemplate(PullTemplate, PullFn)
+	s.AddResourceTemplate(WikiTemplate, WikiFn)
+}
+
+// RepoURI returns the resource URI of a repository
+func RepoURI(owner, repo string) string {
+	return "forgejo://" + url.PathEscape(owner) + "/" + url.PathEscape(repo)
+}
+
+// ListResources is the after-list hook that adds the repositories of the
+// authenticated user to the first page of resources/list
+func ListResources(ctx context.Context, id any, req *mcp.ListResourcesRequest, result *mcp.ListResourcesResult) {
+	if req.Params.Cursor != "" {
+		return
+	}
+	repos, _, err := forgejo.ClientCtx(ctx).ListMyRepos(forgejo_sdk.ListReposOptions{
+		ListOptions: forgejo_sdk.ListOptions{Page: 1, PageSize: listLimit},
+	})
+	if err != nil {
+		log.Error("List repository resources failed", log.ErrorField(err))
+		return
+	}
+	for _, r := range repos {
+		if r.Owner == nil {
+			continue
+		}
+		result.Resources = append(result.Resources, mcp.NewResource(
+			RepoURI(r.Owner.UserName, r.Name),
+			r.FullName,
+			mcp.WithResourceDescription(r.Description),
+			mcp.WithMIMEType(mimeJSON),
+		))
+	}
+}
+
+func RepoFn(ctx context.Context, req mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	log.Debugf("Called RepoFn")
+	owner, repo := argument(req, "owner"), argument(req, "repo")
+	r, _, err := forgejo.ClientCtx(ctx).GetRepo(owner, repo)
+	if err != nil {
+		return nil, fmt.Errorf("get %v/%v err: %v", owner, repo, err)
+	}
+	return to.JSONResource(req.Params.URI, r)
+}
+
+func FileFn(ctx context.Context, req mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	log.Debugf("Called FileFn")
+	owner, repo := argument(req, "owner"), argument(req, "repo")
+	ref, filePath := argument(req, "ref"), argument(req, "path")
+	rawURL := fmt.Sprintf("%s/api/v1/repos/%s/%s/raw/%s?ref=%s", strings.TrimSuffix(flag.URL, "/"),
+		url.PathEscape(owner), url.PathEscape(repo), escapePath(filePath), url.QueryEscape(ref))
+	data, _, err := forgejo.Download(ctx, rawURL, maxFileSize)
+	if err != nil {
+		return nil, fmt.Errorf("get file %v err: %v", filePath, err)
+	}
+
+	mimeType := to.MIMEType(filePath, "", data)
+	if to.IsText(mimeType, data) {
+		return []mcp.ResourceContents{mcp.TextResourceContents{
+			URI:      req.Params.URI,
+			MIMEType: mimeType,
+			Text:     string(data),
+		}}, nil
+	}
+	return []mcp.ResourceContents{mcp.BlobResourceContents{
+		URI:      req.Params.URI,
+		MIMEType: mimeType,
+		Blob:     base64.StdEncoding.EncodeToString(data),
+	}}, nil
+}
+
+func BranchFn(ctx context.Context, req mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	log.Debugf("Called BranchFn")
+	owner, repo, branch := argument(req, "owner"), argument(req, "repo"), argument(req, "branch")
+	client := forgejo.ClientCtx(ctx)
+	b, _, err := client.GetRepoBranch(owner, repo, branch)
+	if err != nil {
+		return nil, fmt.Errorf("get %v/%v branch %v err: %v", owner, repo, branch, err)
+	}
+	status, _, err := client.GetCombinedStatus(owner, repo, branch)
+	if err != nil {
+		return nil, fmt.Errorf("get %v/%v branch %v status err: %v", owner, repo, branch, err)
+	}
+	return to.JSONResource(req.Params.URI, &BranchState{Branch: b, Status: status})
+}
+
+func IssueFn(ctx context.Context, req mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	log.Debugf("Called IssueFn")
+	owner, repo := argument(req, "owner"), argument(req, "repo")
+	index, err := indexArgument(req)
+	if err != nil {
+		return nil, err
+	}
+	client := forgejo.ClientCtx(ctx)
+	issue, _, err := client.GetIssue(owner, repo, index)
+	if err != nil {
+		return nil, fmt.Errorf("get %v/%v/issue/%v err: %v", owner, repo, index, err)
+	}
+	comments, _, err := client.ListIssueComments(owner, repo, index, forgejo_sdk.ListIssueCommentOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("list %v/%v/issue/%v comments err: %v", owner, repo, index, err)
+	}
+	return to.JSONResource(req.Params.URI, &Thread{Issue: to.Compact(issue), Comments: comments})
+}
+
+func PullFn(ctx context.Context, req mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	log.Debugf("Called PullFn")
+	owner, repo := argument(req, "owner"), argument(req, "repo")
+	index, err := indexArgument(req)
+	if err != nil {
+		return nil, err
+	}
+	client := forgejo.ClientCtx(ctx)
+	pr, _, err := client.GetPullRequest(owner, repo, index)
+	if err != nil {
+		return nil, fmt.Errorf("get %v/%v/pr/%v err: %v", owner, repo, index, err)
+	}
+	comments, _, err := client.ListIssueComments(owner, repo, index, forgejo_sdk.ListIssueCommentOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("list %v/%v/pr/%v comments err: %v", owner, repo, index, err)
+	}
+	reviews, err := paginate.List(ctx, paginate.Options{Page: 1, Limit: reviewPageSize, All: true}, func(opt forgejo_sdk.ListOptions) ([]*forgejo_sdk.PullReview, *forgejo_sdk.Response, error) {
+		return client.ListPullReviews(owner, repo, index, forgejo_sdk.ListPullReviewsOptions{ListOptions: opt})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list %v/%v/pr/%v reviews err: %v", owner, repo, index, err)
+	}
+	thread := &Thread{PullRequest: to.Compact(pr), Comments: comments, Reviews: reviews.Items, ReviewsTruncated: reviews.HasMore}
+	if pr.Head != nil && pr.Head.Sha != "" {
+		thread.Status, _, err = client.GetCombinedStatus(owner, repo, pr.Head.Sha)
+		if err != nil {
+			return nil, fmt.Errorf("get %v/%v/pr/%v status err: %v", owner, repo, index, err)
+		}
+	}
+	return to.JSONResource(req.Params.URI, thread)
+}
+
+func WikiFn(ctx context.Context, req mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	log.Debugf("Called WikiFn")
+	owner, repo, page := argument(req, "owner"), argument(req, "repo"), argument(req, "page")
+	var wiki wikiPage
+	path := fmt.Sprintf("/repos/%s/%s/wiki/page/%s", url.PathEscape(owner), url.PathEscape(repo), url.PathEscape(page))
+	if _, err := forgejo.Do(ctx, http.MethodGet, path, nil, nil, &wiki); err != nil {
+		return nil, fmt.Errorf("get wiki page %v err: %v", page, err)
+	}
+	content, err := base64.StdEncoding.DecodeString(wiki.ContentBase64)
+	if err != nil {
+		return nil, fmt.Errorf("decode wiki page %v err: %v", page, err)
+	}
+	return []mcp.ResourceContents{mcp.TextResourceContents{
+		URI:      req.Params.URI,
+		MIMEType: mimeMarkdown,
+		Text:     string(content),
+	}}, nil
+}
+
+// argument returns a variable of the matched URI template
+func argument(req mcp.ReadResourceRequest, name string) string {
+	switch v := req.Params.Arguments[name].(type) {
+	case string:
+		return v
+	case []string:
+		if len(v) > 0 {
+			return v[0]
+		}
+	}
+	return ""
+}
+
+func indexArgument(req mcp.ReadResourceRequest) (int64, error) {
+	index, err := strconv.ParseInt(argument(req, "index"), 10, 64)
+	if err != nil || index < 1 {
+		return 0, fmt.Errorf("invalid index in %v: must be a positive number", req.Params.URI)
+	}
+	return index, nil
+}
@@ -0,0 +1,151 @@
+package resource
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"codeberg.org/goern/forgejo-mcp/v2/pkg/flag"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestTemplates tests that resource URIs match their template variables
+func TestTemplates(t *testing.T) {
+	tests := []struct {
+		template mcp.ResourceTemplate
+		uri      string
+		want     map[string]string
+	}{
+		{RepoTemplate, "forgejo://acme/website", map[string]string{"owner": "acme", "repo": "website"}},
+		{FileTemplate, "forgejo://acme/website/file/main/docs/setup.md", map[string]string{"ref": "main", "path": "docs/setup.md"}},
+		{FileTemplate, "forgejo://acme/website/file/feature%2Fdark/README.md", map[string]string{"ref": "feature/dark", "path": "README.md"}},
+		{BranchTemplate, "forgejo://acme/website/branches/release%2F1.0", map[string]string{"branch": "release/1.0"}},
+		{IssueTemplate, "forgejo://acme/website/issues/42", map[string]string{"index": "42"}},
+		{PullTemplate, "forgejo://acme/website/pulls/7", map[string]string{"index": "7"}},
+		{WikiTemplate, "forgejo://acme/website/wiki/Getting%20Started", map[string]string{"page": "Getting Started"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.uri, func(t *testing.T) {
+			values := tt.template.URITemplate.Match(tt.uri)
+			require.NotNil(t, values)
+			for name, want := range tt.want {
+				assert.Equal(t, want, values.Get(name).String(), name)
+			}
+		})
+	}
+
+	assert.Nil(t, RepoTemplate.URITemplate.Match("forgejo://acme/website/issues/42"))
+	assert.Equal(t, "forgejo://acme/my%20site", RepoURI("acme", "my site"))
+}
+
+// TestIndexArgument tests index validation before any API call
+func TestIndexArgument(t *testing.T) {
+	req := mcp.ReadResourceRequest{}
+	req.Params.URI = "forgejo://acme/website/issues/42"
+	req.Params.Arguments = map[string]any{"index": []string{"42"}}
+	index, err := indexArgument(req)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(42), index)
+
+	req.Params.Arguments = map[string]any{"index": []string{"latest"}}
+	_, err = IssueFn(nil, req)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid index")
+}
+
+// TestFileFn reads a file through the raw endpoint and refuses files past
+// maxFileSize without reading them whole
+func TestFileFn(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/repos/acme/website/raw/docs/setup.md":
+			assert.Equal(t, "feature/dark", r.URL.Query().Get("ref"))
+			io.WriteString(w, "# Setup\n")
+		case "/api/v1/repos/acme/website/raw/big.bin":
+			chunk := make([]byte, 1<<20)
+			for i := 0; i < 2*maxFileSize>>20; i++ {
+				if _, err := w.Write(chunk); err != nil {
+					return
+				}
+			}
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	defer func(url string) { flag.URL = url }(flag.URL)
+	flag.URL = srv.URL
+
+	req := mcp.ReadResourceRequest{}
+	req.Params.URI = "forgejo://acme/website/file/feature%2Fdark/docs/setup.md"
+	req.Params.Arguments = map[string]any{"owner": "acme", "repo": "website", "ref": "feature/dark", "path": "docs/setup.md"}
+	contents, err := FileFn(context.Background(), req)
+	require.NoError(t, err)
+	text := contents[0].(mcp.TextResourceContents)
+	assert.Equal(t, "text/markdown", text.MIMEType)
+	assert.Equal(t, "# Setup\n", text.Text)
+
+	req.Params.Arguments = map[string]any{"owner": "acme", "repo": "website", "ref": "main", "path": "big.bin"}
+	_, err = FileFn(context.Background(), req)
+	assert.ErrorContains(t, err, "exceeds")
+}
+
+// TestPullFn follows every page of reviews and marks them truncated at the
+// item cap
+func TestPullFn(t *testing.T) {
+	defer func(maxItems int) { flag.MaxItems = maxItems }(flag.MaxItems)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/version":
+			io.WriteString(w, `{"version":"11.0.0"}`)
+		case "/api/v1/repos/acme/website/pulls/7":
+			io.WriteString(w, `{"number":7}`)
+		case "/api/v1/repos/acme/website/issues/7/comments":
+			io.WriteString(w, `[]`)
+		case "/api/v1/repos/acme/website/pulls/7/reviews":
+			page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+			limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+			w.Header().Set("X-Total-Count", "120")
+			var ids []string
+			for id := (page-1)*limit + 1; id <= page*limit && id <= 120; id++ {
+				ids = append(ids, fmt.Sprintf(`{"id":%d}`, id))
+			}
+			io.WriteString(w, "["+strings.Join(ids, ",")+"]")
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	defer func(url string) { flag.URL = url }(flag.URL)
+	flag.URL = srv.URL
+
+	read := func() Thread {
+		req := mcp.ReadResourceRequest{}
+		req.Params.URI = "forgejo://acme/website/pulls/7"
+		req.Params.Arguments = map[string]any{"owner": "acme", "repo": "website", "index": "7"}
+		contents, err := PullFn(context.Background(), req)
+		require.NoError(t, err)
+		var thread Thread
+		require.NoError(t, json.Unmarshal([]byte(contents[0].(mcp.TextResourceContents).Text), &thread))
+		return thread
+	}
+
+	thread := read()
+	assert.Len(t, thread.Reviews, 120)
+	assert.False(t, thread.ReviewsTruncated)
+
+	flag.MaxItems = 100
+	thread = read()
+	assert.Len(t, thread.Reviews, 100)
+	assert.True(t, thread.ReviewsTruncated)
+}
@@ -0,0 +1,361 @@
+package resource
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"codeberg.org/goern/forgejo-mcp/v2/pkg/forgejo"
+	"codeberg.org/goern/forgejo-mcp/v2/pkg/log"
+	"codeberg.org/goern/forgejo-mcp/v2/pkg/paginate"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// DefaultPollInterval is how often subscribed resources are checked when no
+// --poll-interval is configured
+const DefaultPollInterval = time.Minute
+
+const (
+	// watchPageSize is the page size of paged endpoints; Forgejo may answer
+	// with smaller pages
+	watchPageSize = 50
+	// maxWatchPages bounds the pages of one endpoint polled per check
+	maxWatchPages = 20
+)
+
+// Notifier tells a session that the resource uri changed
+type Notifier func(session, uri string) error
+
+// target is a Forgejo endpoint whose answer is part of a resource
+type target struct {
+	path  string
+	query url.Values
+	// pull marks the pull request whose head commit status is followed
+	pull bool
+	// paged marks a list endpoint whose pages are all followed, since
+	// Forgejo answers only the first one without paging arguments
+	paged bool
+}
+
+// watch is a subscribed resource and what its endpoints last answered
+type watch struct {
+	uri      string
+	targets  []target
+	sessions map[string]struct{}
+
+	etags   map[string]string
+	digests map[string][sha256.Size]byte
+	// empty marks the pages of paged endpoints that held no items
+	empty map[string]bool
+	// status follows the head commit of a pull request
+	status *target
+	primed bool
+}
+
+// Subscriptions polls the resources that sessions subscribed to and
+// notifies them when the content changes. Endpoints are fetched with the
+// ETag of their previous answer, so unchanged ones cost a 304.
+type Subscriptions struct {
+	notify   Notifier
+	interval time.Duration
+
+	mu      sync.Mutex
+	watches map[string]*watch
+	running bool
+	wake    chan struct{}
+}
+
+func NewSubscriptions(interval time.Duration, notify Notifier) *Subscriptions {
+	if interval <= 0 {
+		interval = DefaultPollInterval
+	}
+	return &Subscriptions{
+		notify:   notify,
+		interval: interval,
+		watches:  map[string]*watch{},
+		wake:     make(chan struct{}, 1),
+	}
+}
+
+// Subscribe starts watching uri for session
+func (s *Subscriptions) Subscribe(session, uri string) error {
+	targets, err := watchTargets(uri)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	w, ok := s.watches[uri]
+	if !ok {
+		w = &watch{
+			uri:      uri,
+			targets:  targets,
+			sessions: map[string]struct{}{},
+			etags:    map[string]string{},
+			digests:  map[string][sha256.Size]byte{},
+			empty:    map[string]bool{},
+		}
+		s.watches[uri] = w
+	}
+	w.sessions[session] = struct{}{}
+	log.Debug("Resource subscribed",
+		log.StringField("uri", uri),
+		log.StringField("session", session),
+	)
+
+	if !s.running {
+		s.running = true
+		go s.run()
+	}
+	// Take the first snapshot now rather than at the next interval
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// Unsubscribe stops watching uri for session
+func (s *Subscriptions) Unsubscribe(session, uri string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.drop(session, uri)
+}
+
+// DropSession stops all watches of a session that went away
+func (s *Subscriptions) DropSession(session string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for uri := range s.watches {
+		s.drop(session, uri)
+	}
+}
+
+// drop removes session from the watch of uri; the caller holds s.mu
+func (s *Subscriptions) drop(session, uri string) {
+	w, ok := s.watches[uri]
+	if !ok {
+		return
+	}
+	delete(w.sessions, session)
+	if len(w.sessions) == 0 {
+		delete(s.watches, uri)
+	}
+}
+
+// run checks the watches every interval, and new ones when woken, until no
+// watch is left
+func (s *Subscriptions) run() {
+	timer := time.NewTimer(s.interval)
+	defer timer.Stop()
+	for {
+		primedOnly := false
+		select {
+		case <-timer.C:
+			timer.Reset(s.interval)
+		case <-s.wake:
+			primedOnly = true
+		}
+
+		watches := s.snapshot()
+		if watches == nil {
+			return
+		}
+		for _, w := range watches {
+			if primedOnly && w.primed {
+				continue
+			}
+			s.check(w)
+		}
+	}
+}
+
+// snapshot returns the current watches, or nil after marking the poller
+// stopped when there are none
+func (s *Subscriptions) snapshot() []*watch {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.watches) == 0 {
+		s.running = false
+		return nil
+	}
+	watches := make([]*watch, 0, len(s.watches))
+	for _, w := range s.watches {
+		watches = append(watches, w)
+	}
+	return watches
+}
+
+// check polls the endpoints of w and notifies its sessions of changes.
+// The first check of a watch only records the current state.
+func (s *Subscriptions) check(w *watch) {
+	ctx := context.Background()
+	changed := false
+	for _, t := range w.targets {
+		if t.paged {
+			changed = s.pollPages(ctx, w, t) || changed
+			continue
+		}
+		changed = s.poll(ctx, w, t) || changed
+	}
+	if w.status != nil {
+		changed = s.poll(ctx, w, *w.status) || changed
+	}
+	if !w.primed {
+		w.primed = true
+		return
+	}
+	if !changed {
+		return
+	}
+
+	s.mu.Lock()
+	sessions := make([]string, 0, len(w.sessions))
+	for session := range w.sessions {
+		sessions = append(sessions, session)
+	}
+	s.mu.Unlock()
+	for _, session := range sessions {
+		if err := s.notify(session, w.uri); err != nil {
+			log.Debug("Dropping subscription of closed session",
+				log.StringField("session", session),
+				log.ErrorField(err),
+			)
+			s.Unsubscribe(session, w.uri)
+		}
+	}
+	log.Debug("Resource updated",
+		log.StringField("uri", w.uri),
+		log.IntField("sessions", len(sessions)),
+	)
+}
+
+// pollPages polls the pages of t up to the first empty or failing one and
+// reports whether any of them changed. An empty page is how the end of the list is
+// told apart from a page that Forgejo capped below watchPageSize.
+func (s *Subscriptions) pollPages(ctx context.Context, w *watch, t target) bool {
+	changed := false
+	for page := 1; page <= maxWatchPages; page++ {
+		pt := target{path: t.path, query: paginate.Query(t.query, page, watchPageSize)}
+		changed = s.poll(ctx, w, pt) || changed
+		if _, ok := w.etags[pt.key()]; !ok || w.empty[pt.key()] {
+			break
+		}
+	}
+	return changed
+}
+
+// poll fetches t and reports whether its answer differs from the previous
+// one
+func (s *Subscriptions) poll(ctx context.Context, w *watch, t target) bool {
+	key := t.key()
+	data, etag, modified, err := forgejo.Poll(ctx, t.path, t.query, w.etags[key])
+	if err != nil {
+		log.Warn("Polling subscribed resource failed",
+			log.StringField("uri", w.uri),
+			log.ErrorField(err),
+		)
+		return false
+	}
+	if !modified {
+		return false
+	}
+	w.etags[key] = etag
+	if t.query.Has("page") {
+		var items []json.RawMessage
+		w.empty[key] = json.Unmarshal(data, &items) != nil || len(items) == 0
+	}
+
+	if t.pull {
+		w.status = headStatus(t.path, data)
+	}
+	digest := sha256.Sum256(data)
+	previous, seen := w.digests[key]
+	w.digests[key] = digest
+	return seen && previous != digest
+}
+
+// headStatus returns the combined status endpoint of the head commit of a
+// pull request answer
+func headStatus(pullPath string, data []byte) *target {
+	var pr struct {
+		Head struct {
+			Sha string `json:"sha"`
+		} `json:"head"`
+	}
+	if json.Unmarshal(data, &pr) != nil || pr.Head.Sha == "" {
+		return nil
+	}
+	repoPath := pullPath[:strings.Index(pullPath, "/pulls/")]
+	return &target{path: repoPath + "/commits/" + pr.Head.Sha + "/status"}
+}
+
+// watchTargets returns the endpoints that make up a resource
+func watchTargets(uri string) ([]target, error) {
+	for _, tpl := range []mcp.ResourceTemplate{RepoTemplate, FileTemplate, BranchTemplate, IssueTemplate, PullTemplate, WikiTemplate} {
+		values := tpl.URITemplate.Match(uri)
+		if values == nil {
+			continue
+		}
+		repo := "/repos/" + url.PathEscape(values.Get("owner").String()) + "/" + url.PathEscape(values.Get("repo").String())
+		switch tpl.URITemplate.Raw() {
+		case RepoURITemplate:
+			return []target{{path: repo}}, nil
+		case FileURITemplate:
+			return []target{{
+				path:  repo + "/contents/" + escapePath(values.Get("path").String()),
+				query: url.Values{"ref": {values.Get("ref").String()}},
+			}}, nil
+		case BranchURITemplate:
+			branch := url.PathEscape(values.Get("branch").String())
+			return []target{
+				{path: repo + "/branches/" + branch},
+				{path: repo + "/commits/" + branch + "/status"},
+			}, nil
+		case IssueURITemplate:
+			index := values.Get("index").String()
+			if n, err := strconv.ParseInt(index, 10, 64); err != nil || n < 1 {
+				return nil, fmt.Errorf("invalid index in %v: must be a positive number", uri)
+			}
+			return []target{
+				{path: repo + "/issues/" + index},
+				{path: repo + "/issues/" + index + "/comments"},
+			}, nil
+		case PullURITemplate:
+			index := values.Get("index").String()
+			if n, err := strconv.ParseInt(index, 10, 64); err != nil || n < 1 {
+				return nil, fmt.Errorf("invalid index in %v: must be a positive number", uri)
+			}
+			return []target{
+				{path: repo + "/pulls/" + index, pull: true},
+				{path: repo + "/pulls/" + index + "/reviews", paged: true},
+				{path: repo + "/issues/" + index + "/comments"},
+			}, nil
+		case WikiURITemplate:
+			return []target{{path: repo + "/wiki/page/" + url.PathEscape(values.Get("page").String())}}, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown resource %v: must match one of the forgejo:// resource templates", uri)
+}
+
+// key identifies the answer of t in the ETags and digests of a watch
+func (t target) key() string {
+	return t.path + "?" + t.query.Encode()
+}
+
+// escapePath escapes each segment of a file path
+func escapePath(p string) string {
+	segments := strings.Split(p, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
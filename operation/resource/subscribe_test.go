@@ -0,0 +1,159 @@
+package resource
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"codeberg.org/goern/forgejo-mcp/v2/pkg/flag"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestWatchTargets tests the endpoints polled for each kind of resource
+func TestWatchTargets(t *testing.T) {
+	tests := map[string][]string{
+		"forgejo://acme/website":                         {"/repos/acme/website"},
+		"forgejo://acme/website/file/v1.0/docs/a%20b.md": {"/repos/acme/website/contents/docs/a%20b.md"},
+		"forgejo://acme/website/branches/release%2F1.0":  {"/repos/acme/website/branches/release%2F1.0", "/repos/acme/website/commits/release%2F1.0/status"},
+		"forgejo://acme/website/issues/42":               {"/repos/acme/website/issues/42", "/repos/acme/website/issues/42/comments"},
+		"forgejo://acme/website/pulls/7":                 {"/repos/acme/website/pulls/7", "/repos/acme/website/pulls/7/reviews", "/repos/acme/website/issues/7/comments"},
+		"forgejo://acme/website/wiki/Home":               {"/repos/acme/website/wiki/page/Home"},
+	}
+	for uri, want := range tests {
+		targets, err := watchTargets(uri)
+		require.NoError(t, err, uri)
+		var paths []string
+		for _, target := range targets {
+			paths = append(paths, target.path)
+		}
+		assert.Equal(t, want, paths, uri)
+	}
+
+	targets, _ := watchTargets("forgejo://acme/website/file/v1.0/docs/a%20b.md")
+	assert.Equal(t, "v1.0", targets[0].query.Get("ref"))
+
+	// Only the pull request itself leads to the head commit status
+	targets, _ = watchTargets("forgejo://acme/website/pulls/7")
+	assert.Equal(t, []bool{true, false, false}, []bool{targets[0].pull, targets[1].pull, targets[2].pull})
+	// and only its reviews are paged
+	assert.Equal(t, []bool{false, true, false}, []bool{targets[0].paged, targets[1].paged, targets[2].paged})
+
+	for _, uri := range []string{"https://example.com", "forgejo://acme/website/issues/latest"} {
+		_, err := watchTargets(uri)
+		assert.Error(t, err, uri)
+	}
+}
+
+// TestHeadStatus tests that pull requests follow their head commit status
+func TestHeadStatus(t *testing.T) {
+	status := headStatus("/repos/acme/website/pulls/7", []byte(`{"head":{"sha":"abc123"}}`))
+	require.NotNil(t, status)
+	assert.Equal(t, "/repos/acme/website/commits/abc123/status", status.path)
+	assert.Nil(t, headStatus("/repos/acme/website/pulls/7", []byte(`{}`)))
+}
+
+// TestSubscriptions polls a subscribed issue with ETags and notifies once
+// a comment is added, and notifies about a pull request once the CI status
+// of its head commit changes or a review lands past the first page
+func TestSubscriptions(t *testing.T) {
+	var comments, reviews, notModified, statusPolls atomic.Int32
+	var ciState atomic.Value
+	ciState.Store("pending")
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body string
+		switch r.URL.Path {
+		case "/api/v1/repos/acme/website/issues/42":
+			body = `{"number":42,"title":"Broken link"}`
+		case "/api/v1/repos/acme/website/issues/42/comments":
+			body = fmt.Sprintf(`[%d]`, comments.Load())
+		case "/api/v1/repos/acme/website/pulls/7":
+			body = `{"number":7,"head":{"sha":"abc123"}}`
+		case "/api/v1/repos/acme/website/pulls/7/reviews":
+			// Pages of at most 30 reviews, whatever the limit asked for
+			page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+			var ids []string
+			for id := (page-1)*30 + 1; id <= page*30 && id <= int(reviews.Load()); id++ {
+				ids = append(ids, strconv.Itoa(id))
+			}
+			body = "[" + strings.Join(ids, ",") + "]"
+		case "/api/v1/repos/acme/website/issues/7/comments":
+			body = `[]`
+		case "/api/v1/repos/acme/website/commits/abc123/status":
+			statusPolls.Add(1)
+			body = fmt.Sprintf(`{"state":%q}`, ciState.Load())
+		default:
+			http.NotFound(w, r)
+			return
+		}
+		etag := fmt.Sprintf(`"%x"`, sha256.Sum256([]byte(body)))
+		if r.Header.Get("If-None-Match") == etag {
+			notModified.Add(1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		io.WriteString(w, body)
+	}))
+	defer srv.Close()
+	defer func(url string) { flag.URL = url }(flag.URL)
+	flag.URL = srv.URL
+
+	notified := make(chan string, 10)
+	subs := NewSubscriptions(20*time.Millisecond, func(session, uri string) error {
+		notified <- session + " " + uri
+		return nil
+	})
+	require.NoError(t, subs.Subscribe("s1", "forgejo://acme/website/issues/42"))
+
+	// Unchanged resources are revalidated without a notification
+	require.Eventually(t, func() bool { return notModified.Load() >= 2 }, time.Second, 5*time.Millisecond)
+	assert.Empty(t, notified)
+
+	comments.Add(1)
+	select {
+	case n := <-notified:
+		assert.Equal(t, "s1 forgejo://acme/website/issues/42", n)
+	case <-time.After(time.Second):
+		t.Fatal("no notification after the issue changed")
+	}
+
+	subs.Unsubscribe("s1", "forgejo://acme/website/issues/42")
+
+	// The reviews answer must not stop the head commit status from being followed
+	reviews.Store(30)
+	require.NoError(t, subs.Subscribe("s2", "forgejo://acme/website/pulls/7"))
+	require.Eventually(t, func() bool { return statusPolls.Load() >= 2 }, time.Second, 5*time.Millisecond)
+	assert.Empty(t, notified)
+
+	ciState.Store("success")
+	select {
+	case n := <-notified:
+		assert.Equal(t, "s2 forgejo://acme/website/pulls/7", n)
+	case <-time.After(time.Second):
+		t.Fatal("no notification after the CI status changed")
+	}
+
+	reviews.Add(1)
+	select {
+	case n := <-notified:
+		assert.Equal(t, "s2 forgejo://acme/website/pulls/7", n)
+	case <-time.After(time.Second):
+		t.Fatal("no notification after a review was added on the second page")
+	}
+
+	subs.Unsubscribe("s2", "forgejo://acme/website/pulls/7")
+	assert.Eventually(t, func() bool {
+		subs.mu.Lock()
+		defer subs.mu.Unlock()
+		return !subs.running
+	}, time.Second, 5*time.Millisecond)
+}
@@ -0,0 +1,252 @@
+package search
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+
+	"codeberg.org/goern/forgejo-mcp/v2/operation/params"
+	"codeberg.org/goern/forgejo-mcp/v2/pkg/forgejo"
+	"codeberg.org/goern/forgejo-mcp/v2/pkg/log"
+	"codeberg.org/goern/forgejo-mcp/v2/pkg/to"
+
+	forgejo_sdk "codeberg.org/mvdkleijn/forgejo-sdk/forgejo/v2"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const (
+	SearchCodeToolName = "search_code"
+
+	CodeSearchModeIndexer = "indexer"
+	CodeSearchModeScan    = "scan"
+
+	defaultCodeResults = 50
+	maxCodeResults     = 200
+
+	// Bounds of the fallback tree scan
+	scanMaxFiles     = 200
+	scanMaxFileSize  = 256 << 10
+	scanTreePageSize = 1000
+	scanMaxTreePages = 5
+	scanMaxLineLen   = 300
+)
+
+var SearchCodeTool = mcp.NewTool(
+	SearchCodeToolName,
+	mcp.WithDescription("Search code in a repository (code indexer, or a bounded tree scan when the indexer is off)"),
+	to.WithResult[*CodeSearchResult](),
+	mcp.WithString("owner", mcp.Required(), mcp.Description(params.Owner)),
+	mcp.WithString("repo", mcp.Required(), mcp.Description(params.Repo)),
+	mcp.WithString("q", mcp.Required(), mcp.Description("Text to search for")),
+	mcp.WithString("ref", mcp.Description("Ref to scan (default branch if empty; forces a tree scan)")),
+	mcp.WithString("path", mcp.Description("Only files under this path prefix (tree scan only)")),
+	mcp.WithBoolean("case_sensitive", mcp.Description("Case-sensitive match (tree scan only)")),
+	mcp.WithNumber("max_results", mcp.Description("Maximum matching lines (at most 200)"), mcp.DefaultNumber(defaultCodeResults)),
+)
+
+// CodeMatch is one matching line
+type CodeMatch struct {
+	Path    string `json:"path"`
+	Line    int    `json:"line"`
+	Content string `json:"content"`
+}
+
+// CodeSearchResult reports the matches and which search mode produced them
+type CodeSearchResult struct {
+	Mode         string       `json:"mode"`
+	Query        string       `json:"query"`
+	Ref          string       `json:"ref,omitempty"`
+	Matches      []*CodeMatch `json:"matches"`
+	FilesScanned int          `json:"files_scanned,omitempty"`
+	Truncated    bool         `json:"truncated"`
+}
+
+// codeIndexerHit is a file returned by the code indexer
+type codeIndexerHit struct {
+	Filename string `json:"filename"`
+	Lines    []struct {
+		Num     int    `json:"num"`
+		Content string `json:"content"`
+	} `json:"lines"`
+}
+
+// errIndexerUnavailable means the instance has no usable code indexer
+var errIndexerUnavailable = errors.New("code indexer unavailable")
+
+// searchIndexer queries the repository code indexer. Instances without the
+// code search endpoint, or with the indexer disabled, answer 404 or 501,
+// which is reported as errIndexerUnavailable.
+func searchIndexer(ctx context.Context, owner, repo, q string, maxResults int) (*CodeSearchResult, error) {
+	query := url.Values{}
+	query.Set("q", q)
+	hits := []*codeIndexerHit{}
+	_, err := forgejo.Do(ctx, "GET", fmt.Sprintf("/repos/%s/%s/search", url.PathEscape(owner), url.PathEscape(repo)), query, nil, &hits)
+	if err != nil {
+		var apiErr *forgejo.APIError
+		if errors.As(err, &apiErr) && (apiErr.StatusCode == http.StatusNotFound || apiErr.StatusCode == http.StatusNotImplemented) {
+			return nil, errIndexerUnavailable
+		}
+		return nil, err
+	}
+
+	result := &CodeSearchResult{Mode: CodeSearchModeIndexer, Query: q, Matches: []*CodeMatch{}}
+	for _, hit := range hits {
+		for _, line := range hit.Lines {
+			if len(result.Matches) == maxResults {
+				result.Truncated = true
+				return result, nil
+			}
+			result.Matches = append(result.Matches, &CodeMatch{Path: hit.Filename, Line: line.Num, Content: clipLine(line.Content)})
+		}
+	}
+	return result, nil
+}
+
+// scanTree lists the blobs of the tree at ref and greps them through fetch,
+// stopping at scanMaxFiles files or maxResults matches, or when ctx is done
+func scanTree(ctx context.Context, entries []forgejo_sdk.GitEntry, q, pathPrefix string, caseSensitive bool, maxResults int, fetch func(path string) ([]byte, error)) (*CodeSearchResult, error) {
+	result := &CodeSearchResult{Mode: CodeSearchModeScan, Query: q, Matches: []*CodeMatch{}}
+	needle := []byte(q)
+	if !caseSensitive {
+		needle = bytes.ToLower(needle)
+	}
+
+	for _, entry := range entries {
+		if entry.Type != "blob" || entry.Size > scanMaxFileSize || !strings.HasPrefix(entry.Path, pathPrefix) {
+			continue
+		}
+		if result.FilesScanned == scanMaxFiles {
+			result.Truncated = true
+			break
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		data, err := fetch(entry.Path)
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %v", entry.Path, err)
+		}
+		result.FilesScanned++
+		if bytes.IndexByte(data, 0) >= 0 {
+			continue // binary
+		}
+
+		for i, line := range bytes.Split(data, []byte("\n")) {
+			haystack := line
+			if !caseSensitive {
+				haystack = bytes.ToLower(line)
+			}
+			if !bytes.Contains(haystack, needle) {
+				continue
+			}
+			if len(result.Matches) == maxResults {
+				result.Truncated = true
+				return result, nil
+			}
+			result.Matches = append(result.Matches, &CodeMatch{
+				Path:    entry.Path,
+				Line:    i + 1,
+				Content: clipLine(strings.TrimRight(string(line), "\r")),
+			})
+		}
+	}
+	return result, nil
+}
+
+// clipLine shortens very long lines such as minified files, cutting at a
+// character boundary so the result stays valid UTF-8
+func clipLine(line string) string {
+	if len(line) <= scanMaxLineLen {
+		return line
+	}
+	cut := scanMaxLineLen
+	for cut > 0 && !utf8.RuneStart(line[cut]) {
+		cut--
+	}
+	return line[:cut] + "…"
+}
+
+// listTree returns up to scanMaxTreePages pages of the recursive tree at ref
+func listTree(ctx context.Context, owner, repo, ref string) (entries []forgejo_sdk.GitEntry, truncated bool, err error) {
+	for page := 1; page <= scanMaxTreePages; page++ {
+		tree, _, err := forgejo.ClientCtx(ctx).GetTrees(owner, repo, ref, forgejo_sdk.GetTreesOptions{
+			Recursive:   true,
+			ListOptions: forgejo_sdk.ListOptions{Page: page, PageSize: scanTreePageSize},
+		})
+		if err != nil {
+			return nil, false, err
+		}
+		entries = append(entries, tree.Entries...)
+		if !tree.Truncated || len(tree.Entries) == 0 {
+			return entries, false, nil
+		}
+	}
+	return entries, true, nil
+}
+
+func SearchCodeFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called SearchCodeFn")
+	owner, err := req.RequireString("owner")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	repo, err := req.RequireString("repo")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	q, err := req.RequireString("q")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	if strings.TrimSpace(q) == "" {
+		return to.ErrorResult(to.InvalidArgument("q must not be empty"))
+	}
+	maxResults := int(req.GetFloat("max_results", defaultCodeResults))
+	if maxResults < 1 || maxResults > maxCodeResults {
+		return to.ErrorResult(to.InvalidArgument("max_results must be between 1 and %d", maxCodeResults))
+	}
+	ref := req.GetString("ref", "")
+	pathPrefix := strings.TrimPrefix(req.GetString("path", ""), "/")
+	caseSensitive := req.GetBool("case_sensitive", false)
+
+	// The indexer only covers the default branch and has its own matching
+	// rules, so scan-only options skip it
+	if ref == "" && pathPrefix == "" && !caseSensitive {
+		result, err := searchIndexer(ctx, owner, repo, q, maxResults)
+		if err == nil {
+			return to.TextResult(result)
+		}
+		if !errors.Is(err, errIndexerUnavailable) {
+			return to.ErrorResult(fmt.Errorf("search code err: %v", err))
+		}
+		log.Debugf("Code indexer unavailable for %s/%s, scanning tree", owner, repo)
+	}
+
+	if ref == "" {
+		repository, _, err := forgejo.ClientCtx(ctx).GetRepo(owner, repo)
+		if err != nil {
+			return to.ErrorResult(fmt.Errorf("get repo err: %v", err))
+		}
+		ref = repository.DefaultBranch
+	}
+
+	entries, treeTruncated, err := listTree(ctx, owner, repo, ref)
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("get tree err: %v", err))
+	}
+	result, err := scanTree(ctx, entries, q, pathPrefix, caseSensitive, maxResults, func(path string) ([]byte, error) {
+		data, _, err := forgejo.ClientCtx(ctx).GetFile(owner, repo, ref, path)
+		return data, err
+	})
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("search code err: %v", err))
+	}
+	result.Ref = ref
+	result.Truncated = result.Truncated || treeTruncated
+	return to.TextResult(result)
+}
@@ -0,0 +1,132 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	forgejo_sdk "codeberg.org/mvdkleijn/forgejo-sdk/forgejo/v2"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var scanFiles = map[string]string{
+	"README.md":        "# Demo\nRun make build\n",
+	"cmd/main.go":      "package main\n\nfunc main() {\n\tBuild()\n}\n",
+	"pkg/build.go":     "package pkg\n\n// Build builds\nfunc Build() {}\r\n",
+	"assets/logo.png":  "\x89PNG\x00build",
+	"vendor/huge.js":   "build",
+	"docs/guide/a.txt": "nothing here",
+}
+
+func scanEntries() []forgejo_sdk.GitEntry {
+	entries := []forgejo_sdk.GitEntry{{Path: "cmd", Type: "tree"}}
+	for _, path := range []string{"README.md", "assets/logo.png", "cmd/main.go", "docs/guide/a.txt", "pkg/build.go"} {
+		entries = append(entries, forgejo_sdk.GitEntry{Path: path, Type: "blob", Size: int64(len(scanFiles[path]))})
+	}
+	return append(entries, forgejo_sdk.GitEntry{Path: "vendor/huge.js", Type: "blob", Size: scanMaxFileSize + 1})
+}
+
+func fetchScanFile(path string) ([]byte, error) {
+	content, ok := scanFiles[path]
+	if !ok {
+		return nil, fmt.Errorf("not found")
+	}
+	return []byte(content), nil
+}
+
+// TestScanTree tests the fallback scan skips trees, binaries and large files
+func TestScanTree(t *testing.T) {
+	result, err := scanTree(context.Background(), scanEntries(), "build", "", false, 10, fetchScanFile)
+	assert.NoError(t, err)
+	assert.Equal(t, CodeSearchModeScan, result.Mode)
+	assert.Equal(t, 5, result.FilesScanned)
+	assert.False(t, result.Truncated)
+	assert.Equal(t, []*CodeMatch{
+		{Path: "README.md", Line: 2, Content: "Run make build"},
+		{Path: "cmd/main.go", Line: 4, Content: "\tBuild()"},
+		{Path: "pkg/build.go", Line: 3, Content: "// Build builds"},
+		{Path: "pkg/build.go", Line: 4, Content: "func Build() {}"},
+	}, result.Matches)
+}
+
+// TestScanTree_Options tests case sensitivity, path prefix and result limits
+func TestScanTree_Options(t *testing.T) {
+	result, err := scanTree(context.Background(), scanEntries(), "Build", "", true, 10, fetchScanFile)
+	assert.NoError(t, err)
+	assert.Len(t, result.Matches, 3)
+
+	result, err = scanTree(context.Background(), scanEntries(), "build", "pkg/", false, 10, fetchScanFile)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result.FilesScanned)
+	assert.Len(t, result.Matches, 2)
+
+	result, err = scanTree(context.Background(), scanEntries(), "build", "", false, 2, fetchScanFile)
+	assert.NoError(t, err)
+	assert.Len(t, result.Matches, 2)
+	assert.True(t, result.Truncated)
+}
+
+// TestScanTree_FetchError tests that read failures are reported
+func TestScanTree_FetchError(t *testing.T) {
+	entries := []forgejo_sdk.GitEntry{{Path: "missing.go", Type: "blob"}}
+	_, err := scanTree(context.Background(), entries, "x", "", false, 10, fetchScanFile)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "read missing.go")
+}
+
+// TestClipLine tests that long lines are shortened
+func TestClipLine(t *testing.T) {
+	assert.Equal(t, "short", clipLine("short"))
+	clipped := clipLine(strings.Repeat("x", scanMaxLineLen+10))
+	assert.True(t, strings.HasPrefix(clipped, strings.Repeat("x", scanMaxLineLen)))
+	assert.True(t, strings.HasSuffix(clipped, "…"))
+
+	// A multi-byte character across the limit is dropped whole
+	clipped = clipLine(strings.Repeat("x", scanMaxLineLen-1) + "é" + strings.Repeat("x", 10))
+	assert.True(t, utf8.ValidString(clipped))
+	assert.Equal(t, strings.Repeat("x", scanMaxLineLen-1)+"…", clipped)
+}
+
+// TestSearchCodeFn_InvalidArgs tests argument validation before any API call
+func TestSearchCodeFn_InvalidArgs(t *testing.T) {
+	tests := []struct {
+		name string
+		args map[string]interface{}
+		want string
+	}{
+		{name: "empty query", args: map[string]interface{}{"owner": "acme", "repo": "website", "q": "  "}, want: "q must not be empty"},
+		{name: "max results", args: map[string]interface{}{"owner": "acme", "repo": "website", "q": "x", "max_results": float64(500)}, want: "max_results must be between"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := mcp.CallToolRequest{
+				Params: mcp.CallToolParams{
+					Arguments: tt.args,
+				},
+			}
+
+			result, err := SearchCodeFn(nil, req)
+			assert.NoError(t, err)
+			require.True(t, result.IsError)
+			assert.Contains(t, result.Content[0].(mcp.TextContent).Text, tt.want)
+		})
+	}
+}
+
+// TestScanTree_Cancelled stops scanning once the context is done
+func TestScanTree_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	fetched := 0
+	_, err := scanTree(ctx, scanEntries(), "build", "", false, 10, func(path string) ([]byte, error) {
+		fetched++
+		cancel()
+		return fetchScanFile(path)
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 1, fetched)
+}
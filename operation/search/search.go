@@ -3,10 +3,14 @@ package search
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"net/url"
+	"time"
 
 	"codeberg.org/goern/forgejo-mcp/v2/operation/params"
 	"codeberg.org/goern/forgejo-mcp/v2/pkg/forgejo"
 	"codeberg.org/goern/forgejo-mcp/v2/pkg/log"
+	"codeberg.org/goern/forgejo-mcp/v2/pkg/paginate"
 	"codeberg.org/goern/forgejo-mcp/v2/pkg/to"
 
 	forgejo_sdk "codeberg.org/mvdkleijn/forgejo-sdk/forgejo/v2"
@@ -18,12 +22,14 @@ const (
 	SearchUsersToolName    = "search_users"
 	SearchOrgTeamsToolName = "search_org_teams"
 	SearchReposToolName    = "search_repos"
+	SearchIssuesToolName   = "search_issues"
 )
 
 var (
 	SearchUsersTool = mcp.NewTool(
 		SearchUsersToolName,
 		mcp.WithDescription("Search users"),
+		to.WithResult[*to.Page[*forgejo_sdk.User]](),
 		mcp.WithString("keyword", mcp.Description(params.Keyword)),
 		mcp.WithNumber("page", mcp.Description(params.Page), mcp.DefaultNumber(1)),
 		mcp.WithNumber("limit", mcp.Description(params.Limit), mcp.DefaultNumber(100)),
@@ -32,6 +38,7 @@ var (
 	SearchOrgTeamsTool = mcp.NewTool(
 		SearchOrgTeamsToolName,
 		mcp.WithDescription("Search org teams"),
+		to.WithResult[*to.Page[*forgejo_sdk.Team]](),
 		mcp.WithString("org", mcp.Required(), mcp.Description(params.Org)),
 		mcp.WithString("keyword", mcp.Description(params.Keyword)),
 		mcp.WithNumber("page", mcp.Description(params.Page), mcp.DefaultNumber(1)),
@@ -41,31 +48,63 @@ var (
 	SearchReposTool = mcp.NewTool(
 		SearchReposToolName,
 		mcp.WithDescription("Search repos"),
+		to.WithResult[*to.Page[*forgejo_sdk.Repository]](),
 		mcp.WithString("keyword", mcp.Description(params.Keyword)),
 		mcp.WithString("sort", mcp.Description(params.Sort), mcp.DefaultString("updated")),
 		mcp.WithString("order", mcp.Description(params.Order), mcp.DefaultString("desc")),
 		mcp.WithNumber("page", mcp.Description(params.Page), mcp.DefaultNumber(1)),
 		mcp.WithNumber("limit", mcp.Description(params.Limit), mcp.DefaultNumber(100)),
 	)
+
+	SearchIssuesTool = mcp.NewTool(
+		SearchIssuesToolName,
+		mcp.WithDescription("Search issues and pull requests across repositories"),
+		to.WithResult[*to.Page[*forgejo_sdk.Issue]](),
+		mcp.WithString("q", mcp.Description(params.Keyword)),
+		mcp.WithString("state", mcp.Description("State (open|closed|all)"), mcp.DefaultString("open")),
+		mcp.WithString("type", mcp.Description("Type (issues|pulls)")),
+		mcp.WithString("labels", mcp.Description("Label names (comma-separated)")),
+		mcp.WithString("milestones", mcp.Description("Milestone names (comma-separated)")),
+		mcp.WithString("owner", mcp.Description("Only repositories of this user or organization")),
+		mcp.WithString("team", mcp.Description("Only repositories of this team (requires owner)")),
+		mcp.WithBoolean("created", mcp.Description("Only issues created by you")),
+		mcp.WithBoolean("assigned", mcp.Description("Only issues assigned to you")),
+		mcp.WithBoolean("mentioned", mcp.Description("Only issues mentioning you")),
+		mcp.WithBoolean("review_requested", mcp.Description("Only pull requests requesting your review")),
+		mcp.WithBoolean("reviewed", mcp.Description("Only pull requests you reviewed")),
+		mcp.WithString("since", mcp.Description("Updated after time (RFC3339)")),
+		mcp.WithString("before", mcp.Description("Updated before time (RFC3339)")),
+		mcp.WithNumber("page", mcp.Description(params.Page), mcp.DefaultNumber(1)),
+		mcp.WithNumber("limit", mcp.Description(params.Limit), mcp.DefaultNumber(20)),
+	)
 )
 
+// issueSearchFlags are the boolean search_issues filters relative to the
+// authenticated user
+var issueSearchFlags = []string{"created", "assigned", "mentioned", "review_requested", "reviewed"}
+
 func RegisterTool(s *server.MCPServer) {
 	s.AddTool(SearchUsersTool, SearchUserFn)
 	s.AddTool(SearchOrgTeamsTool, SearchOrgTeamsFn)
 	s.AddTool(SearchReposTool, SearchReposFn)
+	s.AddTool(SearchIssuesTool, SearchIssuesFn)
+	s.AddTool(SearchCodeTool, SearchCodeFn)
 }
 
 func SearchUserFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	// Create a search query for dummy implementation
 	keyword := req.GetString("keyword", "")
-
-	// Create a basic search option with just a keyword
-	opt := forgejo_sdk.SearchUsersOption{
-		KeyWord: keyword,
+	paging, err := paginate.FromRequest(req, 100)
+	if err != nil {
+		return to.ErrorResult(err)
 	}
 
-	// Use the correct options type for searching
-	result, _, err := forgejo.Client().SearchUsers(opt)
+	result, err := paginate.List(ctx, paging, func(lo forgejo_sdk.ListOptions) ([]*forgejo_sdk.User, *forgejo_sdk.Response, error) {
+		return forgejo.ClientCtx(ctx).SearchUsers(forgejo_sdk.SearchUsersOption{
+			ListOptions: lo,
+			KeyWord:     keyword,
+		})
+	})
 	if err != nil {
 		return to.ErrorResult(fmt.Errorf("search user err: %v", err))
 	}
@@ -81,14 +120,17 @@ func SearchOrgTeamsFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallTo
 	}
 
 	keyword := req.GetString("keyword", "")
-
-	// Create proper search team options
-	opt := &forgejo_sdk.SearchTeamsOptions{
-		Query: keyword,
+	paging, err := paginate.FromRequest(req, 100)
+	if err != nil {
+		return to.ErrorResult(err)
 	}
 
-	// Use the proper options type for search
-	result, _, err := forgejo.Client().SearchOrgTeams(org, opt)
+	result, err := paginate.List(ctx, paging, func(lo forgejo_sdk.ListOptions) ([]*forgejo_sdk.Team, *forgejo_sdk.Response, error) {
+		return forgejo.ClientCtx(ctx).SearchOrgTeams(org, &forgejo_sdk.SearchTeamsOptions{
+			ListOptions: lo,
+			Query:       keyword,
+		})
+	})
 	if err != nil {
 		return to.ErrorResult(fmt.Errorf("search org teams err: %v", err))
 	}
@@ -100,24 +142,97 @@ func SearchReposFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolR
 	keyword := req.GetString("keyword", "")
 	sort := req.GetString("sort", "updated")
 	order := req.GetString("order", "desc")
-	page := req.GetFloat("page", 1)
-	limit := req.GetFloat("limit", 100)
+	paging, err := paginate.FromRequest(req, 100)
+	if err != nil {
+		return to.ErrorResult(err)
+	}
 
 	// Create a proper search options structure
 	opt := forgejo_sdk.SearchRepoOptions{
 		Keyword: keyword,
 		Sort:    sort,
 		Order:   order,
-		ListOptions: forgejo_sdk.ListOptions{
-			Page:     int(page),
-			PageSize: int(limit),
-		},
 	}
 
 	// Call search repos with proper options
-	result, _, err := forgejo.Client().SearchRepos(opt)
+	result, err := paginate.List(ctx, paging, func(lo forgejo_sdk.ListOptions) ([]*forgejo_sdk.Repository, *forgejo_sdk.Response, error) {
+		opt.ListOptions = lo
+		return forgejo.ClientCtx(ctx).SearchRepos(opt)
+	})
 	if err != nil {
 		return to.ErrorResult(fmt.Errorf("search repos err: %v", err))
 	}
 	return to.TextResult(result)
 }
+
+// issueSearchQuery builds the /repos/issues/search query from the tool
+// arguments, without the page and limit parameters
+func issueSearchQuery(req mcp.CallToolRequest) (url.Values, error) {
+	query := url.Values{}
+
+	state := req.GetString("state", "open")
+	switch state {
+	case "open", "closed", "all":
+		query.Set("state", state)
+	default:
+		return nil, to.InvalidArgument("invalid state '%s': must be open, closed or all", state)
+	}
+
+	switch issueType := req.GetString("type", ""); issueType {
+	case "":
+	case "issues", "pulls":
+		query.Set("type", issueType)
+	default:
+		return nil, to.InvalidArgument("invalid type '%s': must be issues or pulls", issueType)
+	}
+
+	for _, key := range []string{"q", "labels", "milestones", "owner", "team"} {
+		if v := req.GetString(key, ""); v != "" {
+			query.Set(key, v)
+		}
+	}
+	if query.Has("team") && !query.Has("owner") {
+		return nil, to.InvalidArgument("team requires owner")
+	}
+
+	for _, key := range issueSearchFlags {
+		if req.GetBool(key, false) {
+			query.Set(key, "true")
+		}
+	}
+
+	for _, key := range []string{"since", "before"} {
+		v := req.GetString(key, "")
+		if v == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return nil, to.InvalidArgument("invalid %s time format (expected RFC3339): %v", key, err)
+		}
+		query.Set(key, t.Format(time.RFC3339))
+	}
+	return query, nil
+}
+
+func SearchIssuesFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called SearchIssuesFn")
+	query, err := issueSearchQuery(req)
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	paging, err := paginate.FromRequest(req, 20)
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+
+	issues, err := paginate.Fetch(ctx, paging, func(page, limit int) ([]*forgejo_sdk.Issue, *http.Response, error) {
+		issues := []*forgejo_sdk.Issue{}
+		resp, err := forgejo.Do(ctx, "GET", "/repos/issues/search", paginate.Query(query, page, limit), nil, &issues)
+		return issues, resp, err
+	})
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("search issues err: %v", err))
+	}
+	return to.TextResult(issues)
+}
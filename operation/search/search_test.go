@@ -0,0 +1,85 @@
+package search
+
+import (
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestSearchIssuesTool verifies the tool definition is correctly configured
+func TestSearchIssuesTool(t *testing.T) {
+	tool := SearchIssuesTool
+
+	assert.Equal(t, "search_issues", tool.Name)
+	assert.NotNil(t, tool.Description)
+
+	params := tool.InputSchema.Properties
+	for _, name := range []string{"q", "state", "type", "labels", "milestones", "owner", "team", "since", "before", "page", "limit"} {
+		assert.Contains(t, params, name)
+	}
+	for _, name := range issueSearchFlags {
+		assert.Contains(t, params, name)
+	}
+	assert.Empty(t, tool.InputSchema.Required)
+}
+
+// TestIssueSearchQuery tests that arguments map onto the search endpoint query
+func TestIssueSearchQuery(t *testing.T) {
+	req := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Arguments: map[string]interface{}{
+				"q":                "flaky",
+				"type":             "pulls",
+				"labels":           "bug,ci",
+				"owner":            "acme",
+				"team":             "backend",
+				"review_requested": true,
+				"assigned":         false,
+				"since":            "2026-10-01T00:00:00Z",
+				"limit":            float64(50),
+			},
+		},
+	}
+
+	query, err := issueSearchQuery(req)
+	assert.NoError(t, err)
+	assert.Equal(t, "open", query.Get("state"))
+	assert.Equal(t, "pulls", query.Get("type"))
+	assert.Equal(t, "flaky", query.Get("q"))
+	assert.Equal(t, "bug,ci", query.Get("labels"))
+	assert.Equal(t, "acme", query.Get("owner"))
+	assert.Equal(t, "backend", query.Get("team"))
+	assert.Equal(t, "true", query.Get("review_requested"))
+	assert.False(t, query.Has("assigned"))
+	assert.Equal(t, "2026-10-01T00:00:00Z", query.Get("since"))
+	assert.False(t, query.Has("page"))
+}
+
+// TestIssueSearchQuery_Invalid tests argument validation before any API call
+func TestIssueSearchQuery_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		args map[string]interface{}
+		want string
+	}{
+		{name: "state", args: map[string]interface{}{"state": "merged"}, want: "invalid state"},
+		{name: "type", args: map[string]interface{}{"type": "commits"}, want: "invalid type"},
+		{name: "team without owner", args: map[string]interface{}{"team": "backend"}, want: "team requires owner"},
+		{name: "date", args: map[string]interface{}{"before": "yesterday"}, want: "invalid before time format"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := mcp.CallToolRequest{
+				Params: mcp.CallToolParams{
+					Arguments: tt.args,
+				},
+			}
+
+			_, err := issueSearchQuery(req)
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), tt.want)
+		})
+	}
+}
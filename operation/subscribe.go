@@ -0,0 +1,159 @@
+package operation
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+
+	"codeberg.org/goern/forgejo-mcp/v2/operation/resource"
+	"codeberg.org/goern/forgejo-mcp/v2/pkg/flag"
+	"codeberg.org/goern/forgejo-mcp/v2/pkg/log"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const (
+	methodSubscribe   = "resources/subscribe"
+	methodUnsubscribe = "resources/unsubscribe"
+
+	// stdioSession is the session ID mcp-go gives the stdio client
+	stdioSession = "stdio"
+)
+
+// subscriptions polls the resources clients subscribed to. mcp-go does not
+// route resources/subscribe, so handleSubscription answers it in front of
+// the transports.
+var subscriptions *resource.Subscriptions
+
+// newSubscriptions returns the poller of s, which notifies sessions through
+// notifications/resources/updated
+func newSubscriptions(s *server.MCPServer) *resource.Subscriptions {
+	return resource.NewSubscriptions(flag.PollInterval, func(session, uri string) error {
+		return s.SendNotificationToSpecificClient(session, mcp.MethodNotificationResourceUpdated, map[string]any{"uri": uri})
+	})
+}
+
+// dropSubscriptions is the unregister-session hook that ends the
+// subscriptions of a closed session
+func dropSubscriptions(ctx context.Context, session server.ClientSession) {
+	subscriptions.DropSession(session.SessionID())
+}
+
+// handleSubscription answers resources/subscribe and resources/unsubscribe
+// requests of session. It returns nil for all other messages.
+func handleSubscription(session string, message []byte) mcp.JSONRPCMessage {
+	var req struct {
+		ID     any    `json:"id"`
+		Method string `json:"method"`
+		Params struct {
+			URI string `json:"uri"`
+		} `json:"params"`
+	}
+	if json.Unmarshal(message, &req) != nil || req.ID == nil {
+		return nil
+	}
+	id := mcp.NewRequestId(req.ID)
+
+	switch req.Method {
+	case methodSubscribe:
+		if err := subscriptions.Subscribe(session, req.Params.URI); err != nil {
+			return mcp.NewJSONRPCError(id, mcp.INVALID_PARAMS, err.Error(), nil)
+		}
+	case methodUnsubscribe:
+		subscriptions.Unsubscribe(session, req.Params.URI)
+	default:
+		return nil
+	}
+	return mcp.NewJSONRPCResultResponse(id, mcp.EmptyResult{})
+}
+
+// lockedWriter serializes the messages that the stdio server and
+// handleSubscription write
+type lockedWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (l *lockedWriter) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.w.Write(p)
+}
+
+// subscriptionReader passes the lines of in on, except subscription
+// requests, which it answers on out
+func subscriptionReader(in io.Reader, out io.Writer) io.Reader {
+	pr, pw := io.Pipe()
+	go func() {
+		reader := bufio.NewReader(in)
+		for {
+			line, err := reader.ReadBytes('\n')
+			if len(line) > 0 {
+				if response := handleSubscription(stdioSession, line); response != nil {
+					data, _ := json.Marshal(response)
+					fmt.Fprintf(out, "%s\n", data)
+				} else if _, err := pw.Write(line); err != nil {
+					return
+				}
+			}
+			if err != nil {
+				pw.CloseWithError(err)
+				return
+			}
+		}
+	}()
+	return pr
+}
+
+// serveStdio is server.ServeStdio with subscriptions answered by
+// handleSubscription
+func serveStdio(s *server.MCPServer) error {
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT)
+	defer cancel()
+
+	out := &lockedWriter{w: os.Stdout}
+	return server.NewStdioServer(s).Listen(ctx, subscriptionReader(os.Stdin, out), out)
+}
+
+// subscriptionHandler answers subscription requests posted to the SSE
+// message endpoint through the session's event stream, and hands everything
+// else to sse
+func subscriptionHandler(sse *server.SSEServer) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		session := r.URL.Query().Get("sessionId")
+		if r.Method != http.MethodPost || session == "" || r.URL.Path != sse.CompleteMessagePath() {
+			sse.ServeHTTP(w, r)
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, "Parse error", http.StatusBadRequest)
+			return
+		}
+		if response := handleSubscription(session, body); response != nil {
+			if err := sse.SendEventToSession(session, response); err != nil {
+				log.Debug("Subscription answer not delivered",
+					log.StringField("session", session),
+					log.ErrorField(err),
+				)
+				subscriptions.DropSession(session)
+				http.Error(w, "Invalid session ID", http.StatusBadRequest)
+				return
+			}
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		sse.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,44 @@
+package operation
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSubscriptionReader answers subscription requests itself and passes
+// every other message on to the stdio server
+func TestSubscriptionReader(t *testing.T) {
+	newMCPServer("test")
+	in := strings.Join([]string{
+		`{"jsonrpc":"2.0","id":1,"method":"resources/subscribe","params":{"uri":"forgejo://acme/website/issues/42"}}`,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/list"}`,
+		`{"jsonrpc":"2.0","id":3,"method":"resources/subscribe","params":{"uri":"https://example.com"}}`,
+		`{"jsonrpc":"2.0","id":4,"method":"resources/unsubscribe","params":{"uri":"forgejo://acme/website/issues/42"}}`,
+	}, "\n") + "\n"
+	var out bytes.Buffer
+
+	passed, err := io.ReadAll(subscriptionReader(strings.NewReader(in), &lockedWriter{w: &out}))
+	require.NoError(t, err)
+	assert.Equal(t, `{"jsonrpc":"2.0","id":2,"method":"tools/list"}`+"\n", string(passed))
+
+	answers := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, answers, 3)
+	assert.Equal(t, `{"jsonrpc":"2.0","id":1,"result":{}}`, answers[0])
+	assert.Contains(t, answers[1], `"id":3`)
+	assert.Contains(t, answers[1], "unknown resource https://example.com")
+	assert.Equal(t, `{"jsonrpc":"2.0","id":4,"result":{}}`, answers[2])
+}
+
+// TestHandleSubscription_OtherMessages leaves other requests and
+// notifications to mcp-go
+func TestHandleSubscription_OtherMessages(t *testing.T) {
+	newMCPServer("test")
+	assert.Nil(t, handleSubscription(stdioSession, []byte(`{"jsonrpc":"2.0","id":1,"method":"resources/read","params":{"uri":"forgejo://a/b"}}`)))
+	assert.Nil(t, handleSubscription(stdioSession, []byte(`{"jsonrpc":"2.0","method":"notifications/initialized"}`)))
+	assert.Nil(t, handleSubscription(stdioSession, []byte(`not json`)))
+}
@@ -0,0 +1,284 @@
+package user
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha1"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"codeberg.org/goern/forgejo-mcp/v2/operation/params"
+	"codeberg.org/goern/forgejo-mcp/v2/pkg/forgejo"
+	"codeberg.org/goern/forgejo-mcp/v2/pkg/log"
+	"codeberg.org/goern/forgejo-mcp/v2/pkg/paginate"
+	"codeberg.org/goern/forgejo-mcp/v2/pkg/to"
+
+	forgejo_sdk "codeberg.org/mvdkleijn/forgejo-sdk/forgejo/v2"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const (
+	ListMySSHKeysToolName = "list_my_ssh_keys"
+	AddSSHKeyToolName     = "add_ssh_key"
+	RemoveSSHKeyToolName  = "remove_ssh_key"
+	ListMyGPGKeysToolName = "list_my_gpg_keys"
+	AddGPGKeyToolName     = "add_gpg_key"
+	RemoveGPGKeyToolName  = "remove_gpg_key"
+)
+
+// GPGKey adds the primary key fingerprint, which the Forgejo API does not return
+type GPGKey struct {
+	*forgejo_sdk.GPGKey
+	Fingerprint string `json:"fingerprint"`
+}
+
+var (
+	ListMySSHKeysTool = mcp.NewTool(
+		ListMySSHKeysToolName,
+		mcp.WithDescription("List my SSH keys with fingerprints"),
+		to.WithResult[*to.Page[*forgejo_sdk.PublicKey]](),
+		mcp.WithNumber("page", mcp.Description(params.Page), mcp.DefaultNumber(1)),
+		mcp.WithNumber("limit", mcp.Description(params.Limit), mcp.DefaultNumber(20)),
+	)
+
+	AddSSHKeyTool = mcp.NewTool(
+		AddSSHKeyToolName,
+		mcp.WithDescription("Add SSH key to my account"),
+		to.WithResult[*forgejo_sdk.PublicKey](),
+		mcp.WithString("title", mcp.Required(), mcp.Description(params.KeyTitle)),
+		mcp.WithString("key", mcp.Required(), mcp.Description("Public SSH key")),
+	)
+
+	RemoveSSHKeyTool = mcp.NewTool(
+		RemoveSSHKeyToolName,
+		mcp.WithDescription("Remove SSH key from my account"),
+		to.WithResult[string](),
+		mcp.WithNumber("id", mcp.Required(), mcp.Description(params.KeyID)),
+	)
+
+	ListMyGPGKeysTool = mcp.NewTool(
+		ListMyGPGKeysToolName,
+		mcp.WithDescription("List my GPG keys with fingerprints"),
+		to.WithResult[*to.Page[*GPGKey]](),
+		mcp.WithNumber("page", mcp.Description(params.Page), mcp.DefaultNumber(1)),
+		mcp.WithNumber("limit", mcp.Description(params.Limit), mcp.DefaultNumber(20)),
+	)
+
+	AddGPGKeyTool = mcp.NewTool(
+		AddGPGKeyToolName,
+		mcp.WithDescription("Add GPG key to my account"),
+		to.WithResult[*GPGKey](),
+		mcp.WithString("armored_public_key", mcp.Required(), mcp.Description("ASCII-armored public GPG key")),
+	)
+
+	RemoveGPGKeyTool = mcp.NewTool(
+		RemoveGPGKeyToolName,
+		mcp.WithDescription("Remove GPG key from my account"),
+		to.WithResult[string](),
+		mcp.WithNumber("id", mcp.Required(), mcp.Description(params.KeyID)),
+	)
+)
+
+// gpgFingerprint returns the upper-case hex v4 fingerprint of the primary key
+// in an armored key block or in the bare base64 packet data Forgejo returns
+// as public_key, or "" if the key cannot be parsed. The
+// fingerprint is the SHA-1 of 0x99, the two-byte body length and the body of
+// the public key packet (RFC 4880, section 12.2).
+func gpgFingerprint(key string) string {
+	data, err := decodeKey(key)
+	if err != nil || len(data) < 2 || data[0]&0x80 == 0 {
+		return ""
+	}
+
+	var tag byte
+	var body []byte
+	if data[0]&0x40 != 0 {
+		// New format packet header
+		tag = data[0] & 0x3f
+		switch l := int(data[1]); {
+		case l < 192:
+			body = sliceBody(data, 2, l)
+		case l < 224:
+			if len(data) < 3 {
+				return ""
+			}
+			body = sliceBody(data, 3, (l-192)<<8+int(data[2])+192)
+		case l == 255:
+			if len(data) < 6 {
+				return ""
+			}
+			body = sliceBody(data, 6, int(data[2])<<24|int(data[3])<<16|int(data[4])<<8|int(data[5]))
+		}
+	} else {
+		// Old format packet header
+		tag = (data[0] >> 2) & 0x0f
+		switch data[0] & 0x03 {
+		case 0:
+			body = sliceBody(data, 2, int(data[1]))
+		case 1:
+			if len(data) < 3 {
+				return ""
+			}
+			body = sliceBody(data, 3, int(data[1])<<8|int(data[2]))
+		case 2:
+			if len(data) < 5 {
+				return ""
+			}
+			body = sliceBody(data, 5, int(data[1])<<24|int(data[2])<<16|int(data[3])<<8|int(data[4]))
+		}
+	}
+
+	// Tag 6 is a public key packet, only v4 keys use the SHA-1 fingerprint
+	if tag != 6 || len(body) == 0 || body[0] != 4 || len(body) > 0xffff {
+		return ""
+	}
+	h := sha1.New()
+	h.Write([]byte{0x99, byte(len(body) >> 8), byte(len(body))})
+	h.Write(body)
+	return fmt.Sprintf("%X", h.Sum(nil))
+}
+
+func sliceBody(data []byte, offset, length int) []byte {
+	if length < 0 || offset+length > len(data) {
+		return nil
+	}
+	return data[offset : offset+length]
+}
+
+// decodeKey decodes an armored key block, or base64 packet data when the
+// armor header is missing
+func decodeKey(key string) ([]byte, error) {
+	if strings.Contains(key, "-----BEGIN") {
+		return dearmor(key)
+	}
+	return base64.StdEncoding.DecodeString(strings.Join(strings.Fields(key), ""))
+}
+
+// dearmor decodes the base64 payload of an ASCII-armored block, skipping the
+// armor headers and the CRC24 checksum line
+func dearmor(armored string) ([]byte, error) {
+	scanner := bufio.NewScanner(strings.NewReader(armored))
+	var payload strings.Builder
+	inBlock, inBody := false, false
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case strings.HasPrefix(line, "-----BEGIN PGP PUBLIC KEY BLOCK"):
+			inBlock = true
+		case !inBlock:
+		case strings.HasPrefix(line, "-----END"):
+			return base64.StdEncoding.DecodeString(payload.String())
+		case !inBody:
+			// Armor headers end with an empty line
+			if line == "" {
+				inBody = true
+			}
+		case strings.HasPrefix(line, "="):
+		default:
+			payload.WriteString(line)
+		}
+	}
+	return nil, fmt.Errorf("no armored public key block found")
+}
+
+func withFingerprint(key *forgejo_sdk.GPGKey) *GPGKey {
+	return &GPGKey{GPGKey: key, Fingerprint: gpgFingerprint(key.PublicKey)}
+}
+
+func ListMySSHKeysFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called ListMySSHKeysFn")
+	paging, err := paginate.FromRequest(req, 20)
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+
+	keys, err := paginate.List(ctx, paging, func(lo forgejo_sdk.ListOptions) ([]*forgejo_sdk.PublicKey, *forgejo_sdk.Response, error) {
+		return forgejo.ClientCtx(ctx).ListMyPublicKeys(forgejo_sdk.ListPublicKeysOptions{ListOptions: lo})
+	})
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("list ssh keys err: %v", err))
+	}
+	return to.TextResult(keys)
+}
+
+func AddSSHKeyFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called AddSSHKeyFn")
+	title, err := req.RequireString("title")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	key, err := req.RequireString("key")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+
+	publicKey, _, err := forgejo.ClientCtx(ctx).CreatePublicKey(forgejo_sdk.CreateKeyOption{Title: title, Key: key})
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("add ssh key err: %v", err))
+	}
+	return to.TextResult(publicKey)
+}
+
+func RemoveSSHKeyFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called RemoveSSHKeyFn")
+	id, err := req.RequireFloat("id")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+
+	_, err = forgejo.ClientCtx(ctx).DeletePublicKey(int64(id))
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("remove ssh key err: %v", err))
+	}
+	return to.TextResult("Remove SSH key success")
+}
+
+func ListMyGPGKeysFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called ListMyGPGKeysFn")
+	paging, err := paginate.FromRequest(req, 20)
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+
+	keys, err := paginate.List(ctx, paging, func(lo forgejo_sdk.ListOptions) ([]*GPGKey, *forgejo_sdk.Response, error) {
+		keys, resp, err := forgejo.ClientCtx(ctx).ListMyGPGKeys(&forgejo_sdk.ListGPGKeysOptions{ListOptions: lo})
+		result := make([]*GPGKey, 0, len(keys))
+		for _, key := range keys {
+			result = append(result, withFingerprint(key))
+		}
+		return result, resp, err
+	})
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("list gpg keys err: %v", err))
+	}
+	return to.TextResult(keys)
+}
+
+func AddGPGKeyFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called AddGPGKeyFn")
+	armored, err := req.RequireString("armored_public_key")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+
+	key, _, err := forgejo.ClientCtx(ctx).CreateGPGKey(forgejo_sdk.CreateGPGKeyOption{ArmoredKey: armored})
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("add gpg key err: %v", err))
+	}
+	return to.TextResult(withFingerprint(key))
+}
+
+func RemoveGPGKeyFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called RemoveGPGKeyFn")
+	id, err := req.RequireFloat("id")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+
+	_, err = forgejo.ClientCtx(ctx).DeleteGPGKey(int64(id))
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("remove gpg key err: %v", err))
+	}
+	return to.TextResult("Remove GPG key success")
+}
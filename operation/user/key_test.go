@@ -0,0 +1,51 @@
+package user
+
+import (
+	"testing"
+
+	forgejo_sdk "codeberg.org/mvdkleijn/forgejo-sdk/forgejo/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+const testGPGKey = `-----BEGIN PGP PUBLIC KEY BLOCK-----
+
+mDMEatTZThYJKwYBBAHaRw8BAQdAxpjIaPJLs11qa1lpYMcx23Wta790xfRYp3TO
+Eai4EAe0GlRlc3QgQm90IDxib3RAZXhhbXBsZS5jb20+iJAEExYIADgWIQTavRH8
+kRp3W+dKS2jIWCELeLPbyAUCatTZTgIbAwULCQgHAgYVCgkICwIEFgIDAQIeAQIX
+gAAKCRDIWCELeLPbyFq1AQDIA2Oa+OtCIeK7EgQCXnWDpmsuv+OF8XmymMXiJkjG
+3AEAyFxjEGkaNg0rAvalZ+Bc6TFMwuy8D/KxTiCN4aR+0gc=
+=vvdL
+-----END PGP PUBLIC KEY BLOCK-----
+`
+
+// testAPIGPGKey is the public_key Forgejo returns for testGPGKey: the bare
+// base64 of the primary key packet
+const testAPIGPGKey = "mDMEatTZThYJKwYBBAHaRw8BAQdAxpjIaPJLs11qa1lpYMcx23Wta790xfRYp3TOEai4EAc="
+
+// TestGPGFingerprint tests fingerprint calculation from an armored key and
+// from the packet data of the API
+func TestGPGFingerprint(t *testing.T) {
+	assert.Equal(t, "DABD11FC911A775BE74A4B68C858210B78B3DBC8", gpgFingerprint(testGPGKey))
+	assert.Equal(t, "DABD11FC911A775BE74A4B68C858210B78B3DBC8", gpgFingerprint(testAPIGPGKey))
+	assert.Empty(t, gpgFingerprint("not base64!"))
+	assert.Empty(t, gpgFingerprint(""))
+	assert.Empty(t, gpgFingerprint("-----BEGIN PGP PUBLIC KEY BLOCK-----\n\nnot base64!\n-----END PGP PUBLIC KEY BLOCK-----"))
+}
+
+// TestWithFingerprint verifies the fingerprint is added next to the key fields
+func TestWithFingerprint(t *testing.T) {
+	key := withFingerprint(&forgejo_sdk.GPGKey{ID: 7, KeyID: "C858210B78B3DBC8", PublicKey: testAPIGPGKey})
+
+	assert.Equal(t, int64(7), key.ID)
+	assert.Equal(t, "DABD11FC911A775BE74A4B68C858210B78B3DBC8", key.Fingerprint)
+	assert.Len(t, key.Fingerprint, 40)
+}
+
+// TestAddSSHKeyTool verifies the tool definition is correctly configured
+func TestAddSSHKeyTool(t *testing.T) {
+	tool := AddSSHKeyTool
+
+	assert.Equal(t, "add_ssh_key", tool.Name)
+	assert.Contains(t, tool.InputSchema.Required, "title")
+	assert.Contains(t, tool.InputSchema.Required, "key")
+}
@@ -9,6 +9,7 @@ import (
 	"codeberg.org/goern/forgejo-mcp/v2/pkg/log"
 	"codeberg.org/goern/forgejo-mcp/v2/pkg/to"
 
+	forgejo_sdk "codeberg.org/mvdkleijn/forgejo-sdk/forgejo/v2"
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 )
@@ -21,11 +22,20 @@ var (
 	GetMyUserInfoTool = mcp.NewTool(
 		GetMyUserInfoToolName,
 		mcp.WithDescription("Get user info"),
+		to.WithResult[*forgejo_sdk.User](),
 	)
 )
 
 func RegisterTool(s *server.MCPServer) {
 	s.AddTool(GetMyUserInfoTool, GetUserInfoFn)
+
+	// Keys
+	s.AddTool(ListMySSHKeysTool, ListMySSHKeysFn)
+	s.AddTool(AddSSHKeyTool, AddSSHKeyFn)
+	s.AddTool(RemoveSSHKeyTool, RemoveSSHKeyFn)
+	s.AddTool(ListMyGPGKeysTool, ListMyGPGKeysFn)
+	s.AddTool(AddGPGKeyTool, AddGPGKeyFn)
+	s.AddTool(RemoveGPGKeyTool, RemoveGPGKeyFn)
 }
 
 func GetUserInfoFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -34,7 +44,7 @@ func GetUserInfoFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolR
 
 	log.LogMCPToolStart(ctx, GetMyUserInfoToolName, map[string]interface{}{})
 
-	user, resp, err := forgejo.Client().GetMyUserInfo()
+	user, resp, err := forgejo.ClientCtx(ctx).GetMyUserInfo()
 	duration := time.Since(start)
 
 	// Log API call details
@@ -20,6 +20,7 @@ var (
 	GetForgejoMCPServerVersionTool = mcp.NewTool(
 		GetForgejoMCPServerVersion,
 		mcp.WithDescription("Get MCP server version"),
+		to.WithResult[string](),
 	)
 )
 
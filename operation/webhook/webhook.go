@@ -0,0 +1,392 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"codeberg.org/goern/forgejo-mcp/v2/operation/params"
+	"codeberg.org/goern/forgejo-mcp/v2/pkg/forgejo"
+	"codeberg.org/goern/forgejo-mcp/v2/pkg/log"
+	"codeberg.org/goern/forgejo-mcp/v2/pkg/paginate"
+	"codeberg.org/goern/forgejo-mcp/v2/pkg/to"
+
+	forgejo_sdk "codeberg.org/mvdkleijn/forgejo-sdk/forgejo/v2"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const (
+	ListWebhooksToolName  = "list_webhooks"
+	GetWebhookToolName    = "get_webhook"
+	CreateWebhookToolName = "create_webhook"
+	EditWebhookToolName   = "edit_webhook"
+	DeleteWebhookToolName = "delete_webhook"
+	TestWebhookToolName   = "test_webhook"
+)
+
+// HookTypeMatrix is not declared by the SDK but accepted by Forgejo
+const HookTypeMatrix forgejo_sdk.HookType = "matrix"
+
+var (
+	ListWebhooksTool = mcp.NewTool(
+		ListWebhooksToolName,
+		mcp.WithDescription("List webhooks of a repo or org"),
+		to.WithResult[*to.Page[*forgejo_sdk.Hook]](),
+		mcp.WithString("owner", mcp.Required(), mcp.Description(params.Owner)),
+		mcp.WithString("repo", mcp.Description(params.ScopeRepo)),
+		mcp.WithNumber("page", mcp.Description(params.Page), mcp.DefaultNumber(1)),
+		mcp.WithNumber("limit", mcp.Description(params.Limit), mcp.DefaultNumber(20)),
+	)
+
+	GetWebhookTool = mcp.NewTool(
+		GetWebhookToolName,
+		mcp.WithDescription("Get webhook"),
+		to.WithResult[*forgejo_sdk.Hook](),
+		mcp.WithString("owner", mcp.Required(), mcp.Description(params.Owner)),
+		mcp.WithString("repo", mcp.Description(params.ScopeRepo)),
+		mcp.WithNumber("id", mcp.Required(), mcp.Description(params.HookID)),
+	)
+
+	CreateWebhookTool = mcp.NewTool(
+		CreateWebhookToolName,
+		mcp.WithDescription("Create webhook"),
+		to.WithResult[*forgejo_sdk.Hook](),
+		mcp.WithString("owner", mcp.Required(), mcp.Description(params.Owner)),
+		mcp.WithString("repo", mcp.Description(params.ScopeRepo)),
+		mcp.WithString("type", mcp.Required(), mcp.Description(params.HookType)),
+		mcp.WithString("url", mcp.Required(), mcp.Description(params.HookURL)),
+		mcp.WithString("content_type", mcp.Description("Payload content type (json|form)"), mcp.DefaultString("json")),
+		mcp.WithString("http_method", mcp.Description("HTTP method (POST|GET)")),
+		mcp.WithString("secret", mcp.Description(params.HookSecret)),
+		mcp.WithString("authorization_header", mcp.Description("Authorization header sent with each delivery")),
+		mcp.WithObject("config", mcp.Description(params.HookConfig)),
+		mcp.WithString("events", mcp.Description(params.HookEvents), mcp.DefaultString("push")),
+		mcp.WithString("branch_filter", mcp.Description(params.HookBranchFilter)),
+		mcp.WithBoolean("active", mcp.Description("Active"), mcp.DefaultBool(true)),
+	)
+
+	EditWebhookTool = mcp.NewTool(
+		EditWebhookToolName,
+		mcp.WithDescription("Edit webhook (only provided fields change)"),
+		to.WithResult[*forgejo_sdk.Hook](),
+		mcp.WithString("owner", mcp.Required(), mcp.Description(params.Owner)),
+		mcp.WithString("repo", mcp.Description(params.ScopeRepo)),
+		mcp.WithNumber("id", mcp.Required(), mcp.Description(params.HookID)),
+		mcp.WithString("url", mcp.Description(params.HookURL)),
+		mcp.WithString("content_type", mcp.Description("Payload content type (json|form)")),
+		mcp.WithString("http_method", mcp.Description("HTTP method (POST|GET)")),
+		mcp.WithString("secret", mcp.Description(params.HookSecret)),
+		mcp.WithString("authorization_header", mcp.Description("Authorization header sent with each delivery")),
+		mcp.WithObject("config", mcp.Description(params.HookConfig)),
+		mcp.WithString("events", mcp.Description(params.HookEvents)),
+		mcp.WithString("branch_filter", mcp.Description(params.HookBranchFilter)),
+		mcp.WithBoolean("active", mcp.Description("Active")),
+	)
+
+	DeleteWebhookTool = mcp.NewTool(
+		DeleteWebhookToolName,
+		mcp.WithDescription("Delete webhook"),
+		to.WithResult[string](),
+		mcp.WithString("owner", mcp.Required(), mcp.Description(params.Owner)),
+		mcp.WithString("repo", mcp.Description(params.ScopeRepo)),
+		mcp.WithNumber("id", mcp.Required(), mcp.Description(params.HookID)),
+	)
+
+	TestWebhookTool = mcp.NewTool(
+		TestWebhookToolName,
+		mcp.WithDescription("Send a test push delivery to a repo webhook"),
+		to.WithResult[string](),
+		mcp.WithString("owner", mcp.Required(), mcp.Description(params.Owner)),
+		mcp.WithString("repo", mcp.Required(), mcp.Description(params.Repo)),
+		mcp.WithNumber("id", mcp.Required(), mcp.Description(params.HookID)),
+		mcp.WithString("ref", mcp.Description("Ref whose last commit is sent (default: default branch)")),
+	)
+)
+
+func RegisterTool(s *server.MCPServer) {
+	s.AddTool(ListWebhooksTool, ListWebhooksFn)
+	s.AddTool(GetWebhookTool, GetWebhookFn)
+	s.AddTool(CreateWebhookTool, CreateWebhookFn)
+	s.AddTool(EditWebhookTool, EditWebhookFn)
+	s.AddTool(DeleteWebhookTool, DeleteWebhookFn)
+	s.AddTool(TestWebhookTool, TestWebhookFn)
+}
+
+// parseHookType accepts the hook types this server supports
+func parseHookType(hookType string) (forgejo_sdk.HookType, error) {
+	t := forgejo_sdk.HookType(strings.ToLower(strings.TrimSpace(hookType)))
+	switch t {
+	case forgejo_sdk.HookTypeForgejo, forgejo_sdk.HookTypeGitea, forgejo_sdk.HookTypeSlack,
+		forgejo_sdk.HookTypeDiscord, HookTypeMatrix:
+		return t, nil
+	}
+	return "", to.InvalidArgument("invalid hook type '%s': must be forgejo, gitea, slack, matrix or discord", hookType)
+}
+
+// parseEvents splits a comma-separated event list, returning nil when empty
+func parseEvents(events string) []string {
+	var list []string
+	for _, event := range strings.Split(events, ",") {
+		if event = strings.TrimSpace(event); event != "" {
+			list = append(list, event)
+		}
+	}
+	return list
+}
+
+// hookConfig collects the config map from the typed arguments and the free
+// form config object. Typed arguments win over keys in the object.
+func hookConfig(req mcp.CallToolRequest) (map[string]string, error) {
+	config := map[string]string{}
+	args := req.GetArguments()
+	if raw, ok := args["config"]; ok && raw != nil {
+		extra, ok := raw.(map[string]any)
+		if !ok {
+			return nil, to.InvalidArgument("config must be an object")
+		}
+		for key, value := range extra {
+			config[key] = fmt.Sprintf("%v", value)
+		}
+	}
+	for _, key := range []string{"url", "content_type", "http_method", "secret"} {
+		if value, ok := args[key].(string); ok && value != "" {
+			config[key] = value
+		}
+	}
+	return config, nil
+}
+
+func ListWebhooksFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called ListWebhooksFn")
+	owner, err := req.RequireString("owner")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	repo := req.GetString("repo", "")
+	paging, err := paginate.FromRequest(req, 20)
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+
+	hooks, err := paginate.List(ctx, paging, func(lo forgejo_sdk.ListOptions) ([]*forgejo_sdk.Hook, *forgejo_sdk.Response, error) {
+		opt := forgejo_sdk.ListHooksOptions{ListOptions: lo}
+		if repo == "" {
+			return forgejo.ClientCtx(ctx).ListOrgHooks(owner, opt)
+		}
+		return forgejo.ClientCtx(ctx).ListRepoHooks(owner, repo, opt)
+	})
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("list webhooks err: %v", err))
+	}
+	return to.TextResult(hooks)
+}
+
+// hookState holds the fields of Forgejo's hook answer that an edit resets
+// when left out; the SDK's Hook drops the branch filter and auth header
+type hookState struct {
+	Events              []string `json:"events"`
+	BranchFilter        string   `json:"branch_filter"`
+	AuthorizationHeader string   `json:"authorization_header"`
+}
+
+func getHookState(ctx context.Context, owner, repo string, id int64) (*hookState, error) {
+	path := fmt.Sprintf("/orgs/%s/hooks/%d", url.PathEscape(owner), id)
+	if repo != "" {
+		path = fmt.Sprintf("/repos/%s/%s/hooks/%d", url.PathEscape(owner), url.PathEscape(repo), id)
+	}
+	var state hookState
+	if _, err := forgejo.Do(ctx, "GET", path, nil, nil, &state); err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
+
+func getHook(ctx context.Context, owner, repo string, id int64) (*forgejo_sdk.Hook, error) {
+	var hook *forgejo_sdk.Hook
+	var err error
+	if repo == "" {
+		hook, _, err = forgejo.ClientCtx(ctx).GetOrgHook(owner, id)
+	} else {
+		hook, _, err = forgejo.ClientCtx(ctx).GetRepoHook(owner, repo, id)
+	}
+	return hook, err
+}
+
+func GetWebhookFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called GetWebhookFn")
+	owner, err := req.RequireString("owner")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	repo := req.GetString("repo", "")
+	id, err := req.RequireFloat("id")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+
+	hook, err := getHook(ctx, owner, repo, int64(id))
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("get webhook err: %v", err))
+	}
+	return to.TextResult(hook)
+}
+
+func CreateWebhookFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called CreateWebhookFn")
+	owner, err := req.RequireString("owner")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	repo := req.GetString("repo", "")
+	typeArg, err := req.RequireString("type")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	hookType, err := parseHookType(typeArg)
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	if _, err := req.RequireString("url"); err != nil {
+		return to.ErrorResult(err)
+	}
+	config, err := hookConfig(req)
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	if _, ok := config["content_type"]; !ok {
+		config["content_type"] = "json"
+	}
+
+	opt := forgejo_sdk.CreateHookOption{
+		Type:                hookType,
+		Config:              config,
+		Events:              parseEvents(req.GetString("events", "push")),
+		BranchFilter:        req.GetString("branch_filter", ""),
+		Active:              req.GetBool("active", true),
+		AuthorizationHeader: req.GetString("authorization_header", ""),
+	}
+
+	var hook *forgejo_sdk.Hook
+	if repo == "" {
+		hook, _, err = forgejo.ClientCtx(ctx).CreateOrgHook(owner, opt)
+	} else {
+		hook, _, err = forgejo.ClientCtx(ctx).CreateRepoHook(owner, repo, opt)
+	}
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("create webhook err: %v", err))
+	}
+	return to.TextResult(hook)
+}
+
+func EditWebhookFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called EditWebhookFn")
+	owner, err := req.RequireString("owner")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	repo := req.GetString("repo", "")
+	id, err := req.RequireFloat("id")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	config, err := hookConfig(req)
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	args := req.GetArguments()
+
+	// Forgejo resets events, branch filter and auth header that an edit
+	// leaves out, so start from the current hook
+	current, err := getHookState(ctx, owner, repo, int64(id))
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("get webhook err: %v", err))
+	}
+	opt := forgejo_sdk.EditHookOption{
+		Config:              config,
+		Events:              current.Events,
+		BranchFilter:        current.BranchFilter,
+		AuthorizationHeader: current.AuthorizationHeader,
+	}
+	if _, ok := args["events"]; ok {
+		opt.Events = parseEvents(req.GetString("events", ""))
+	}
+	if _, ok := args["branch_filter"]; ok {
+		opt.BranchFilter = req.GetString("branch_filter", "")
+	}
+	if _, ok := args["authorization_header"]; ok {
+		opt.AuthorizationHeader = req.GetString("authorization_header", "")
+	}
+	if _, ok := args["active"]; ok {
+		active := req.GetBool("active", true)
+		opt.Active = &active
+	}
+
+	if repo == "" {
+		_, err = forgejo.ClientCtx(ctx).EditOrgHook(owner, int64(id), opt)
+	} else {
+		_, err = forgejo.ClientCtx(ctx).EditRepoHook(owner, repo, int64(id), opt)
+	}
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("edit webhook err: %v", err))
+	}
+
+	hook, err := getHook(ctx, owner, repo, int64(id))
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("get edited webhook err: %v", err))
+	}
+	return to.TextResult(hook)
+}
+
+func DeleteWebhookFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called DeleteWebhookFn")
+	owner, err := req.RequireString("owner")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	repo := req.GetString("repo", "")
+	id, err := req.RequireFloat("id")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+
+	if repo == "" {
+		_, err = forgejo.ClientCtx(ctx).DeleteOrgHook(owner, int64(id))
+	} else {
+		_, err = forgejo.ClientCtx(ctx).DeleteRepoHook(owner, repo, int64(id))
+	}
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("delete webhook err: %v", err))
+	}
+	return to.TextResult("Delete webhook success")
+}
+
+// TestWebhookFn triggers a test delivery. Forgejo only offers this for repo
+// webhooks, so repo is required here.
+func TestWebhookFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called TestWebhookFn")
+	owner, err := req.RequireString("owner")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	repo, err := req.RequireString("repo")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	id, err := req.RequireFloat("id")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+
+	query := url.Values{}
+	if ref := req.GetString("ref", ""); ref != "" {
+		query.Set("ref", ref)
+	}
+	path := fmt.Sprintf("/repos/%s/%s/hooks/%d/tests", url.PathEscape(owner), url.PathEscape(repo), int64(id))
+	_, err = forgejo.Do(ctx, "POST", path, query, nil, nil)
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("test webhook err: %v", err))
+	}
+	return to.TextResult("Test delivery sent")
+}
@@ -0,0 +1,148 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	forgejo_sdk "codeberg.org/mvdkleijn/forgejo-sdk/forgejo/v2"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"codeberg.org/goern/forgejo-mcp/v2/pkg/flag"
+	"codeberg.org/goern/forgejo-mcp/v2/pkg/to"
+)
+
+// TestCreateWebhookTool verifies the tool definition is correctly configured
+func TestCreateWebhookTool(t *testing.T) {
+	tool := CreateWebhookTool
+
+	assert.Equal(t, "create_webhook", tool.Name)
+	assert.NotNil(t, tool.Description)
+
+	params := tool.InputSchema.Properties
+	assert.Contains(t, params, "events")
+	assert.Contains(t, params, "branch_filter")
+	assert.Contains(t, params, "secret")
+	assert.Contains(t, params, "config")
+
+	assert.Contains(t, tool.InputSchema.Required, "owner")
+	assert.Contains(t, tool.InputSchema.Required, "type")
+	assert.Contains(t, tool.InputSchema.Required, "url")
+	assert.NotContains(t, tool.InputSchema.Required, "repo")
+}
+
+// TestParseHookType tests the supported hook types
+func TestParseHookType(t *testing.T) {
+	for _, hookType := range []string{"forgejo", "gitea", "slack", "matrix", "Discord"} {
+		_, err := parseHookType(hookType)
+		assert.NoError(t, err, hookType)
+	}
+
+	_, err := parseHookType("telegram")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid hook type")
+}
+
+// TestHookConfig tests that typed arguments are merged over the config object
+func TestHookConfig(t *testing.T) {
+	req := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Arguments: map[string]interface{}{
+				"url":    "https://example.com/hook",
+				"secret": "s3cret",
+				"config": map[string]interface{}{
+					"url":     "https://ignored.example.com",
+					"channel": "#ci",
+				},
+			},
+		},
+	}
+
+	config, err := hookConfig(req)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"url":     "https://example.com/hook",
+		"secret":  "s3cret",
+		"channel": "#ci",
+	}, config)
+}
+
+// TestWebhookSecretRedacted verifies hook secrets never reach tool results
+func TestWebhookSecretRedacted(t *testing.T) {
+	hook := &forgejo_sdk.Hook{
+		ID:     1,
+		Type:   "forgejo",
+		Config: map[string]string{"url": "https://example.com/hook", "secret": "s3cret"},
+	}
+
+	result, err := to.TextResult(hook)
+	assert.NoError(t, err)
+	text := result.Content[0].(mcp.TextContent).Text
+	assert.NotContains(t, text, "s3cret")
+	assert.Contains(t, text, "https://example.com/hook")
+}
+
+// TestCreateWebhookFn_InvalidType tests hook type validation before any API call
+func TestCreateWebhookFn_InvalidType(t *testing.T) {
+	req := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Arguments: map[string]interface{}{
+				"owner": "my-org",
+				"type":  "msteams",
+				"url":   "https://example.com/hook",
+			},
+		},
+	}
+
+	result, err := CreateWebhookFn(nil, req)
+	assert.NoError(t, err)
+	require.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "invalid hook type")
+}
+
+// TestEditWebhookFn_KeepsUnsetFields toggles active and checks that events,
+// branch filter and auth header of the hook are sent back unchanged
+func TestEditWebhookFn_KeepsUnsetFields(t *testing.T) {
+	var edit map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/api/v1/version":
+			io.WriteString(w, `{"version":"11.0.0"}`)
+		case r.URL.Path == "/api/v1/repos/acme/website/hooks/3" && r.Method == http.MethodPatch:
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&edit))
+			io.WriteString(w, `{"id":3}`)
+		case r.URL.Path == "/api/v1/repos/acme/website/hooks/3":
+			io.WriteString(w, `{"id":3,"type":"forgejo","events":["push","pull_request"],"branch_filter":"main","authorization_header":"Bearer t0ken","active":true}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	defer func(url string) { flag.URL = url }(flag.URL)
+	flag.URL = srv.URL
+
+	req := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Arguments: map[string]interface{}{
+				"owner":  "acme",
+				"repo":   "website",
+				"id":     float64(3),
+				"active": false,
+			},
+		},
+	}
+
+	result, err := EditWebhookFn(context.Background(), req)
+	assert.NoError(t, err)
+	require.False(t, result.IsError, result.Content)
+	require.NotNil(t, edit)
+	assert.Equal(t, false, edit["active"])
+	assert.Equal(t, []any{"push", "pull_request"}, edit["events"])
+	assert.Equal(t, "main", edit["branch_filter"])
+	assert.Equal(t, "Bearer t0ken", edit["authorization_header"])
+}
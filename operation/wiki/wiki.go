@@ -26,6 +26,7 @@ var (
 	ListWikiPagesTool = mcp.NewTool(
 		ListWikiPagesToolName,
 		mcp.WithDescription("List wiki pages"),
+		to.WithResult[*to.Page[*forgejo_sdk.WikiPageMetaData]](),
 		mcp.WithString("owner", mcp.Required(), mcp.Description(params.Owner)),
 		mcp.WithString("repo", mcp.Required(), mcp.Description(params.Repo)),
 	)
@@ -33,6 +34,7 @@ var (
 	CreateWikiPageTool = mcp.NewTool(
 		CreateWikiPageToolName,
 		mcp.WithDescription("Create wiki page"),
+		to.WithResult[*forgejo_sdk.WikiPage](),
 		mcp.WithString("owner", mcp.Required(), mcp.Description(params.Owner)),
 		mcp.WithString("repo", mcp.Required(), mcp.Description(params.Repo)),
 		mcp.WithString("title", mcp.Required(), mcp.Description(params.WikiTitle)),
@@ -43,6 +45,7 @@ var (
 	UpdateWikiPageTool = mcp.NewTool(
 		UpdateWikiPageToolName,
 		mcp.WithDescription("Update wiki page"),
+		to.WithResult[*forgejo_sdk.WikiPage](),
 		mcp.WithString("owner", mcp.Required(), mcp.Description(params.Owner)),
 		mcp.WithString("repo", mcp.Required(), mcp.Description(params.Repo)),
 		mcp.WithString("page_name", mcp.Required(), mcp.Description(params.WikiPage)),
@@ -63,11 +66,11 @@ func ListWikiPagesFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToo
 	owner, _ := req.Params.Arguments["owner"].(string)
 	repo, _ := req.Params.Arguments["repo"].(string)
 
-	wikiPages, _, err := forgejo.Client().ListWikiPages(owner, repo)
+	wikiPages, _, err := forgejo.ClientCtx(ctx).ListWikiPages(owner, repo)
 	if err != nil {
 		return to.ErrorResult(fmt.Errorf("list wiki pages err: %v", err))
 	}
-	return to.TextResult(wikiPages)
+	return to.TextResult(to.SinglePage(wikiPages))
 }
 
 func CreateWikiPageFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -89,7 +92,7 @@ func CreateWikiPageFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallTo
 		Message: message,
 	}
 
-	wikiPage, _, err := forgejo.Client().CreateWikiPage(owner, repo, opt)
+	wikiPage, _, err := forgejo.ClientCtx(ctx).CreateWikiPage(owner, repo, opt)
 	if err != nil {
 		return to.ErrorResult(fmt.Errorf("create wiki page err: %v", err))
 	}
@@ -121,7 +124,7 @@ func UpdateWikiPageFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallTo
 		Message: message,
 	}
 
-	wikiPage, _, err := forgejo.Client().EditWikiPage(owner, repo, pageName, opt)
+	wikiPage, _, err := forgejo.ClientCtx(ctx).EditWikiPage(owner, repo, pageName, opt)
 	if err != nil {
 		return to.ErrorResult(fmt.Errorf("update wiki page err: %v", err))
 	}
@@ -1,10 +1,33 @@
 package flag
 
+import "time"
+
 var (
 	URL     string
 	SSEPort int
 	Token   string
 	Version string
 
+	// Verbosity is "compact" (default) or "full" tool output
+	Verbosity string
+	// Format is "json" (default) or "markdown" tool output text
+	Format string
+	// MaxItems caps the items of list calls with all=true
+	MaxItems int
+
+	// Timeout bounds each Forgejo API call, retries included
+	Timeout time.Duration
+	// MaxRetries is how often idempotent calls are retried, 0 disables retries
+	MaxRetries int
+	// MaxConcurrency limits the Forgejo API calls in flight
+	MaxConcurrency int
+	// ToolTimeout is the deadline of a tool call; long tools get more
+	ToolTimeout time.Duration
+	// CacheSize is how many Forgejo API responses are cached, 0 disables
+	// the cache
+	CacheSize int
+	// PollInterval is how often subscribed resources are checked
+	PollInterval time.Duration
+
 	Debug bool
 )
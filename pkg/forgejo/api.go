@@ -0,0 +1,203 @@
+package forgejo
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"codeberg.org/goern/forgejo-mcp/v2/pkg/flag"
+)
+
+// APIError is returned by Do when Forgejo answers with a non-2xx status
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("forgejo API error: %d %s", e.StatusCode, http.StatusText(e.StatusCode))
+	}
+	return e.Message
+}
+
+// Do performs a raw API request against /api/v1 for endpoints the SDK does
+// not cover. body is sent as JSON when non-nil and a successful response is
+// decoded into out when out is non-nil.
+func Do(ctx context.Context, method, path string, query url.Values, body, out any) (*http.Response, error) {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("marshal request body: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := newRequest(ctx, method, path, query, reader)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	return send(req, out)
+}
+
+// DoUpload sends content as the multipart file field to an /api/v1 endpoint
+// and decodes a successful JSON response into out
+func DoUpload(ctx context.Context, path string, query url.Values, field, filename string, content []byte, out any) (*http.Response, error) {
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+	part, err := writer.CreateFormFile(field, filename)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := part.Write(content); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+
+	req, err := newRequest(ctx, "POST", path, query, &buf)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	return send(req, out)
+}
+
+// Download fetches a file URL, such as an attachment's browser_download_url,
+// and returns at most maxBytes of it. The access token is only sent when the
+// URL points at the configured Forgejo instance.
+func Download(ctx context.Context, rawURL string, maxBytes int64) (data []byte, contentType string, err error) {
+	target, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid download URL: %w", err)
+	}
+	base, err := url.Parse(flag.URL)
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid forgejo URL: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", target.String(), nil)
+	if err != nil {
+		return nil, "", err
+	}
+	if flag.Token != "" && target.Scheme == base.Scheme && target.Host == base.Host {
+		req.Header.Set("Authorization", "token "+flag.Token)
+	}
+
+	resp, err := HTTPClient().Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return nil, "", &APIError{StatusCode: resp.StatusCode}
+	}
+
+	data, err = io.ReadAll(io.LimitReader(resp.Body, maxBytes+1))
+	if err != nil {
+		return nil, "", fmt.Errorf("read download: %w", err)
+	}
+	if int64(len(data)) > maxBytes {
+		return nil, "", fmt.Errorf("file exceeds %d bytes", maxBytes)
+	}
+	return data, resp.Header.Get("Content-Type"), nil
+}
+
+func newRequest(ctx context.Context, method, path string, query url.Values, body io.Reader) (*http.Request, error) {
+	endpoint := strings.TrimSuffix(flag.URL, "/") + "/api/v1" + path
+	if len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if flag.Token != "" {
+		req.Header.Set("Authorization", "token "+flag.Token)
+	}
+	return req, nil
+}
+
+// Poll fetches an /api/v1 endpoint past the cache, sending etag as
+// If-None-Match when set. It reports modified false when Forgejo answers 304
+// Not Modified, and otherwise returns the body and the new ETag.
+func Poll(ctx context.Context, path string, query url.Values, etag string) (data []byte, newETag string, modified bool, err error) {
+	req, err := newRequest(ctx, http.MethodGet, path, query, nil)
+	if err != nil {
+		return nil, "", false, err
+	}
+	req.Header.Set("Cache-Control", "no-cache")
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+
+	resp, err := HTTPClient().Do(req)
+	if err != nil {
+		return nil, "", false, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotModified {
+		return nil, etag, false, nil
+	}
+
+	data, err = io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", false, fmt.Errorf("read response body: %w", err)
+	}
+	if resp.StatusCode/100 != 2 {
+		return nil, "", false, newAPIError(resp, data)
+	}
+	return data, resp.Header.Get("ETag"), true, nil
+}
+
+// send performs req, turning non-2xx answers into *APIError, whose message
+// errorTransport formatted, and decoding a successful body into out when out
+// is non-nil
+func send(req *http.Request, out any) (*http.Response, error) {
+	resp, err := HTTPClient().Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return resp, fmt.Errorf("read response body: %w", err)
+	}
+
+	if resp.StatusCode/100 != 2 {
+		return resp, newAPIError(resp, data)
+	}
+
+	if out != nil && len(data) > 0 {
+		if err := json.Unmarshal(data, out); err != nil {
+			return resp, fmt.Errorf("decode response body: %w", err)
+		}
+	}
+	return resp, nil
+}
+
+// newAPIError returns the error of a non-2xx answer with body data
+func newAPIError(resp *http.Response, data []byte) *APIError {
+	apiErr := &APIError{StatusCode: resp.StatusCode}
+	var msg struct {
+		Message string `json:"message"`
+	}
+	if json.Unmarshal(data, &msg) == nil {
+		apiErr.Message = msg.Message
+	}
+	return apiErr
+}
@@ -0,0 +1,295 @@
+package forgejo
+
+import (
+	"bytes"
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"codeberg.org/goern/forgejo-mcp/v2/pkg/log"
+)
+
+// maxCacheBody is the largest response body that is cached
+const maxCacheBody = 1 << 20
+
+// defaultCacheTTL applies to GET endpoints without an entry in cacheTTLs
+const defaultCacheTTL = 30 * time.Second
+
+// cacheTTLs are the per-endpoint lifetimes of cached responses, matched
+// against the path below /api/v1. A zero TTL keeps an endpoint uncached.
+var cacheTTLs = []struct {
+	pattern *regexp.Regexp
+	ttl     time.Duration
+}{
+	{regexp.MustCompile(`^/version$`), time.Hour},
+	{regexp.MustCompile(`^/user$`), 5 * time.Minute},
+	{regexp.MustCompile(`^/repos/[^/]+/[^/]+/labels$`), 5 * time.Minute},
+	{regexp.MustCompile(`^/repos/[^/]+/[^/]+/branches$`), time.Minute},
+	{regexp.MustCompile(`^/notifications|/activities/|/stopwatches$|/times$`), 0},
+}
+
+// cacheTTL returns how long a GET response of path stays fresh
+func cacheTTL(path string) time.Duration {
+	for _, entry := range cacheTTLs {
+		if entry.pattern.MatchString(path) {
+			return entry.ttl
+		}
+	}
+	return defaultCacheTTL
+}
+
+// cacheEntry is a cached response
+type cacheEntry struct {
+	key     string
+	user    string
+	owner   string
+	status  int
+	header  http.Header
+	body    []byte
+	expires time.Time
+	elem    *list.Element
+}
+
+// response rebuilds the cached response for req
+func (e *cacheEntry) response(req *http.Request) *http.Response {
+	return &http.Response{
+		Status:        http.StatusText(e.status),
+		StatusCode:    e.status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        e.header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(e.body)),
+		ContentLength: int64(len(e.body)),
+		Request:       req,
+	}
+}
+
+// cacheTransport keeps successful GET responses per user and URL for their
+// endpoint's TTL, revalidates expired ones that carry an ETag, and drops a
+// user's responses related to anything that user changes. At most size
+// responses are kept, evicting the least recently used. Requests sent with
+// Cache-Control: no-cache, such as subscription polls, bypass the cache.
+type cacheTransport struct {
+	next http.RoundTripper
+	size int
+	ttl  func(path string) time.Duration
+
+	mu      sync.Mutex
+	entries map[string]*cacheEntry
+	lru     *list.List
+	hits    int
+	misses  int
+}
+
+func newCacheTransport(next http.RoundTripper, size int) *cacheTransport {
+	return &cacheTransport{
+		next:    next,
+		size:    size,
+		ttl:     cacheTTL,
+		entries: map[string]*cacheEntry{},
+		lru:     list.New(),
+	}
+}
+
+func (t *cacheTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	user := cacheUser(req)
+	path := apiPath(req.URL.Path)
+
+	if req.Method != http.MethodGet {
+		resp, err := t.next.RoundTrip(req)
+		if err == nil && isMutation(req.Method) && resp.StatusCode < 400 {
+			t.invalidate(user, path)
+		}
+		return resp, err
+	}
+	ttl := t.ttl(path)
+	if ttl <= 0 || strings.Contains(req.Header.Get("Cache-Control"), "no-cache") {
+		return t.next.RoundTrip(req)
+	}
+
+	key := user + " " + req.URL.String()
+	entry, fresh := t.lookup(key)
+	if fresh {
+		t.logResult("hit", req)
+		return entry.response(req), nil
+	}
+
+	r := req
+	if entry != nil {
+		if etag := entry.header.Get("ETag"); etag != "" {
+			r = req.Clone(req.Context())
+			r.Header.Set("If-None-Match", etag)
+		}
+	}
+	resp, err := t.next.RoundTrip(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusNotModified && entry != nil {
+		io.Copy(io.Discard, io.LimitReader(resp.Body, maxErrorBody))
+		resp.Body.Close()
+		t.refresh(entry, ttl)
+		t.logResult("revalidated", req)
+		return entry.response(req), nil
+	}
+	t.logResult("miss", req)
+	if resp.StatusCode != http.StatusOK || strings.Contains(resp.Header.Get("Cache-Control"), "no-store") {
+		return resp, nil
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxCacheBody+1))
+	if err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+	if len(data) > maxCacheBody {
+		// Too large to keep: hand on what was read and the rest
+		resp.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(data), resp.Body), resp.Body}
+		return resp, nil
+	}
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(data))
+
+	t.store(&cacheEntry{
+		key:     key,
+		user:    user,
+		owner:   pathOwner(path),
+		status:  resp.StatusCode,
+		header:  resp.Header.Clone(),
+		body:    data,
+		expires: time.Now().Add(ttl),
+	})
+	return resp, nil
+}
+
+// lookup returns the entry under key and whether it is still fresh
+func (t *cacheTransport) lookup(key string) (*cacheEntry, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	entry, ok := t.entries[key]
+	if !ok {
+		t.misses++
+		return nil, false
+	}
+	t.lru.MoveToFront(entry.elem)
+	if time.Now().Before(entry.expires) {
+		t.hits++
+		return entry, true
+	}
+	t.misses++
+	return entry, false
+}
+
+// refresh extends an entry that the server confirmed as unchanged
+func (t *cacheTransport) refresh(entry *cacheEntry, ttl time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	entry.expires = time.Now().Add(ttl)
+}
+
+func (t *cacheTransport) store(entry *cacheEntry) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if old, ok := t.entries[entry.key]; ok {
+		t.remove(old)
+	}
+	entry.elem = t.lru.PushFront(entry)
+	t.entries[entry.key] = entry
+	for t.lru.Len() > t.size {
+		t.remove(t.lru.Back().Value.(*cacheEntry))
+	}
+}
+
+// invalidate drops the user's responses related to a change of path: those
+// of the same owner and those spanning owners, such as /user and search.
+// Changes outside an owner drop all of the user's responses.
+func (t *cacheTransport) invalidate(user, path string) {
+	owner := pathOwner(path)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	dropped := 0
+	for _, entry := range t.entries {
+		if entry.user == user && (owner == "" || entry.owner == "" || entry.owner == owner) {
+			t.remove(entry)
+			dropped++
+		}
+	}
+	if dropped > 0 {
+		log.Debug("Forgejo API cache invalidated",
+			log.StringField("path", path),
+			log.IntField("dropped", dropped),
+		)
+	}
+}
+
+// remove deletes entry; the caller holds t.mu
+func (t *cacheTransport) remove(entry *cacheEntry) {
+	t.lru.Remove(entry.elem)
+	delete(t.entries, entry.key)
+}
+
+func (t *cacheTransport) logResult(result string, req *http.Request) {
+	t.mu.Lock()
+	hits, misses, size := t.hits, t.misses, len(t.entries)
+	t.mu.Unlock()
+	log.Debug("Forgejo API cache "+result,
+		log.SanitizedURLField("url", req.URL.String()),
+		log.IntField("hits", hits),
+		log.IntField("misses", misses),
+		log.IntField("entries", size),
+	)
+}
+
+// cacheUser identifies the caller by a hash of its credentials, so the
+// token itself is never kept
+func cacheUser(req *http.Request) string {
+	sum := sha256.Sum256([]byte(req.Header.Get("Authorization") + "\x00" + req.Header.Get("Sudo")))
+	return hex.EncodeToString(sum[:8])
+}
+
+// apiPath returns the path below /api/v1, or the whole path of other URLs
+func apiPath(path string) string {
+	if i := strings.Index(path, "/api/v1/"); i >= 0 {
+		return path[i+len("/api/v1"):]
+	}
+	return path
+}
+
+// pathOwner returns the user or organization an API path belongs to, or ""
+// for paths that span owners such as /user, /repos/search or /notifications
+func pathOwner(path string) string {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	if len(segments) < 2 {
+		return ""
+	}
+	switch segments[0] {
+	case "repos":
+		if len(segments) < 3 || segments[1] == "issues" && segments[2] == "search" {
+			return ""
+		}
+	case "orgs", "users", "packages":
+	default:
+		return ""
+	}
+	return strings.ToLower(segments[1])
+}
+
+// isMutation reports whether a call with method may change data
+func isMutation(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,149 @@
+package forgejo
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// cacheServer answers every GET with its path and the number of calls so
+// far, and counts the calls per path
+func cacheServer(t *testing.T) (*httptest.Server, *atomic.Int32) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := calls.Add(1)
+		if r.Method == http.MethodGet {
+			io.WriteString(w, r.URL.Path+" "+string(rune('0'+n)))
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &calls
+}
+
+// cacheGet calls url as token and returns the body
+func cacheGet(t *testing.T, client *http.Client, url, token string) string {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "token "+token)
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return string(body)
+}
+
+// TestCacheTransport_Hit serves repeated calls from the cache, separately
+// per token
+func TestCacheTransport_Hit(t *testing.T) {
+	srv, calls := cacheServer(t)
+	client := &http.Client{Transport: newCacheTransport(http.DefaultTransport, 10)}
+	url := srv.URL + "/api/v1/repos/o/r/labels"
+
+	first := cacheGet(t, client, url, "alice")
+	assert.Equal(t, first, cacheGet(t, client, url, "alice"))
+	assert.Equal(t, int32(1), calls.Load())
+
+	assert.NotEqual(t, first, cacheGet(t, client, url, "bob"))
+	assert.Equal(t, int32(2), calls.Load())
+
+	// Uncached endpoints always reach the server
+	cacheGet(t, client, srv.URL+"/api/v1/notifications", "alice")
+	cacheGet(t, client, srv.URL+"/api/v1/notifications", "alice")
+	assert.Equal(t, int32(4), calls.Load())
+}
+
+// TestCacheTransport_Revalidate sends the ETag of expired responses and
+// serves the cached body on 304
+func TestCacheTransport_Revalidate(t *testing.T) {
+	var calls, notModified atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			notModified.Add(1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		io.WriteString(w, "branches")
+	}))
+	defer srv.Close()
+
+	transport := newCacheTransport(http.DefaultTransport, 10)
+	transport.ttl = func(string) time.Duration { return time.Millisecond }
+	client := &http.Client{Transport: transport}
+
+	assert.Equal(t, "branches", cacheGet(t, client, srv.URL, "alice"))
+	time.Sleep(5 * time.Millisecond)
+	assert.Equal(t, "branches", cacheGet(t, client, srv.URL, "alice"))
+	assert.Equal(t, int32(2), calls.Load())
+	assert.Equal(t, int32(1), notModified.Load())
+}
+
+// TestCacheTransport_Invalidate drops related responses after a change
+func TestCacheTransport_Invalidate(t *testing.T) {
+	srv, calls := cacheServer(t)
+	client := &http.Client{Transport: newCacheTransport(http.DefaultTransport, 10)}
+	labels := srv.URL + "/api/v1/repos/o/r/labels"
+	other := srv.URL + "/api/v1/repos/other/r/labels"
+	me := srv.URL + "/api/v1/user"
+
+	for _, url := range []string{labels, other, me} {
+		cacheGet(t, client, url, "alice")
+	}
+	assert.Equal(t, int32(3), calls.Load())
+
+	req, _ := http.NewRequest(http.MethodPost, srv.URL+"/api/v1/repos/o/r/labels", strings.NewReader("{}"))
+	req.Header.Set("Authorization", "token alice")
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, int32(4), calls.Load())
+
+	// Same owner and owner-spanning responses are fetched again
+	cacheGet(t, client, labels, "alice")
+	cacheGet(t, client, me, "alice")
+	assert.Equal(t, int32(6), calls.Load())
+	cacheGet(t, client, other, "alice")
+	assert.Equal(t, int32(6), calls.Load())
+}
+
+// TestCacheTransport_Size evicts the least recently used response
+func TestCacheTransport_Size(t *testing.T) {
+	srv, calls := cacheServer(t)
+	client := &http.Client{Transport: newCacheTransport(http.DefaultTransport, 2)}
+
+	cacheGet(t, client, srv.URL+"/a", "alice")
+	cacheGet(t, client, srv.URL+"/b", "alice")
+	cacheGet(t, client, srv.URL+"/a", "alice")
+	cacheGet(t, client, srv.URL+"/c", "alice")
+	assert.Equal(t, int32(3), calls.Load())
+
+	cacheGet(t, client, srv.URL+"/a", "alice")
+	assert.Equal(t, int32(3), calls.Load())
+	cacheGet(t, client, srv.URL+"/b", "alice")
+	assert.Equal(t, int32(4), calls.Load())
+}
+
+func TestPathOwner(t *testing.T) {
+	tests := map[string]string{
+		"/repos/Goern/forgejo-mcp/labels": "goern",
+		"/orgs/acme/teams":                "acme",
+		"/users/bob/repos":                "bob",
+		"/repos/search":                   "",
+		"/repos/issues/search":            "",
+		"/repos/migrate":                  "",
+		"/user":                           "",
+		"/notifications":                  "",
+	}
+	for path, want := range tests {
+		assert.Equal(t, want, pathOwner(path), path)
+	}
+}
@@ -15,6 +15,9 @@ import (
 var (
 	client     *forgejo.Client
 	clientOnce sync.Once
+	// serverVersion is read once so clients bound to a call context skip
+	// the SDK's version request
+	serverVersion string
 )
 
 // Client returns a Forgejo client configured to connect to a Forgejo instance
@@ -22,7 +25,7 @@ var (
 func Client() *forgejo.Client {
 	clientOnce.Do(func() {
 		if client == nil {
-			c, err := forgejo.NewClient(flag.URL, forgejo.SetToken(flag.Token))
+			c, err := forgejo.NewClient(flag.URL, forgejo.SetToken(flag.Token), forgejo.SetHTTPClient(HTTPClient()))
 			if err != nil {
 				log.Error("Failed to create Forgejo client",
 					log.SanitizedURLField("url", flag.URL),
@@ -31,6 +34,9 @@ func Client() *forgejo.Client {
 				log.Fatalf("create forgejo client err: %v", err)
 			}
 			client = c
+			if v, _, err := c.ServerVersion(); err == nil {
+				serverVersion = v
+			}
 			log.Info("Successfully created Forgejo client",
 				log.SanitizedURLField("url", flag.URL),
 				log.BoolField("token_configured", flag.Token != ""),
@@ -40,6 +46,51 @@ func Client() *forgejo.Client {
 	return client
 }
 
+type clientKey struct{}
+
+// NewClientCtx returns a Forgejo client whose requests are bound to ctx, so
+// they stop when the tool call is cancelled or runs past its deadline
+func NewClientCtx(ctx context.Context) (*forgejo.Client, error) {
+	c, err := forgejo.NewClient(flag.URL,
+		forgejo.SetToken(flag.Token),
+		forgejo.SetHTTPClient(HTTPClient()),
+		forgejo.SetContext(ctx),
+		forgejo.SetForgejoVersion(serverVersion),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("create forgejo client err: %v", err)
+	}
+	return c, nil
+}
+
+// WithClient returns ctx carrying a client bound to it, which ClientCtx
+// hands out. The tool and resource middlewares call it once per call, so a
+// client that cannot be built fails that call rather than the server.
+func WithClient(ctx context.Context) (context.Context, error) {
+	c, err := NewClientCtx(ctx)
+	if err != nil {
+		return ctx, err
+	}
+	return context.WithValue(ctx, clientKey{}, c), nil
+}
+
+// ClientCtx returns the client WithClient bound to ctx. Outside of tool
+// calls and resource reads it builds one, falling back to the shared client
+// when that fails.
+func ClientCtx(ctx context.Context) *forgejo.Client {
+	if c, ok := ctx.Value(clientKey{}).(*forgejo.Client); ok {
+		return c
+	}
+	c, err := NewClientCtx(ctx)
+	if err != nil {
+		log.Error("Failed to create Forgejo client bound to the call",
+			log.SanitizedURLField("url", flag.URL),
+			log.ErrorField(err),
+		)
+		return Client()
+	}
+	return c
+}
 
 // VerifyConnection attempts to get basic information to verify
 // that the client is properly connected
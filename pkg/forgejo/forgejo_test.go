@@ -0,0 +1,26 @@
+package forgejo
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestWithClient verifies the client bound to a call is handed out by
+// ClientCtx, and that a client which cannot be built is an error of the call
+func TestWithClient(t *testing.T) {
+	defer func(version string) { serverVersion = version }(serverVersion)
+
+	serverVersion = "11.0.0"
+	ctx, err := WithClient(context.Background())
+	require.NoError(t, err)
+	client := ClientCtx(ctx)
+	require.NotNil(t, client)
+	assert.Same(t, client, ClientCtx(ctx))
+
+	serverVersion = "not a version"
+	_, err = WithClient(context.Background())
+	assert.ErrorContains(t, err, "create forgejo client")
+}
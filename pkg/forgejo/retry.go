@@ -0,0 +1,218 @@
+package forgejo
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"codeberg.org/goern/forgejo-mcp/v2/pkg/log"
+)
+
+// Backoff settings of retried calls
+const (
+	retryBaseDelay = 500 * time.Millisecond
+	retryMaxDelay  = 10 * time.Second
+	// retryMaxWait is the longest Retry-After or rate-limit reset that is
+	// waited for; longer ones are left to the caller
+	retryMaxWait = 30 * time.Second
+)
+
+// retryTransport retries idempotent calls on connection errors, 5xx and 429
+// answers with jittered exponential backoff. Retry-After and exhausted
+// X-RateLimit-Remaining headers pause all calls until the server is ready.
+type retryTransport struct {
+	next       http.RoundTripper
+	maxRetries int
+	baseDelay  time.Duration
+	maxDelay   time.Duration
+	maxWait    time.Duration
+
+	mu          sync.Mutex
+	pausedUntil time.Time
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	retryable := isIdempotent(req.Method) && (req.Body == nil || req.Body == http.NoBody || req.GetBody != nil)
+
+	for attempt := 0; ; attempt++ {
+		if err := sleep(ctx, t.pause()); err != nil {
+			return nil, err
+		}
+
+		r := req
+		if attempt > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			r = req.Clone(ctx)
+			r.Body = body
+		}
+		resp, err := t.next.RoundTrip(r)
+		if resp != nil {
+			t.notePause(resp)
+		}
+
+		if !retryable || attempt >= t.maxRetries || ctx.Err() != nil || !shouldRetry(resp, err) {
+			return resp, err
+		}
+		if resp != nil {
+			if after, ok := retryAfter(resp.Header); ok && after > t.maxWait {
+				return resp, nil
+			}
+			io.Copy(io.Discard, io.LimitReader(resp.Body, maxErrorBody))
+			resp.Body.Close()
+		}
+
+		wait := max(t.backoff(attempt), t.pause())
+		status := 0
+		if resp != nil {
+			status = resp.StatusCode
+		}
+		log.Debug("Retrying Forgejo API call",
+			log.StringField("method", req.Method),
+			log.SanitizedURLField("url", req.URL.String()),
+			log.IntField("attempt", attempt+1),
+			log.DurationField("wait", wait),
+			log.IntField("status_code", status),
+			log.ErrorField(err),
+		)
+		if err := sleep(ctx, wait); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// backoff is the jittered delay before retry attempt+1: a random duration
+// between half and all of baseDelay doubled per attempt, up to maxDelay
+func (t *retryTransport) backoff(attempt int) time.Duration {
+	d := t.baseDelay << attempt
+	if d <= 0 || d > t.maxDelay {
+		d = t.maxDelay
+	}
+	return d/2 + rand.N(d/2+1)
+}
+
+// pause returns how long calls still wait for the server, or 0
+func (t *retryTransport) pause() time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return max(time.Until(t.pausedUntil), 0)
+}
+
+// notePause records the wait a response asks for: Retry-After on 429 and
+// 503 answers, or the reset of an exhausted rate limit. Waits longer than
+// maxWait are not taken on.
+func (t *retryTransport) notePause(resp *http.Response) {
+	var wait time.Duration
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable {
+		wait, _ = retryAfter(resp.Header)
+	}
+	if resp.Header.Get("X-RateLimit-Remaining") == "0" {
+		if reset, ok := rateLimitReset(resp.Header); ok {
+			wait = max(wait, reset)
+		}
+	}
+	if wait <= 0 || wait > t.maxWait {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if until := time.Now().Add(wait); until.After(t.pausedUntil) {
+		t.pausedUntil = until
+	}
+}
+
+// limitTransport bounds the calls in flight. A slot is held until the
+// response headers arrive, not while the body is read.
+type limitTransport struct {
+	next  http.RoundTripper
+	slots chan struct{}
+}
+
+func (t *limitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	select {
+	case t.slots <- struct{}{}:
+	case <-req.Context().Done():
+		return nil, req.Context().Err()
+	}
+	defer func() { <-t.slots }()
+	return t.next.RoundTrip(req)
+}
+
+// isIdempotent reports whether a call with method may be sent twice
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// shouldRetry reports whether a failed attempt is worth repeating: the
+// connection failed, or the server was overloaded or briefly down
+func shouldRetry(resp *http.Response, err error) bool {
+	if err != nil {
+		var netErr net.Error
+		return errors.As(err, &netErr) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) ||
+			errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED)
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// retryAfter reads a Retry-After header given in seconds or as a date
+func retryAfter(header http.Header) (time.Duration, bool) {
+	value := header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(time.Until(date), 0), true
+	}
+	return 0, false
+}
+
+// rateLimitReset reads X-RateLimit-Reset, which proxies send either as a
+// Unix time or as seconds from now
+func rateLimitReset(header http.Header) (time.Duration, bool) {
+	reset, err := strconv.ParseInt(header.Get("X-RateLimit-Reset"), 10, 64)
+	if err != nil || reset < 0 {
+		return 0, false
+	}
+	if reset > 1e9 {
+		return max(time.Until(time.Unix(reset, 0)), 0), true
+	}
+	return time.Duration(reset) * time.Second, true
+}
+
+// sleep waits for d or until ctx is done
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
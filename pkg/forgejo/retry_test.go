@@ -0,0 +1,185 @@
+package forgejo
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testRetryClient is a client whose retries wait milliseconds
+func testRetryClient(maxRetries int) *http.Client {
+	return &http.Client{Transport: &retryTransport{
+		next:       http.DefaultTransport,
+		maxRetries: maxRetries,
+		baseDelay:  time.Millisecond,
+		maxDelay:   5 * time.Millisecond,
+		maxWait:    time.Second,
+	}}
+}
+
+// flakyServer fails the first failures calls with status, then answers 200
+// with the request body
+func flakyServer(t *testing.T, failures int, status int) (*httptest.Server, *atomic.Int32) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if int(calls.Add(1)) <= failures {
+			w.WriteHeader(status)
+			return
+		}
+		io.Copy(w, r.Body)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &calls
+}
+
+// TestRetryTransport_Idempotent retries GET and PUT, replaying the body
+func TestRetryTransport_Idempotent(t *testing.T) {
+	srv, calls := flakyServer(t, 2, http.StatusServiceUnavailable)
+	resp, err := testRetryClient(3).Get(srv.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, int32(3), calls.Load())
+
+	srv, calls = flakyServer(t, 1, http.StatusBadGateway)
+	req, _ := http.NewRequest(http.MethodPut, srv.URL, bytes.NewReader([]byte("topic")))
+	resp, err = testRetryClient(3).Do(req)
+	require.NoError(t, err)
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.Equal(t, "topic", string(body))
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+// TestRetryTransport_GivesUp stops after maxRetries and never retries POST
+// or client errors
+func TestRetryTransport_GivesUp(t *testing.T) {
+	srv, calls := flakyServer(t, 10, http.StatusInternalServerError)
+	resp, err := testRetryClient(2).Get(srv.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+	assert.Equal(t, int32(3), calls.Load())
+
+	srv, calls = flakyServer(t, 1, http.StatusBadGateway)
+	resp, err = testRetryClient(3).Post(srv.URL, "application/json", bytes.NewReader([]byte("{}")))
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusBadGateway, resp.StatusCode)
+	assert.Equal(t, int32(1), calls.Load())
+
+	srv, calls = flakyServer(t, 1, http.StatusNotFound)
+	resp, err = testRetryClient(3).Get(srv.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+// TestRetryTransport_ConnectionError retries a dropped connection
+func TestRetryTransport_ConnectionError(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			if conn, _, err := w.(http.Hijacker).Hijack(); err == nil {
+				conn.Close()
+			}
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	resp, err := testRetryClient(3).Get(srv.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+// TestRetryTransport_RetryAfter waits out short Retry-After answers and
+// hands long ones to the caller
+func TestRetryTransport_RetryAfter(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set("Retry-After", r.URL.Query().Get("after"))
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer srv.Close()
+
+	resp, err := testRetryClient(3).Get(srv.URL + "?after=3600")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+	assert.Equal(t, int32(1), calls.Load())
+
+	calls.Store(0)
+	start := time.Now()
+	resp, err = testRetryClient(1).Get(srv.URL + "?after=1")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, int32(2), calls.Load())
+	assert.GreaterOrEqual(t, time.Since(start), 900*time.Millisecond)
+}
+
+// TestRetryTransport_RateLimitHeaders pauses calls while the rate limit is
+// exhausted
+func TestRetryTransport_RateLimitHeaders(t *testing.T) {
+	transport := &retryTransport{maxWait: time.Minute}
+	header := http.Header{}
+	header.Set("X-RateLimit-Remaining", "0")
+	header.Set("X-RateLimit-Reset", strconv.FormatInt(time.Now().Add(20*time.Second).Unix(), 10))
+	transport.notePause(&http.Response{StatusCode: http.StatusOK, Header: header})
+	assert.InDelta(t, 20*time.Second, transport.pause(), float64(2*time.Second))
+
+	// Remaining calls and resets beyond maxWait do not pause
+	transport = &retryTransport{maxWait: time.Minute}
+	header.Set("X-RateLimit-Remaining", "5")
+	transport.notePause(&http.Response{StatusCode: http.StatusOK, Header: header})
+	assert.Zero(t, transport.pause())
+	header.Set("X-RateLimit-Remaining", "0")
+	header.Set("X-RateLimit-Reset", "3600")
+	transport.notePause(&http.Response{StatusCode: http.StatusOK, Header: header})
+	assert.Zero(t, transport.pause())
+}
+
+// TestLimitTransport keeps at most the configured calls in flight
+func TestLimitTransport(t *testing.T) {
+	var inFlight, peak atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := inFlight.Add(1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		inFlight.Add(-1)
+	}))
+	defer srv.Close()
+
+	client := &http.Client{Transport: &limitTransport{next: http.DefaultTransport, slots: make(chan struct{}, 2)}}
+	var wg sync.WaitGroup
+	for range 6 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := client.Get(srv.URL)
+			if assert.NoError(t, err) {
+				resp.Body.Close()
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(2), peak.Load())
+}
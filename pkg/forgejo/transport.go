@@ -0,0 +1,181 @@
+package forgejo
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"codeberg.org/goern/forgejo-mcp/v2/pkg/flag"
+)
+
+// maxErrorBody bounds how much of a failed response is read for its message
+const maxErrorBody = 64 << 10
+
+// Defaults of the HTTP client settings when no flag sets them
+const (
+	DefaultTimeout        = 30 * time.Second
+	DefaultMaxRetries     = 3
+	DefaultMaxConcurrency = 4
+	DefaultCacheSize      = 500
+)
+
+var (
+	httpClient     *http.Client
+	httpClientOnce sync.Once
+)
+
+// HTTPClient returns the client shared by the SDK client and the raw API
+// helpers, configured from the timeout, retry, concurrency and cache flags
+func HTTPClient() *http.Client {
+	httpClientOnce.Do(func() {
+		httpClient = newHTTPClient(flag.Timeout, flag.MaxRetries, flag.MaxConcurrency, flag.CacheSize)
+	})
+	return httpClient
+}
+
+// newHTTPClient stacks the transports: timeout bounds a whole call, cached
+// responses are served before any request is sent, errors are rewritten once
+// retries gave up, and every attempt waits for a concurrency slot. A
+// cacheSize of 0 disables the cache.
+func newHTTPClient(timeout time.Duration, maxRetries, maxConcurrency, cacheSize int) *http.Client {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	if maxConcurrency <= 0 {
+		maxConcurrency = DefaultMaxConcurrency
+	}
+	var next http.RoundTripper = &errorTransport{
+		next: &retryTransport{
+			next: &limitTransport{
+				next:  http.DefaultTransport,
+				slots: make(chan struct{}, maxConcurrency),
+			},
+			maxRetries: max(maxRetries, 0),
+			baseDelay:  retryBaseDelay,
+			maxDelay:   retryMaxDelay,
+			maxWait:    retryMaxWait,
+		},
+	}
+	if cacheSize > 0 {
+		next = newCacheTransport(next, cacheSize)
+	}
+	return &http.Client{
+		Transport: &timeoutTransport{
+			timeout: timeout,
+			next:    next,
+		},
+	}
+}
+
+type callTimeoutKey struct{}
+
+// WithCallTimeout returns a context whose Forgejo API calls may each take
+// up to timeout instead of the configured one, for tools such as
+// migrations whose single call runs long
+func WithCallTimeout(ctx context.Context, timeout time.Duration) context.Context {
+	return context.WithValue(ctx, callTimeoutKey{}, timeout)
+}
+
+// timeoutTransport bounds each call, retries and reading the body
+// included, by the configured timeout or the one set with WithCallTimeout
+type timeoutTransport struct {
+	next    http.RoundTripper
+	timeout time.Duration
+}
+
+func (t *timeoutTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	timeout := t.timeout
+	if d, ok := req.Context().Value(callTimeoutKey{}).(time.Duration); ok && d > 0 {
+		timeout = d
+	}
+	ctx, cancel := context.WithTimeout(req.Context(), timeout)
+	resp, err := t.next.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	resp.Body = &cancelBody{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+// cancelBody releases the call's timeout once the body is closed
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelBody) Close() error {
+	defer b.cancel()
+	return b.ReadCloser.Close()
+}
+
+// errorTransport rewrites the body of failed responses to a JSON message
+// that starts with the HTTP status and carries Forgejo's field errors. The
+// SDK builds its errors from the message alone, so this is what lets tool
+// errors be told apart by status.
+type errorTransport struct {
+	next http.RoundTripper
+}
+
+func (t *errorTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.next.RoundTrip(req)
+	if err != nil || resp.StatusCode < 400 {
+		return resp, err
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	resp.Body.Close()
+	if err != nil {
+		data = nil
+	}
+	body, _ := json.Marshal(map[string]string{"message": errorMessage(resp, data)})
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	resp.ContentLength = int64(len(body))
+	resp.Header.Set("Content-Type", "application/json")
+	resp.Header.Set("Content-Length", strconv.Itoa(len(body)))
+	return resp, nil
+}
+
+// errorMessage formats a failed response as "404 Not Found: message",
+// appending the field errors Forgejo lists apart from the message and the
+// Retry-After delay of throttled responses
+func errorMessage(resp *http.Response, data []byte) string {
+	msg := fmt.Sprintf("%d %s", resp.StatusCode, http.StatusText(resp.StatusCode))
+
+	var apiErr struct {
+		Message string   `json:"message"`
+		Errors  []string `json:"errors"`
+	}
+	var details []string
+	if json.Unmarshal(data, &apiErr) == nil {
+		if apiErr.Message != "" {
+			details = append(details, apiErr.Message)
+		}
+		for _, e := range apiErr.Errors {
+			if e != "" && !strings.Contains(apiErr.Message, e) {
+				details = append(details, e)
+			}
+		}
+	} else if text := strings.TrimSpace(string(data)); text != "" && !strings.HasPrefix(text, "<") && len(text) <= 200 {
+		// Plain text answers are kept, HTML error pages of proxies are not
+		details = append(details, text)
+	}
+	if len(details) > 0 {
+		msg += ": " + strings.Join(details, "; ")
+	}
+
+	if after := resp.Header.Get("Retry-After"); after != "" {
+		if _, err := strconv.Atoi(after); err == nil {
+			after += "s"
+		}
+		msg += fmt.Sprintf(" (retry after %s)", after)
+	}
+	return msg
+}
@@ -0,0 +1,73 @@
+package forgejo
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"codeberg.org/goern/forgejo-mcp/v2/pkg/flag"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestErrorTransport verifies failed responses reach callers with the
+// status and Forgejo's messages in the error text
+func TestErrorTransport(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/validation":
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			w.Write([]byte(`{"message":"[Title]: Required","errors":["title is empty"],"url":"https://forgejo.example.org/api/swagger"}`))
+		case "/api/v1/throttled":
+			w.Header().Set("Retry-After", "120")
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte("<html>slow down</html>"))
+		default:
+			w.Write([]byte(`{"ok":true}`))
+		}
+	}))
+	defer srv.Close()
+	defer func(url string) { flag.URL = url }(flag.URL)
+	flag.URL = srv.URL
+
+	_, err := Do(context.Background(), "POST", "/validation", nil, nil, nil)
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusUnprocessableEntity, apiErr.StatusCode)
+	assert.EqualError(t, err, "422 Unprocessable Entity: [Title]: Required; title is empty")
+
+	_, err = Do(context.Background(), "GET", "/throttled", nil, nil, nil)
+	assert.EqualError(t, err, "429 Too Many Requests (retry after 120s)")
+
+	var out struct{ OK bool }
+	_, err = Do(context.Background(), "GET", "/ok", nil, nil, &out)
+	require.NoError(t, err)
+	assert.True(t, out.OK)
+}
+
+// TestTimeoutTransport bounds calls by the configured timeout unless the
+// context asks for a longer one
+func TestTimeoutTransport(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(100 * time.Millisecond)
+		w.Write([]byte("done"))
+	}))
+	defer srv.Close()
+	client := &http.Client{Transport: &timeoutTransport{next: http.DefaultTransport, timeout: 20 * time.Millisecond}}
+
+	req, _ := http.NewRequest("GET", srv.URL, nil)
+	_, err := client.Do(req)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	req, _ = http.NewRequestWithContext(WithCallTimeout(context.Background(), time.Second), "GET", srv.URL, nil)
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	require.NoError(t, err)
+	assert.Equal(t, "done", string(body))
+}
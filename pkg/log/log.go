@@ -6,6 +6,7 @@ import (
 	"encoding/hex"
 	"net/url"
 	"os"
+	"strings"
 	"sync"
 	"time"
 
@@ -168,6 +169,23 @@ func SanitizeURL(rawURL string) string {
 	return parsedURL.String()
 }
 
+// RedactedValue replaces sensitive values in logs and tool results
+const RedactedValue = "[redacted]"
+
+// sensitiveKeyParts lists key fragments whose values must never be logged
+var sensitiveKeyParts = []string{"token", "password", "secret", "authorization"}
+
+// IsSensitiveKey reports whether values stored under key must be redacted
+func IsSensitiveKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, part := range sensitiveKeyParts {
+		if strings.Contains(key, part) {
+			return true
+		}
+	}
+	return false
+}
+
 // Context-aware logging functions
 func DebugCtx(ctx context.Context, msg string, fields ...zap.Field) {
 	fields = append(GetContextFields(ctx), fields...)
@@ -250,8 +268,8 @@ func LogMCPToolStart(ctx context.Context, toolName string, params map[string]int
 
 	// Add sanitized parameters (be careful not to log sensitive data)
 	for key, value := range params {
-		if key == "token" || key == "password" || key == "secret" {
-			fields = append(fields, StringField(key, "[redacted]"))
+		if IsSensitiveKey(key) {
+			fields = append(fields, StringField(key, RedactedValue))
 		} else {
 			fields = append(fields, zap.Any(key, value))
 		}
@@ -0,0 +1,156 @@
+// Package paginate reads the page, limit and all arguments of list tools
+// and turns Forgejo list responses into to.Page results.
+package paginate
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"codeberg.org/goern/forgejo-mcp/v2/pkg/flag"
+	"codeberg.org/goern/forgejo-mcp/v2/pkg/to"
+
+	forgejo_sdk "codeberg.org/mvdkleijn/forgejo-sdk/forgejo/v2"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// DefaultMaxItems caps all=true results when no --max-items is configured
+const DefaultMaxItems = 1000
+
+// Options are the paging arguments of a list tool call
+type Options struct {
+	Page  int
+	Limit int
+	// All follows pages from Page until the last one or the item cap
+	All bool
+	// Max is the item cap of All for callers with a fixed bound; zero
+	// uses MaxItems
+	Max int
+}
+
+// FromRequest reads the page, limit and all arguments, defaulting to the
+// first page of defaultLimit items
+func FromRequest(req mcp.CallToolRequest, defaultLimit int) (Options, error) {
+	page := req.GetFloat("page", 1)
+	if page < 1 || page != math.Trunc(page) {
+		return Options{}, to.InvalidArgument("page must be a positive integer")
+	}
+	limit := req.GetFloat("limit", float64(defaultLimit))
+	if limit < 1 || limit != math.Trunc(limit) {
+		return Options{}, to.InvalidArgument("limit must be a positive integer")
+	}
+	return Options{Page: int(page), Limit: int(limit), All: req.GetBool("all", false)}, nil
+}
+
+// ListOptions returns the SDK list options of the first requested page
+func (o Options) ListOptions() forgejo_sdk.ListOptions {
+	return forgejo_sdk.ListOptions{Page: o.Page, PageSize: o.Limit}
+}
+
+// MaxItems is the configured item cap of all=true results
+func MaxItems() int {
+	if flag.MaxItems > 0 {
+		return flag.MaxItems
+	}
+	return DefaultMaxItems
+}
+
+// Fetch returns the requested page, or with All every whole page that fits
+// in Max or MaxItems items; the first page is always returned whole. fetch is called with the page number and page size and returns
+// the items and the HTTP response, whose X-Total-Count and Link headers
+// tell whether more pages follow. Following pages stops when ctx is done.
+func Fetch[T any](ctx context.Context, opt Options, fetch func(page, limit int) ([]T, *http.Response, error)) (*to.Page[T], error) {
+	result := &to.Page[T]{Items: []T{}, Page: opt.Page, Limit: opt.Limit}
+	maxItems := opt.Max
+	if maxItems <= 0 {
+		maxItems = MaxItems()
+	}
+
+	for page := opt.Page; ; page++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		items, resp, err := fetch(page, opt.Limit)
+		if err != nil {
+			return nil, err
+		}
+		if total, ok := totalCount(resp); ok {
+			result.Total = &total
+		}
+		if opt.All && page > opt.Page && len(result.Items)+len(items) > maxItems {
+			// Stop before a page that would pass the cap so that next_page
+			// resumes without repeating items
+			result.HasMore = true
+			result.NextPage = &page
+			return result, nil
+		}
+		result.Items = append(result.Items, items...)
+		more := hasMore(resp, page, opt.Limit, len(items), result.Total)
+		next := page + 1
+
+		if !more {
+			return result, nil
+		}
+		if !opt.All || len(result.Items) >= maxItems {
+			result.HasMore = true
+			result.NextPage = &next
+			return result, nil
+		}
+	}
+}
+
+// List is Fetch for SDK list calls, which take the page as ListOptions
+func List[T any](ctx context.Context, opt Options, fetch func(forgejo_sdk.ListOptions) ([]T, *forgejo_sdk.Response, error)) (*to.Page[T], error) {
+	return Fetch(ctx, opt, func(page, limit int) ([]T, *http.Response, error) {
+		items, resp, err := fetch(forgejo_sdk.ListOptions{Page: page, PageSize: limit})
+		if resp == nil {
+			return items, nil, err
+		}
+		return items, resp.Response, err
+	})
+}
+
+// Query returns a copy of query with the page and limit parameters set, for
+// raw API calls
+func Query(query url.Values, page, limit int) url.Values {
+	out := url.Values{}
+	for key, values := range query {
+		out[key] = append([]string(nil), values...)
+	}
+	out.Set("page", strconv.Itoa(page))
+	out.Set("limit", strconv.Itoa(limit))
+	return out
+}
+
+// totalCount reads the X-Total-Count header
+func totalCount(resp *http.Response) (int, bool) {
+	if resp == nil {
+		return 0, false
+	}
+	total, err := strconv.Atoi(resp.Header.Get("X-Total-Count"))
+	if err != nil || total < 0 {
+		return 0, false
+	}
+	return total, true
+}
+
+// hasMore decides whether pages follow page: from a rel="next" link, which
+// accounts for Forgejo capping the page size, else from the total count,
+// else from whether the page was full
+func hasMore(resp *http.Response, page, limit, count int, total *int) bool {
+	if count == 0 {
+		return false
+	}
+	if resp != nil {
+		if link := resp.Header.Get("Link"); link != "" {
+			return strings.Contains(link, `rel="next"`)
+		}
+	}
+	if total != nil {
+		return page*limit < *total
+	}
+	return count >= limit
+}
@@ -0,0 +1,217 @@
+package paginate
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"codeberg.org/goern/forgejo-mcp/v2/pkg/flag"
+
+	forgejo_sdk "codeberg.org/mvdkleijn/forgejo-sdk/forgejo/v2"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// listEndpoint fakes a list endpoint over total items, answering like
+// Forgejo with X-Total-Count and, when more pages follow, a rel="next" link
+func listEndpoint(total int, headers bool) (fetch func(page, limit int) ([]int, *http.Response, error), calls *[]int) {
+	calls = &[]int{}
+	fetch = func(page, limit int) ([]int, *http.Response, error) {
+		*calls = append(*calls, page)
+		resp := &http.Response{Header: http.Header{}}
+		var items []int
+		for i := (page - 1) * limit; i < page*limit && i < total; i++ {
+			items = append(items, i)
+		}
+		if headers {
+			resp.Header.Set("X-Total-Count", strconv.Itoa(total))
+			if page*limit < total {
+				resp.Header.Set("Link", `<https://example.org/api/v1/x?page=`+strconv.Itoa(page+1)+`>; rel="next"`)
+			}
+		}
+		return items, resp, nil
+	}
+	return fetch, calls
+}
+
+func request(args map[string]interface{}) mcp.CallToolRequest {
+	return mcp.CallToolRequest{Params: mcp.CallToolParams{Arguments: args}}
+}
+
+// TestFromRequest tests defaults and argument validation
+func TestFromRequest(t *testing.T) {
+	opt, err := FromRequest(request(nil), 20)
+	require.NoError(t, err)
+	assert.Equal(t, Options{Page: 1, Limit: 20}, opt)
+
+	opt, err = FromRequest(request(map[string]interface{}{"page": float64(3), "limit": float64(5), "all": true}), 20)
+	require.NoError(t, err)
+	assert.Equal(t, Options{Page: 3, Limit: 5, All: true}, opt)
+	assert.Equal(t, forgejo_sdk.ListOptions{Page: 3, PageSize: 5}, opt.ListOptions())
+
+	for _, args := range []map[string]interface{}{
+		{"page": float64(0)},
+		{"page": 1.5},
+		{"limit": float64(-1)},
+	} {
+		_, err := FromRequest(request(args), 20)
+		assert.Error(t, err, args)
+	}
+}
+
+// TestFetch_Page tests the paging state of a single page
+func TestFetch_Page(t *testing.T) {
+	fetch, calls := listEndpoint(45, true)
+
+	page, err := Fetch(context.Background(), Options{Page: 2, Limit: 20}, fetch)
+	require.NoError(t, err)
+	assert.Len(t, page.Items, 20)
+	assert.Equal(t, 2, page.Page)
+	assert.Equal(t, 20, page.Limit)
+	require.NotNil(t, page.Total)
+	assert.Equal(t, 45, *page.Total)
+	assert.True(t, page.HasMore)
+	require.NotNil(t, page.NextPage)
+	assert.Equal(t, 3, *page.NextPage)
+	assert.Equal(t, []int{2}, *calls)
+
+	page, err = Fetch(context.Background(), Options{Page: 3, Limit: 20}, fetch)
+	require.NoError(t, err)
+	assert.Len(t, page.Items, 5)
+	assert.False(t, page.HasMore)
+	assert.Nil(t, page.NextPage)
+}
+
+// TestFetch_NoHeaders falls back to whether the page was full
+func TestFetch_NoHeaders(t *testing.T) {
+	fetch, _ := listEndpoint(20, false)
+
+	page, err := Fetch(context.Background(), Options{Page: 1, Limit: 10}, fetch)
+	require.NoError(t, err)
+	assert.Nil(t, page.Total)
+	assert.True(t, page.HasMore)
+
+	page, err = Fetch(context.Background(), Options{Page: 3, Limit: 10}, fetch)
+	require.NoError(t, err)
+	assert.Empty(t, page.Items)
+	assert.NotNil(t, page.Items)
+	assert.False(t, page.HasMore)
+}
+
+// TestFetch_All follows pages to the end
+func TestFetch_All(t *testing.T) {
+	fetch, calls := listEndpoint(45, true)
+
+	page, err := Fetch(context.Background(), Options{Page: 1, Limit: 20, All: true}, fetch)
+	require.NoError(t, err)
+	assert.Len(t, page.Items, 45)
+	assert.Equal(t, 44, page.Items[44])
+	assert.False(t, page.HasMore)
+	assert.Nil(t, page.NextPage)
+	assert.Equal(t, []int{1, 2, 3}, *calls)
+}
+
+// TestFetch_AllCap stops at the last whole page within the item cap
+func TestFetch_AllCap(t *testing.T) {
+	defer func(maxItems int) { flag.MaxItems = maxItems }(flag.MaxItems)
+
+	flag.MaxItems = 25
+	fetch, calls := listEndpoint(100, true)
+	page, err := Fetch(context.Background(), Options{Page: 1, Limit: 10, All: true}, fetch)
+	require.NoError(t, err)
+	assert.Len(t, page.Items, 20)
+	assert.True(t, page.HasMore)
+	require.NotNil(t, page.NextPage)
+	assert.Equal(t, 3, *page.NextPage, "the page past the cap is asked for again")
+	assert.Equal(t, []int{1, 2, 3}, *calls)
+
+	flag.MaxItems = 20
+	fetch, calls = listEndpoint(100, true)
+	page, err = Fetch(context.Background(), Options{Page: 1, Limit: 10, All: true}, fetch)
+	require.NoError(t, err)
+	assert.Len(t, page.Items, 20)
+	assert.Equal(t, 3, *page.NextPage)
+	assert.Equal(t, []int{1, 2}, *calls)
+
+	// A fixed Max wins over the configured cap
+	fetch, _ = listEndpoint(100, true)
+	page, err = Fetch(context.Background(), Options{Page: 1, Limit: 10, All: true, Max: 35}, fetch)
+	require.NoError(t, err)
+	assert.Len(t, page.Items, 30)
+	assert.True(t, page.HasMore)
+
+	// A first page larger than the cap is returned whole
+	fetch, _ = listEndpoint(100, true)
+	page, err = Fetch(context.Background(), Options{Page: 1, Limit: 30, All: true, Max: 10}, fetch)
+	require.NoError(t, err)
+	assert.Len(t, page.Items, 30)
+	assert.Equal(t, 2, *page.NextPage)
+}
+
+// TestFetch_AllCapResume follows next_page after a capped call and gets
+// every item once
+func TestFetch_AllCapResume(t *testing.T) {
+	fetch, _ := listEndpoint(100, true)
+	first, err := Fetch(context.Background(), Options{Page: 1, Limit: 30, All: true, Max: 80}, fetch)
+	require.NoError(t, err)
+	require.True(t, first.HasMore)
+	require.NotNil(t, first.NextPage)
+
+	rest, err := Fetch(context.Background(), Options{Page: *first.NextPage, Limit: 30, All: true, Max: 80}, fetch)
+	require.NoError(t, err)
+	assert.False(t, rest.HasMore)
+
+	seen := map[int]bool{}
+	for _, item := range append(first.Items, rest.Items...) {
+		assert.False(t, seen[item], "item %d returned twice", item)
+		seen[item] = true
+	}
+	assert.Len(t, seen, 100)
+}
+
+// TestFetch_Cancelled stops following pages once the context is done
+func TestFetch_Cancelled(t *testing.T) {
+	fetch, calls := listEndpoint(100, true)
+	ctx, cancel := context.WithCancel(context.Background())
+	_, err := Fetch(ctx, Options{Page: 1, Limit: 10, All: true}, func(page, limit int) ([]int, *http.Response, error) {
+		if page == 2 {
+			cancel()
+		}
+		return fetch(page, limit)
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, []int{1, 2}, *calls)
+}
+
+// TestList tests the SDK adapter, including errors without a response
+func TestList(t *testing.T) {
+	var got forgejo_sdk.ListOptions
+	page, err := List(context.Background(), Options{Page: 2, Limit: 5}, func(lo forgejo_sdk.ListOptions) ([]string, *forgejo_sdk.Response, error) {
+		got = lo
+		resp := &forgejo_sdk.Response{Response: &http.Response{Header: http.Header{"X-Total-Count": {"6"}}}}
+		return []string{"f"}, resp, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, forgejo_sdk.ListOptions{Page: 2, PageSize: 5}, got)
+	assert.Equal(t, 6, *page.Total)
+	assert.False(t, page.HasMore)
+
+	_, err = List(context.Background(), Options{Page: 1, Limit: 5}, func(forgejo_sdk.ListOptions) ([]string, *forgejo_sdk.Response, error) {
+		return nil, nil, errors.New("boom")
+	})
+	assert.EqualError(t, err, "boom")
+}
+
+// TestQuery keeps the filters and leaves the input untouched
+func TestQuery(t *testing.T) {
+	query := url.Values{"q": {"bug"}}
+	out := Query(query, 2, 30)
+	assert.Equal(t, "bug", out.Get("q"))
+	assert.Equal(t, "2", out.Get("page"))
+	assert.Equal(t, "30", out.Get("limit"))
+	assert.False(t, query.Has("page"))
+}
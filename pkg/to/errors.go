@@ -0,0 +1,158 @@
+package to
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"codeberg.org/goern/forgejo-mcp/v2/pkg/log"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// ErrorClass groups tool failures by what the caller can do about them
+type ErrorClass string
+
+const (
+	ClassNotFound         ErrorClass = "not_found"
+	ClassPermissionDenied ErrorClass = "permission_denied"
+	ClassValidation       ErrorClass = "validation"
+	ClassConflict         ErrorClass = "conflict"
+	ClassRateLimited      ErrorClass = "rate_limited"
+	ClassUnavailable      ErrorClass = "upstream_unavailable"
+	ClassTimeout          ErrorClass = "timeout"
+	ClassCancelled        ErrorClass = "cancelled"
+	ClassInternal         ErrorClass = "internal"
+	ClassUnknown          ErrorClass = "error"
+)
+
+// errorHints tells the model how to recover from each class of failure
+var errorHints = map[ErrorClass]string{
+	ClassNotFound:         "Check the owner, repository, number or name; list and search tools show what exists. Forgejo also answers not found when the token cannot see a private resource.",
+	ClassPermissionDenied: "The token's user lacks access to this resource or the token lacks the scope for this call. Retrying will not help; ask the user for access or a token with the needed scope.",
+	ClassValidation:       "Fix the arguments named in the message and call the tool again.",
+	ClassConflict:         "The resource already exists or was changed meanwhile. Fetch its current state, then update it or retry with different values.",
+	ClassRateLimited:      "Forgejo is throttling requests. Wait before retrying and prefer fewer calls, e.g. a higher limit per page.",
+	ClassUnavailable:      "Forgejo could not be reached or failed to answer. Retry later; if it persists, the instance may be down or misconfigured.",
+	ClassTimeout:          "The call ran past its deadline. Narrow it down, e.g. a page instead of all=true or a path for search_code, or retry later.",
+}
+
+var (
+	// statusPattern finds a status such as "404 Not Found" in error text;
+	// the SDK keeps only the message of failed responses, into which the
+	// forgejo package writes the status
+	statusPattern = regexp.MustCompile(`\b[1-5]\d\d `)
+	// fieldPattern finds Forgejo validation messages such as "[Title]: Required"
+	fieldPattern = regexp.MustCompile(`\[([\w.]+)\]:\s*([^;\[]+)`)
+	// argumentPattern matches the errors of the argument getters of
+	// mcp.CallToolRequest, such as `required argument "owner" not found`
+	argumentPattern = regexp.MustCompile(`^(required argument|argument|item \d+ in argument) "`)
+)
+
+// ArgumentError is a missing or malformed tool argument, found by a
+// handler before calling Forgejo
+type ArgumentError struct {
+	Err error
+}
+
+func (e *ArgumentError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ArgumentError) Unwrap() error {
+	return e.Err
+}
+
+// InvalidArgument formats an ArgumentError like fmt.Errorf
+func InvalidArgument(format string, a ...any) error {
+	return &ArgumentError{Err: fmt.Errorf(format, a...)}
+}
+
+// ErrorResult returns err as a tool result with isError set, so the model
+// sees the failure instead of a protocol error. The text names the error
+// class and a remediation hint, and the validation field messages.
+func ErrorResult(err error) (*mcp.CallToolResult, error) {
+	class := ClassifyError(err)
+	log.Error("Tool call failed",
+		log.StringField("class", string(class)),
+		log.ErrorField(err),
+	)
+
+	var text strings.Builder
+	text.WriteString(err.Error())
+	fmt.Fprintf(&text, "\nclass: %s", class)
+	if class == ClassValidation {
+		if fields := fieldPattern.FindAllStringSubmatch(err.Error(), -1); len(fields) > 0 {
+			text.WriteString("\nfields:")
+			for _, field := range fields {
+				fmt.Fprintf(&text, "\n- %s: %s", strings.ToLower(field[1]), strings.TrimSpace(field[2]))
+			}
+		}
+	}
+	if hint, ok := errorHints[class]; ok {
+		fmt.Fprintf(&text, "\nhint: %s", hint)
+	}
+	return mcp.NewToolResultError(text.String()), nil
+}
+
+// ClassifyError sorts err by the HTTP status it carries, else by whether
+// Forgejo could be reached. ArgumentErrors and the errors of the argument
+// getters are validation errors; anything else is internal.
+func ClassifyError(err error) ErrorClass {
+	if status := errorStatus(err.Error()); status != 0 {
+		switch {
+		case status == http.StatusNotFound || status == http.StatusGone:
+			return ClassNotFound
+		case status == http.StatusUnauthorized || status == http.StatusForbidden:
+			return ClassPermissionDenied
+		case status == http.StatusBadRequest || status == http.StatusUnprocessableEntity:
+			return ClassValidation
+		case status == http.StatusConflict || status == http.StatusPreconditionFailed || status == http.StatusLocked:
+			return ClassConflict
+		case status == http.StatusTooManyRequests:
+			return ClassRateLimited
+		case status >= 500:
+			return ClassUnavailable
+		}
+		return ClassUnknown
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		return ClassTimeout
+	}
+	if errors.Is(err, context.Canceled) {
+		return ClassCancelled
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return ClassUnavailable
+	}
+	msg := strings.ToLower(err.Error())
+	for _, s := range []string{"connection refused", "connection reset", "no such host", "i/o timeout", "tls handshake", "unexpected eof", ": eof"} {
+		if strings.Contains(msg, s) {
+			return ClassUnavailable
+		}
+	}
+	var argErr *ArgumentError
+	if errors.As(err, &argErr) || argumentPattern.MatchString(err.Error()) {
+		return ClassValidation
+	}
+	return ClassInternal
+}
+
+// errorStatus returns the first HTTP status written as "<code> <text>" in
+// msg, or 0
+func errorStatus(msg string) int {
+	for _, loc := range statusPattern.FindAllStringIndex(msg, -1) {
+		code, _ := strconv.Atoi(msg[loc[0] : loc[1]-1])
+		if text := http.StatusText(code); text != "" && strings.HasPrefix(msg[loc[1]:], text) {
+			return code
+		}
+	}
+	return 0
+}
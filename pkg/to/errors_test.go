@@ -0,0 +1,62 @@
+package to
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestClassifyError tests classification by status, reachability and
+// argument errors
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		err  error
+		want ErrorClass
+	}{
+		{errors.New("get issue err: 404 Not Found: The target couldn't be found."), ClassNotFound},
+		{errors.New("delete repo err: 403 Forbidden: token does not have at least one of required scope(s)"), ClassPermissionDenied},
+		{errors.New("create label err: 422 Unprocessable Entity: [Color]: invalid"), ClassValidation},
+		{errors.New("create repo err: 409 Conflict: The repository with the same name already exists."), ClassConflict},
+		{errors.New("list repos err: 429 Too Many Requests (retry after 30s)"), ClassRateLimited},
+		{errors.New("list repos err: 502 Bad Gateway"), ClassUnavailable},
+		{fmt.Errorf("get repo err: %w", &net.OpError{Op: "dial", Err: errors.New("connection refused")}), ClassUnavailable},
+		{errors.New(`get repo err: Get "https://forgejo.example.org/api/v1/repos/a/b": dial tcp: connection refused`), ClassUnavailable},
+		{fmt.Errorf("search_code: %w after 5m0s", context.DeadlineExceeded), ClassTimeout},
+		{fmt.Errorf("get_issue: %w", context.Canceled), ClassCancelled},
+		{errors.New(`required argument "owner" not found`), ClassValidation},
+		{errors.New(`argument "index" is not a float64`), ClassValidation},
+		{InvalidArgument("invalid state '%s': must be open or closed", "draft"), ClassValidation},
+		{fmt.Errorf("get_issue: %w", InvalidArgument("owner is required")), ClassValidation},
+		{errors.New("list 404 items err: something broke"), ClassInternal},
+		{errors.New("list repos err: json: cannot unmarshal string into Go value of type int"), ClassInternal},
+		{errors.New("create forgejo client: invalid URL escape"), ClassInternal},
+		{errors.New("list repos err: 418 I'm a teapot"), ClassUnknown},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, ClassifyError(tt.err), tt.err.Error())
+	}
+}
+
+// TestErrorResult verifies failures become isError results with the class,
+// the hint and the validation field messages
+func TestErrorResult(t *testing.T) {
+	result, err := ErrorResult(errors.New("create issue err: 422 Unprocessable Entity: [Title]: Required; [Ref]: MaxSize"))
+	require.NoError(t, err)
+	require.True(t, result.IsError)
+
+	text := result.Content[0].(mcp.TextContent).Text
+	assert.Contains(t, text, "create issue err: 422 Unprocessable Entity")
+	assert.Contains(t, text, "\nclass: validation\n")
+	assert.Contains(t, text, "\nfields:\n- title: Required\n- ref: MaxSize\n")
+	assert.Contains(t, text, "\nhint: Fix the arguments")
+
+	result, err = ErrorResult(errors.New("list 404 items err: something broke"))
+	require.NoError(t, err)
+	assert.NotContains(t, result.Content[0].(mcp.TextContent).Text, "hint:")
+}
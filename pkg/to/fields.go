@@ -0,0 +1,104 @@
+package to
+
+import (
+	"bytes"
+	"encoding/json"
+	"maps"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Fields is a parsed fields argument: each key maps to the fields wanted
+// below it, and an empty subtree keeps the whole value
+type Fields map[string]Fields
+
+// ParseFields parses a comma-separated fields argument such as
+// "number,title,user.login" into a field tree. It returns nil when no
+// fields are given.
+func ParseFields(fields string) Fields {
+	var tree Fields
+	for _, field := range strings.Split(fields, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		if tree == nil {
+			tree = Fields{}
+		}
+		node := tree
+		for _, key := range strings.Split(field, ".") {
+			child, ok := node[key]
+			if !ok {
+				child = Fields{}
+				node[key] = child
+			}
+			node = child
+		}
+	}
+	return tree
+}
+
+// Project keeps only the given fields of each object in v. Lists, and the
+// items of a Page, are projected item by item and other values are returned
+// unchanged.
+func Project(v any, fields Fields) any {
+	if len(fields) == 0 {
+		return v
+	}
+	switch val := v.(type) {
+	case []any:
+		out := make([]any, 0, len(val))
+		for _, item := range val {
+			out = append(out, Project(item, fields))
+		}
+		return out
+	case map[string]any:
+		if isPageObject(val) {
+			out := maps.Clone(val)
+			out["items"] = Project(val["items"], fields)
+			return out
+		}
+		out := map[string]any{}
+		for key, sub := range fields {
+			if item, ok := val[key]; ok {
+				out[key] = Project(item, sub)
+			}
+		}
+		return out
+	}
+	return v
+}
+
+// SelectFields projects the JSON text and structured content of a tool call
+// result onto fields. Error results and results that are not JSON text are
+// returned unchanged.
+func SelectFields(result *mcp.CallToolResult, fields string) *mcp.CallToolResult {
+	tree := ParseFields(fields)
+	if result == nil || result.IsError || tree == nil || len(result.Content) == 0 {
+		return result
+	}
+	text, ok := result.Content[0].(mcp.TextContent)
+	if !ok {
+		return result
+	}
+
+	dec := json.NewDecoder(bytes.NewReader([]byte(text.Text)))
+	dec.UseNumber()
+	var wrapped textResult
+	if err := dec.Decode(&wrapped); err != nil {
+		return result
+	}
+	wrapped.Result = Project(wrapped.Result, tree)
+	data, err := json.Marshal(wrapped)
+	if err != nil {
+		return result
+	}
+
+	projected := *result
+	projected.Content = append([]mcp.Content{mcp.NewTextContent(string(data))}, result.Content[1:]...)
+	if result.StructuredContent != nil {
+		projected.StructuredContent = wrapped
+	}
+	return &projected
+}
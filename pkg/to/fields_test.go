@@ -0,0 +1,64 @@
+package to
+
+import (
+	"testing"
+
+	forgejo_sdk "codeberg.org/mvdkleijn/forgejo-sdk/forgejo/v2"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestParseFields tests parsing of plain and nested field names
+func TestParseFields(t *testing.T) {
+	assert.Nil(t, ParseFields(""))
+	assert.Nil(t, ParseFields(" , "))
+	assert.Equal(t, Fields{
+		"number": {},
+		"user":   {"login": {}, "id": {}},
+	}, ParseFields("number, user.login,user.id"))
+}
+
+// TestProject tests projection of objects, lists and nested values
+func TestProject(t *testing.T) {
+	v := []any{
+		map[string]any{"number": 1, "title": "a", "user": map[string]any{"login": "alice", "id": 1}},
+		map[string]any{"number": 2, "title": "b"},
+		"scalar",
+	}
+
+	assert.Equal(t, []any{
+		map[string]any{"number": 1, "user": map[string]any{"login": "alice"}},
+		map[string]any{"number": 2},
+		"scalar",
+	}, Project(v, ParseFields("number,user.login,missing")))
+}
+
+// TestSelectFields tests projection of a tool result
+func TestSelectFields(t *testing.T) {
+	result, err := TextResult([]*forgejo_sdk.Label{{ID: 12345678901, Name: "bug", Color: "ee0701"}})
+	assert.NoError(t, err)
+
+	projected := SelectFields(result, "id,name")
+	assert.Equal(t, `{"Result":[{"id":12345678901,"name":"bug"}]}`, projected.Content[0].(mcp.TextContent).Text)
+
+	// The original result is left alone and empty fields are a no-op
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "ee0701")
+	assert.Same(t, result, SelectFields(result, ""))
+}
+
+// TestSelectFields_Page tests that pages project their items and keep the
+// paging state
+func TestSelectFields_Page(t *testing.T) {
+	total := 2
+	result, err := TextResult(&Page[*forgejo_sdk.Label]{
+		Items: []*forgejo_sdk.Label{{ID: 1, Name: "bug", Color: "ee0701"}},
+		Page:  1,
+		Limit: 1,
+		Total: &total,
+	})
+	assert.NoError(t, err)
+
+	projected := SelectFields(result, "name")
+	assert.Equal(t, `{"Result":{"has_more":false,"items":[{"name":"bug"}],"limit":1,"next_page":null,"page":1,"total":2}}`,
+		projected.Content[0].(mcp.TextContent).Text)
+}
@@ -0,0 +1,358 @@
+package to
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const (
+	FormatJSON     = "json"
+	FormatMarkdown = "markdown"
+
+	// maxCellLen bounds table cells; longer values are cut
+	maxCellLen = 80
+	// maxInlineLen is the longest string shown as a bullet rather than a
+	// section of its own
+	maxInlineLen = 120
+)
+
+// object is a decoded JSON object that keeps its key order, so rendered
+// columns and fields follow the order of the underlying struct
+type object struct {
+	keys   []string
+	values map[string]any
+}
+
+// decodeOrdered decodes the next JSON value, keeping object key order
+func decodeOrdered(dec *json.Decoder) (any, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch t := tok.(type) {
+	case json.Delim:
+		switch t {
+		case '{':
+			obj := &object{values: map[string]any{}}
+			for dec.More() {
+				keyTok, err := dec.Token()
+				if err != nil {
+					return nil, err
+				}
+				key := keyTok.(string)
+				value, err := decodeOrdered(dec)
+				if err != nil {
+					return nil, err
+				}
+				if _, seen := obj.values[key]; !seen {
+					obj.keys = append(obj.keys, key)
+				}
+				obj.values[key] = value
+			}
+			_, err := dec.Token()
+			return obj, err
+		case '[':
+			list := []any{}
+			for dec.More() {
+				value, err := decodeOrdered(dec)
+				if err != nil {
+					return nil, err
+				}
+				list = append(list, value)
+			}
+			_, err := dec.Token()
+			return list, err
+		}
+	}
+	return tok, nil
+}
+
+// RenderMarkdown replaces the JSON text of a tool result with Markdown:
+// tables for lists and pages, headed sections for single entities and for
+// lists of comments. Structured content and any further content are kept. Error
+// results and results that are not JSON text are returned unchanged.
+func RenderMarkdown(result *mcp.CallToolResult) *mcp.CallToolResult {
+	if result == nil || result.IsError || len(result.Content) == 0 {
+		return result
+	}
+	text, ok := result.Content[0].(mcp.TextContent)
+	if !ok {
+		return result
+	}
+
+	dec := json.NewDecoder(bytes.NewReader([]byte(text.Text)))
+	dec.UseNumber()
+	decoded, err := decodeOrdered(dec)
+	if err != nil {
+		return result
+	}
+	v := any(decoded)
+	if obj, ok := decoded.(*object); ok && len(obj.keys) == 1 && obj.keys[0] == "Result" {
+		v = obj.values["Result"]
+	}
+
+	rendered := *result
+	rendered.Content = append([]mcp.Content{mcp.NewTextContent(Markdown(v))}, result.Content[1:]...)
+	return &rendered
+}
+
+// Markdown renders a value decoded by decodeOrdered
+func Markdown(v any) string {
+	var b strings.Builder
+	writeMarkdown(&b, v, 2)
+	return strings.TrimSpace(b.String()) + "\n"
+}
+
+func writeMarkdown(b *strings.Builder, v any, level int) {
+	switch val := v.(type) {
+	case *object:
+		if isPageObject(val.values) {
+			items, _ := val.values["items"].([]any)
+			writeList(b, items, level)
+			if footer := pageFooter(val); footer != "" {
+				fmt.Fprintf(b, "\n_%s_\n", footer)
+			}
+			return
+		}
+		if title := entityTitle(val); title != "" {
+			fmt.Fprintf(b, "%s %s\n\n", heading(level), title)
+		}
+		writeFields(b, val, level+1)
+	case []any:
+		writeList(b, val, level)
+	default:
+		b.WriteString(scalar(val))
+		b.WriteString("\n")
+	}
+}
+
+// writeFields writes short scalars as a bullet list and long text, nested
+// objects and lists of objects as sections
+func writeFields(b *strings.Builder, obj *object, level int) {
+	var sections []string
+	for _, key := range obj.keys {
+		switch val := obj.values[key].(type) {
+		case nil:
+		case *object:
+			sections = append(sections, key)
+		case []any:
+			if len(val) == 0 {
+				continue
+			}
+			if isScalarList(val) {
+				fmt.Fprintf(b, "- **%s**: %s\n", key, joinScalars(val))
+			} else {
+				sections = append(sections, key)
+			}
+		case string:
+			if val == "" {
+				continue
+			}
+			if isLongText(val) {
+				sections = append(sections, key)
+			} else {
+				fmt.Fprintf(b, "- **%s**: %s\n", key, val)
+			}
+		default:
+			fmt.Fprintf(b, "- **%s**: %s\n", key, scalar(val))
+		}
+	}
+
+	for _, key := range sections {
+		fmt.Fprintf(b, "\n%s %s\n\n", heading(level), key)
+		switch val := obj.values[key].(type) {
+		case string:
+			b.WriteString(val)
+			b.WriteString("\n")
+		case *object:
+			writeFields(b, val, level+1)
+		case []any:
+			writeList(b, val, level+1)
+		}
+	}
+}
+
+// writeList writes a table, or one section per item for comment-like
+// objects that carry a body
+func writeList(b *strings.Builder, list []any, level int) {
+	if len(list) == 0 {
+		b.WriteString("_No results_\n")
+		return
+	}
+	if isScalarList(list) {
+		for _, item := range list {
+			fmt.Fprintf(b, "- %s\n", scalar(item))
+		}
+		return
+	}
+
+	var items []*object
+	hasBody := false
+	for _, item := range list {
+		obj, ok := item.(*object)
+		if !ok {
+			continue
+		}
+		items = append(items, obj)
+		if _, ok := obj.values["body"]; ok {
+			hasBody = true
+		}
+	}
+
+	if hasBody {
+		for i, obj := range items {
+			title := entityTitle(obj)
+			if title == "" {
+				title = fmt.Sprintf("%d", i+1)
+			}
+			fmt.Fprintf(b, "%s %s\n\n", heading(level), title)
+			writeFields(b, obj, level+1)
+			b.WriteString("\n")
+		}
+		return
+	}
+	writeTable(b, items)
+}
+
+func writeTable(b *strings.Builder, items []*object) {
+	var columns []string
+	seen := map[string]bool{}
+	for _, obj := range items {
+		for _, key := range obj.keys {
+			if !seen[key] {
+				seen[key] = true
+				columns = append(columns, key)
+			}
+		}
+	}
+
+	b.WriteString("| " + strings.Join(columns, " | ") + " |\n")
+	b.WriteString("|" + strings.Repeat(" --- |", len(columns)) + "\n")
+	for _, obj := range items {
+		cells := make([]string, 0, len(columns))
+		for _, key := range columns {
+			cells = append(cells, cell(obj.values[key]))
+		}
+		b.WriteString("| " + strings.Join(cells, " | ") + " |\n")
+	}
+}
+
+// entityTitle picks a heading for an object, e.g. "#7 Crash on start" for
+// issues, the full name of repositories or the login of users
+func entityTitle(obj *object) string {
+	str := func(key string) string {
+		s, _ := obj.values[key].(string)
+		return s
+	}
+	title := str("title")
+	if number, ok := obj.values["number"].(json.Number); ok {
+		return strings.TrimSpace(fmt.Sprintf("#%s %s", number, title))
+	}
+	for _, candidate := range []string{title, str("full_name"), str("name"), str("login")} {
+		if candidate != "" {
+			return candidate
+		}
+	}
+	if user := str("user"); user != "" {
+		if created := str("created_at"); created != "" {
+			return user + ", " + created
+		}
+		return user
+	}
+	if sha := str("sha"); sha != "" {
+		if len(sha) > 10 {
+			sha = sha[:10]
+		}
+		return sha
+	}
+	return ""
+}
+
+// pageFooter describes the paging state of a Page, e.g. "Page 1 · 20 of 57
+// · next page 2"
+func pageFooter(page *object) string {
+	var parts []string
+	if p, ok := page.values["page"].(json.Number); ok {
+		parts = append(parts, "Page "+p.String())
+	}
+	items, _ := page.values["items"].([]any)
+	if total, ok := page.values["total"].(json.Number); ok {
+		parts = append(parts, fmt.Sprintf("%d of %s", len(items), total))
+	} else {
+		parts = append(parts, fmt.Sprintf("%d items", len(items)))
+	}
+	if next, ok := page.values["next_page"].(json.Number); ok {
+		parts = append(parts, "next page "+next.String())
+	}
+	return strings.Join(parts, " · ")
+}
+
+func heading(level int) string {
+	if level > 6 {
+		level = 6
+	}
+	return strings.Repeat("#", level)
+}
+
+func isLongText(s string) bool {
+	return strings.Contains(s, "\n") || len(s) > maxInlineLen
+}
+
+func isScalarList(list []any) bool {
+	for _, item := range list {
+		switch item.(type) {
+		case *object, []any:
+			return false
+		}
+	}
+	return true
+}
+
+func joinScalars(list []any) string {
+	parts := make([]string, 0, len(list))
+	for _, item := range list {
+		parts = append(parts, scalar(item))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func scalar(v any) string {
+	switch val := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return val
+	case bool:
+		if val {
+			return "yes"
+		}
+		return "no"
+	}
+	return fmt.Sprint(v)
+}
+
+// cell formats a value for a table cell on a single line
+func cell(v any) string {
+	var s string
+	switch val := v.(type) {
+	case *object:
+		s = entityTitle(val)
+	case []any:
+		if isScalarList(val) {
+			s = joinScalars(val)
+		} else {
+			s = fmt.Sprintf("%d items", len(val))
+		}
+	default:
+		s = scalar(val)
+	}
+	s = strings.Join(strings.Fields(s), " ")
+	if runes := []rune(s); len(runes) > maxCellLen {
+		s = string(runes[:maxCellLen-1]) + "…"
+	}
+	return strings.ReplaceAll(s, "|", `\|`)
+}
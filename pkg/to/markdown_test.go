@@ -0,0 +1,89 @@
+package to
+
+import (
+	"testing"
+
+	forgejo_sdk "codeberg.org/mvdkleijn/forgejo-sdk/forgejo/v2"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+)
+
+func markdownOf(t *testing.T, v any) string {
+	t.Helper()
+	result, err := TextResult(v)
+	assert.NoError(t, err)
+	rendered := RenderMarkdown(result)
+	assert.Equal(t, result.StructuredContent, rendered.StructuredContent)
+	return rendered.Content[0].(mcp.TextContent).Text
+}
+
+// TestRenderMarkdown_List verifies lists become tables in field order
+func TestRenderMarkdown_List(t *testing.T) {
+	labels := []*forgejo_sdk.Label{
+		{ID: 1, Name: "bug", Color: "ee0701", Description: "Something | broken"},
+		{ID: 2, Name: "docs", Color: "0052cc"},
+	}
+
+	assert.Equal(t, "| id | name | color | description |\n"+
+		"| --- | --- | --- | --- |\n"+
+		"| 1 | bug | ee0701 | Something \\| broken |\n"+
+		"| 2 | docs | 0052cc |  |\n", markdownOf(t, labels))
+}
+
+// TestRenderMarkdown_Entity verifies single entities get a heading and
+// long text its own section
+func TestRenderMarkdown_Entity(t *testing.T) {
+	issue := sampleIssue()
+	issue.Body = "Steps:\n1. start"
+
+	text := markdownOf(t, issue)
+	assert.Contains(t, text, "## #7 Crash on start\n\n")
+	assert.Contains(t, text, "- **state**: open\n")
+	assert.Contains(t, text, "- **labels**: bug\n")
+	assert.Contains(t, text, "### body\n\nSteps:\n1. start\n")
+}
+
+// TestRenderMarkdown_Comments verifies items with a body become sections
+func TestRenderMarkdown_Comments(t *testing.T) {
+	comments := []map[string]any{
+		{"id": 1, "user": "alice", "created_at": "2026-10-01", "body": "First"},
+		{"id": 2, "user": "bob", "created_at": "2026-10-02", "body": "Second"},
+	}
+
+	text := markdownOf(t, comments)
+	assert.Contains(t, text, "## bob, 2026-10-02\n\n")
+	assert.Contains(t, text, "- **body**: First\n")
+	assert.NotContains(t, text, "| --- |")
+}
+
+// TestRenderMarkdown_Scalars verifies plain messages and empty lists
+func TestRenderMarkdown_Scalars(t *testing.T) {
+	assert.Equal(t, "Delete label success\n", markdownOf(t, "Delete label success"))
+	assert.Equal(t, "_No results_\n", markdownOf(t, []*forgejo_sdk.Label{}))
+}
+
+// TestTextResult_StructuredContent verifies results carry structured content
+func TestTextResult_StructuredContent(t *testing.T) {
+	result, err := TextResult(&forgejo_sdk.Label{ID: 1, Name: "bug"})
+	assert.NoError(t, err)
+	assert.Equal(t, textResult{&LabelView{ID: 1, Name: "bug"}}, result.StructuredContent)
+
+	projected := SelectFields(result, "name")
+	assert.Equal(t, textResult{map[string]any{"name": "bug"}}, projected.StructuredContent)
+}
+
+// TestRenderMarkdown_Page verifies pages render as a table with a footer
+func TestRenderMarkdown_Page(t *testing.T) {
+	total, next := 30, 2
+	text := markdownOf(t, &Page[*forgejo_sdk.Label]{
+		Items:    []*forgejo_sdk.Label{{ID: 1, Name: "bug", Color: "ee0701"}},
+		Page:     1,
+		Limit:    1,
+		Total:    &total,
+		HasMore:  true,
+		NextPage: &next,
+	})
+	assert.Contains(t, text, "| id | name | color |\n")
+	assert.Contains(t, text, "| 1 | bug | ee0701 |\n")
+	assert.Contains(t, text, "_Page 1 · 1 of 30 · next page 2_\n")
+}
@@ -0,0 +1,68 @@
+package to
+
+import (
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+	"unicode/utf8"
+)
+
+// sourceMIMETypes cover extensions of common repository files that the
+// mime package does not know
+var sourceMIMETypes = map[string]string{
+	".md":       "text/markdown",
+	".markdown": "text/markdown",
+	".go":       "text/x-go",
+	".py":       "text/x-python",
+	".rs":       "text/x-rust",
+	".java":     "text/x-java",
+	".c":        "text/x-c",
+	".h":        "text/x-c",
+	".sh":       "text/x-shellscript",
+	".ts":       "text/typescript",
+	".yaml":     "application/yaml",
+	".yml":      "application/yaml",
+	".toml":     "application/toml",
+	".txt":      "text/plain",
+}
+
+// MIMEType returns the media type of a file or attachment: the server's
+// contentType unless it is empty or generic, then the type of the file
+// extension, then content sniffing
+func MIMEType(name, contentType string, data []byte) string {
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil && mediaType != "application/octet-stream" {
+		return mediaType
+	}
+	ext := strings.ToLower(path.Ext(name))
+	if mediaType, ok := sourceMIMETypes[ext]; ok {
+		return mediaType
+	}
+	if byExt := mime.TypeByExtension(ext); byExt != "" {
+		mediaType, _, _ := mime.ParseMediaType(byExt)
+		return mediaType
+	}
+	mediaType, _, _ := mime.ParseMediaType(http.DetectContentType(data))
+	return mediaType
+}
+
+// IsText reports whether data of mimeType can be returned as text rather
+// than as a base64 blob
+func IsText(mimeType string, data []byte) bool {
+	if !utf8.Valid(data) {
+		return false
+	}
+	switch {
+	case strings.HasPrefix(mimeType, "text/"),
+		mimeType == "application/json",
+		mimeType == "application/xml",
+		mimeType == "application/yaml",
+		mimeType == "application/x-yaml",
+		mimeType == "application/toml",
+		mimeType == "application/javascript",
+		strings.HasSuffix(mimeType, "+json"),
+		strings.HasSuffix(mimeType, "+xml"):
+		return true
+	}
+	return false
+}
@@ -0,0 +1,40 @@
+package to
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestMIMEType tests that text files and attachments are returned as text
+// and everything else as a blob
+func TestMIMEType(t *testing.T) {
+	tests := []struct {
+		name        string
+		file        string
+		contentType string
+		data        []byte
+		mimeType    string
+		text        bool
+	}{
+		{name: "server type", file: "log", contentType: "text/plain; charset=utf-8", data: []byte("ok"), mimeType: "text/plain", text: true},
+		{name: "generic server type", file: "data.json", contentType: "application/octet-stream", data: []byte(`{"a":1}`), mimeType: "application/json", text: true},
+		{name: "markdown", file: "README.md", data: []byte("# Title"), mimeType: "text/markdown", text: true},
+		{name: "source", file: "main.go", data: []byte("package main"), mimeType: "text/x-go", text: true},
+		{name: "upper case extension", file: ".forgejo/workflows/ci.YML", data: []byte("on: push"), mimeType: "application/yaml", text: true},
+		{name: "yaml server type", file: "ci", contentType: "application/x-yaml", data: []byte("on: push"), mimeType: "application/x-yaml", text: true},
+		{name: "toml", file: "Cargo.toml", data: []byte("[package]"), mimeType: "application/toml", text: true},
+		{name: "sniffed text", file: "Makefile", data: []byte("all:\n"), mimeType: "text/plain", text: true},
+		{name: "sniffed png", file: "screenshot", data: []byte("\x89PNG\r\n\x1a\n\x00\x00"), mimeType: "image/png"},
+		{name: "invalid utf8", file: "notes.txt", data: []byte{0xff, 0xfe, 0x00}, mimeType: "text/plain"},
+		{name: "binary", file: "blob", data: []byte{0xff, 0xfe, 0x00}, mimeType: "application/octet-stream"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mimeType := MIMEType(tt.file, tt.contentType, tt.data)
+			assert.Equal(t, tt.mimeType, mimeType)
+			assert.Equal(t, tt.text, IsText(mimeType, tt.data))
+		})
+	}
+}
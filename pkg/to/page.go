@@ -0,0 +1,81 @@
+package to
+
+import "reflect"
+
+// Page is one page of a list result. Total is nil when Forgejo does not
+// report a count, and NextPage is nil on the last page.
+type Page[T any] struct {
+	Items    []T  `json:"items"`
+	Page     int  `json:"page"`
+	Limit    int  `json:"limit"`
+	Total    *int `json:"total"`
+	HasMore  bool `json:"has_more"`
+	NextPage *int `json:"next_page"`
+}
+
+// pageView is a Page whose items went through Compact and Redact
+type pageView struct {
+	Items    any  `json:"items"`
+	Page     int  `json:"page"`
+	Limit    int  `json:"limit"`
+	Total    *int `json:"total"`
+	HasMore  bool `json:"has_more"`
+	NextPage *int `json:"next_page"`
+}
+
+// pager lets Compact and ResultSchema handle pages of any item type
+type pager interface {
+	view(items func(any) any) *pageView
+	itemsType() reflect.Type
+}
+
+var pagerType = reflect.TypeFor[pager]()
+
+func (p *Page[T]) view(items func(any) any) *pageView {
+	if p == nil {
+		return nil
+	}
+	list := p.Items
+	if list == nil {
+		list = []T{}
+	}
+	return &pageView{
+		Items:    items(list),
+		Page:     p.Page,
+		Limit:    p.Limit,
+		Total:    p.Total,
+		HasMore:  p.HasMore,
+		NextPage: p.NextPage,
+	}
+}
+
+func (p *Page[T]) itemsType() reflect.Type {
+	return reflect.TypeFor[[]T]()
+}
+
+// Reshape returns a page with the paging state of p that holds items, for
+// results built from the items of a listed page
+func Reshape[U, T any](p *Page[T], items []U) *Page[U] {
+	return &Page[U]{
+		Items:    items,
+		Page:     p.Page,
+		Limit:    p.Limit,
+		Total:    p.Total,
+		HasMore:  p.HasMore,
+		NextPage: p.NextPage,
+	}
+}
+
+// SinglePage wraps items of an endpoint without pagination as a complete
+// first page
+func SinglePage[T any](items []T) *Page[T] {
+	total := len(items)
+	return &Page[T]{Items: items, Page: 1, Limit: total, Total: &total}
+}
+
+// isPageObject reports whether a decoded JSON object is a Page
+func isPageObject(v map[string]any) bool {
+	_, hasItems := v["items"]
+	_, hasMore := v["has_more"]
+	return hasItems && hasMore
+}
@@ -0,0 +1,180 @@
+package to
+
+import (
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/invopop/jsonschema"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// resultTypes maps tool names to the result types declared with WithResult
+var resultTypes sync.Map
+
+// WithResult declares that a tool returns T through TextResult and sets its
+// output schema accordingly
+func WithResult[T any]() mcp.ToolOption {
+	return func(t *mcp.Tool) {
+		typ := reflect.TypeFor[T]()
+		resultTypes.Store(t.Name, typ)
+		t.OutputSchema = ResultSchema(typ)
+	}
+}
+
+// ResultType returns the result type a tool declared with WithResult
+func ResultType(tool string) (reflect.Type, bool) {
+	typ, ok := resultTypes.Load(tool)
+	if !ok {
+		return nil, false
+	}
+	return typ.(reflect.Type), true
+}
+
+// ResultOutputSchema is the output schema of a tool whose structured content
+// is the {"Result": ...} object built by TextResult, for tools without a
+// declared result type
+func ResultOutputSchema() mcp.ToolOutputSchema {
+	return mcp.ToolOutputSchema{
+		Type:       "object",
+		Properties: map[string]any{"Result": map[string]any{}},
+		Required:   []string{"Result"},
+	}
+}
+
+// ResultSchema derives the output schema of a tool returning typ. The schema
+// describes the value after Compact, so it follows the verbosity setting.
+// Properties are not required and may be null, since the fields argument
+// drops properties and Forgejo leaves optional objects and list entries null.
+func ResultSchema(typ reflect.Type) mcp.ToolOutputSchema {
+	var (
+		schema map[string]any
+		defs   map[string]any
+		ok     bool
+	)
+	if typ.Implements(pagerType) {
+		schema, defs, ok = pageSchema(reflect.Zero(typ).Interface().(pager).itemsType())
+	} else {
+		schema, defs, ok = valueSchema(typ)
+	}
+	if !ok {
+		return ResultOutputSchema()
+	}
+
+	return mcp.ToolOutputSchema{
+		Defs:       defs,
+		Type:       "object",
+		Properties: map[string]any{"Result": nullable(schema)},
+		Required:   []string{"Result"},
+	}
+}
+
+// valueSchema reflects the compact view of typ into a schema and its $defs
+func valueSchema(typ reflect.Type) (schema, defs map[string]any, ok bool) {
+	view := reflect.TypeOf(Compact(reflect.Zero(typ).Interface()))
+	if view == nil {
+		return nil, nil, false
+	}
+
+	reflector := jsonschema.Reflector{
+		Anonymous:                  true,
+		AllowAdditionalProperties:  true,
+		RequiredFromJSONSchemaTags: true,
+		Namer:                      defName,
+	}
+	data, err := json.Marshal(reflector.ReflectFromType(view))
+	if err != nil {
+		return nil, nil, false
+	}
+	if err := json.Unmarshal(data, &schema); err != nil {
+		return nil, nil, false
+	}
+
+	defs, _ = schema["$defs"].(map[string]any)
+	delete(schema, "$defs")
+	delete(schema, "$schema")
+	for _, def := range defs {
+		nullableProperties(def)
+	}
+	nullableProperties(schema)
+	return schema, defs, true
+}
+
+// typeArgQualifier matches the package paths in the type arguments of a
+// generic type name
+var typeArgQualifier = regexp.MustCompile(`[\w./-]*\.`)
+
+// defName names the $defs entry of typ. Generic types are named after their
+// type arguments without package paths, whose slashes would break $ref
+// pointers: Page[*to.IssueView] becomes PageOfIssueView.
+func defName(typ reflect.Type) string {
+	base, args, generic := strings.Cut(typ.Name(), "[")
+	if !generic {
+		return ""
+	}
+	args = typeArgQualifier.ReplaceAllString(args, "")
+	return base + "Of" + strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return -1
+	}, args)
+}
+
+// pageSchema describes a Page whose items are of type items
+func pageSchema(items reflect.Type) (schema, defs map[string]any, ok bool) {
+	itemsSchema, defs, ok := valueSchema(items)
+	if !ok {
+		return nil, nil, false
+	}
+	integer := func() any { return map[string]any{"type": []any{"integer", "null"}} }
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"items":     nullable(itemsSchema),
+			"page":      integer(),
+			"limit":     integer(),
+			"total":     integer(),
+			"has_more":  map[string]any{"type": []any{"boolean", "null"}},
+			"next_page": integer(),
+		},
+	}, defs, true
+}
+
+// nullableProperties makes the properties, array items and map values of a
+// schema, and of the schemas nested in it, accept null
+func nullableProperties(v any) {
+	schema, ok := v.(map[string]any)
+	if !ok {
+		return
+	}
+	if props, ok := schema["properties"].(map[string]any); ok {
+		for name, prop := range props {
+			nullableProperties(prop)
+			props[name] = nullable(prop)
+		}
+	}
+	for _, key := range []string{"items", "additionalProperties"} {
+		if sub, ok := schema[key]; ok {
+			nullableProperties(sub)
+			schema[key] = nullable(sub)
+		}
+	}
+}
+
+func nullable(v any) any {
+	schema, ok := v.(map[string]any)
+	if !ok {
+		return v
+	}
+	if ref, ok := schema["$ref"]; ok && len(schema) == 1 {
+		return map[string]any{"anyOf": []any{map[string]any{"$ref": ref}, map[string]any{"type": "null"}}}
+	}
+	if typ, ok := schema["type"].(string); ok {
+		schema["type"] = []any{typ, "null"}
+	}
+	return schema
+}
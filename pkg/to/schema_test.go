@@ -0,0 +1,87 @@
+package to
+
+import (
+	"reflect"
+	"testing"
+
+	"codeberg.org/goern/forgejo-mcp/v2/pkg/flag"
+
+	forgejo_sdk "codeberg.org/mvdkleijn/forgejo-sdk/forgejo/v2"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestWithResult verifies the result type is recorded and the output schema
+// wraps it in the Result property
+func TestWithResult(t *testing.T) {
+	tool := mcp.NewTool("schema_test_tool", WithResult[[]*forgejo_sdk.Label]())
+
+	typ, ok := ResultType("schema_test_tool")
+	require.True(t, ok)
+	assert.Equal(t, reflect.TypeFor[[]*forgejo_sdk.Label](), typ)
+
+	assert.Equal(t, "object", tool.OutputSchema.Type)
+	assert.Equal(t, []string{"Result"}, tool.OutputSchema.Required)
+	result := tool.OutputSchema.Properties["Result"].(map[string]any)
+	assert.Equal(t, []any{"array", "null"}, result["type"])
+
+	_, ok = ResultType("undeclared_tool")
+	assert.False(t, ok)
+}
+
+// TestResultSchema_Verbosity verifies the schema describes the compact view
+// or the full type depending on the verbosity
+func TestResultSchema_Verbosity(t *testing.T) {
+	defer func(verbosity string) { flag.Verbosity = verbosity }(flag.Verbosity)
+
+	flag.Verbosity = VerbosityCompact
+	compact := ResultSchema(reflect.TypeFor[*forgejo_sdk.Issue]())
+	assert.Contains(t, compact.Defs, "IssueView")
+	assert.NotContains(t, compact.Defs, "Issue")
+
+	flag.Verbosity = VerbosityFull
+	full := ResultSchema(reflect.TypeFor[*forgejo_sdk.Issue]())
+	assert.Contains(t, full.Defs, "Issue")
+	assert.NotContains(t, full.Defs, "IssueView")
+
+	// Optional objects are nullable
+	issue := full.Defs["Issue"].(map[string]any)
+	milestone := issue["properties"].(map[string]any)["milestone"].(map[string]any)
+	assert.Contains(t, milestone["anyOf"], map[string]any{"type": "null"})
+}
+
+// TestResultSchema_String verifies plain message results
+func TestResultSchema_String(t *testing.T) {
+	schema := ResultSchema(reflect.TypeFor[string]())
+	assert.Empty(t, schema.Defs)
+	assert.Equal(t, []any{"string", "null"}, schema.Properties["Result"].(map[string]any)["type"])
+}
+
+// TestResultSchema_Page verifies page schemas describe the items and the
+// paging state
+func TestResultSchema_Page(t *testing.T) {
+	schema := ResultSchema(reflect.TypeFor[*Page[*forgejo_sdk.Label]]())
+	result := schema.Properties["Result"].(map[string]any)
+	props := result["properties"].(map[string]any)
+
+	for _, key := range []string{"page", "limit", "total", "next_page"} {
+		assert.Equal(t, []any{"integer", "null"}, props[key].(map[string]any)["type"], key)
+	}
+	items := props["items"].(map[string]any)
+	assert.Equal(t, []any{"array", "null"}, items["type"])
+	assert.Contains(t, schema.Defs, "LabelView")
+}
+
+// TestResultSchema_GenericDefs verifies generic types nested in results get
+// $defs names without package paths, which would break $ref pointers
+func TestResultSchema_GenericDefs(t *testing.T) {
+	type dependencies struct {
+		Blocks *Page[*forgejo_sdk.Label] `json:"blocks"`
+	}
+	schema := ResultSchema(reflect.TypeFor[*dependencies]())
+	assert.Contains(t, schema.Defs, "PageOfLabel")
+	for name := range schema.Defs {
+		assert.NotContains(t, name, "/", name)
+	}
+}
@@ -1,10 +1,14 @@
 package to
 
 import (
+	"bytes"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 
 	"codeberg.org/goern/forgejo-mcp/v2/pkg/log"
+
+	forgejo_sdk "codeberg.org/mvdkleijn/forgejo-sdk/forgejo/v2"
 	"github.com/mark3labs/mcp-go/mcp"
 )
 
@@ -21,27 +25,127 @@ func SafeJSONMarshal(v any) string {
 	return string(data)
 }
 
+// Redact returns a copy of v with secret values removed. Action secrets lose
+// their data and any string stored under a sensitive key is replaced, so
+// write-only values never reach tool results or logs.
+func Redact(v any) any {
+	switch s := v.(type) {
+	case *forgejo_sdk.Secret:
+		if s == nil {
+			return v
+		}
+		c := *s
+		c.Data = ""
+		return &c
+	case []*forgejo_sdk.Secret:
+		out := make([]*forgejo_sdk.Secret, 0, len(s))
+		for _, secret := range s {
+			out = append(out, Redact(secret).(*forgejo_sdk.Secret))
+		}
+		return out
+	case *pageView:
+		if s == nil {
+			return v
+		}
+		c := *s
+		c.Items = Redact(s.Items)
+		return &c
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return v
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var generic any
+	if err := dec.Decode(&generic); err != nil {
+		return v
+	}
+	if !redactValue(generic) {
+		return v
+	}
+	return generic
+}
+
+// redactValue walks decoded JSON in place and reports whether anything was redacted
+func redactValue(v any) bool {
+	redacted := false
+	switch val := v.(type) {
+	case map[string]any:
+		for key, item := range val {
+			if s, ok := item.(string); ok && s != "" && log.IsSensitiveKey(key) {
+				val[key] = log.RedactedValue
+				redacted = true
+				continue
+			}
+			if redactValue(item) {
+				redacted = true
+			}
+		}
+	case []any:
+		for _, item := range val {
+			if redactValue(item) {
+				redacted = true
+			}
+		}
+	}
+	return redacted
+}
+
+// TextResult returns v as JSON text wrapped in {"Result": ...}, and the same
+// value as structured content for clients that read it
 func TextResult(v any) (*mcp.CallToolResult, error) {
-	result := textResult{v}
+	result := textResult{Redact(Compact(v))}
 	resultBytes, err := json.Marshal(result)
 	if err != nil {
 		return nil, fmt.Errorf("marshal result err: %v", err)
 	}
 	log.Debugf("Text Result: %s", string(resultBytes))
-	return mcp.NewToolResultText(string(resultBytes)), nil
+	return &mcp.CallToolResult{
+		Content:           []mcp.Content{mcp.NewTextContent(string(resultBytes))},
+		StructuredContent: result,
+	}, nil
 }
 
 // SafeTextResult creates a text result with additional safety checks
 func SafeTextResult(v any) (*mcp.CallToolResult, error) {
 	// If v is a struct or complex type, try to convert it to a simple map
 	// This provides an extra layer of safety against SDK-specific types
-	var safeResult any = v
+	var safeResult any = Redact(Compact(v))
 
 	jsonStr := SafeJSONMarshal(safeResult)
-	return mcp.NewToolResultText(fmt.Sprintf(`{"Result":%s}`, jsonStr)), nil
+	return &mcp.CallToolResult{
+		Content:           []mcp.Content{mcp.NewTextContent(fmt.Sprintf(`{"Result":%s}`, jsonStr))},
+		StructuredContent: textResult{safeResult},
+	}, nil
 }
 
-func ErrorResult(err error) (*mcp.CallToolResult, error) {
-	log.Errorf(err.Error())
-	return nil, err
+// BlobResult returns v as JSON text followed by data as an embedded blob
+// resource, for binary content such as downloaded attachments
+func BlobResult(v any, uri, mimeType string, data []byte) (*mcp.CallToolResult, error) {
+	result, err := TextResult(v)
+	if err != nil {
+		return nil, err
+	}
+	result.Content = append(result.Content, mcp.NewEmbeddedResource(mcp.BlobResourceContents{
+		URI:      uri,
+		MIMEType: mimeType,
+		Blob:     base64.StdEncoding.EncodeToString(data),
+	}))
+	return result, nil
+}
+
+// JSONResource returns v as the JSON contents of the resource uri, in the
+// same compact and redacted form as tool results
+func JSONResource(uri string, v any) ([]mcp.ResourceContents, error) {
+	data, err := json.Marshal(Redact(Compact(v)))
+	if err != nil {
+		return nil, fmt.Errorf("marshal resource err: %v", err)
+	}
+	return []mcp.ResourceContents{mcp.TextResourceContents{
+		URI:      uri,
+		MIMEType: "application/json",
+		Text:     string(data),
+	}}, nil
 }
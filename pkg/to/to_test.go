@@ -0,0 +1,51 @@
+package to
+
+import (
+	"testing"
+
+	forgejo_sdk "codeberg.org/mvdkleijn/forgejo-sdk/forgejo/v2"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestTextResult_RedactsSecretData verifies action secret values never reach the result
+func TestTextResult_RedactsSecretData(t *testing.T) {
+	secrets := []*forgejo_sdk.Secret{
+		{Name: "REGISTRY_TOKEN", Data: "hunter2"},
+	}
+
+	result, err := TextResult(secrets)
+	assert.NoError(t, err)
+
+	text := result.Content[0].(mcp.TextContent).Text
+	assert.Contains(t, text, "REGISTRY_TOKEN")
+	assert.NotContains(t, text, "hunter2")
+
+	// The caller's value must not be modified
+	assert.Equal(t, "hunter2", secrets[0].Data)
+}
+
+// TestTextResult_RedactsSensitiveKeys verifies values under sensitive keys are replaced
+func TestTextResult_RedactsSensitiveKeys(t *testing.T) {
+	hook := map[string]any{
+		"id": 1,
+		"config": map[string]string{
+			"url":    "https://example.org/hook",
+			"secret": "hunter2",
+		},
+	}
+
+	result, err := TextResult(hook)
+	assert.NoError(t, err)
+
+	text := result.Content[0].(mcp.TextContent).Text
+	assert.Contains(t, text, "https://example.org/hook")
+	assert.Contains(t, text, "[redacted]")
+	assert.NotContains(t, text, "hunter2")
+}
+
+// TestRedact_LeavesPlainValuesUntouched verifies values without secrets are returned as-is
+func TestRedact_LeavesPlainValuesUntouched(t *testing.T) {
+	label := &forgejo_sdk.Label{ID: 1, Name: "bug"}
+	assert.Same(t, label, Redact(label))
+}
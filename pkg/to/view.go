@@ -0,0 +1,461 @@
+package to
+
+import (
+	"reflect"
+	"strings"
+	"time"
+
+	"codeberg.org/goern/forgejo-mcp/v2/pkg/flag"
+
+	forgejo_sdk "codeberg.org/mvdkleijn/forgejo-sdk/forgejo/v2"
+)
+
+const (
+	VerbosityCompact = "compact"
+	VerbosityFull    = "full"
+)
+
+// IssueView is the compact form of an issue. Body is only kept when a single
+// issue is returned.
+type IssueView struct {
+	Number    int64      `json:"number"`
+	Title     string     `json:"title"`
+	State     string     `json:"state"`
+	Repo      string     `json:"repo,omitempty"`
+	IsPull    bool       `json:"is_pull,omitempty"`
+	User      string     `json:"user"`
+	Labels    []string   `json:"labels,omitempty"`
+	Assignees []string   `json:"assignees,omitempty"`
+	Milestone string     `json:"milestone,omitempty"`
+	Comments  int        `json:"comments"`
+	Body      string     `json:"body,omitempty"`
+	HTMLURL   string     `json:"html_url"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	ClosedAt  *time.Time `json:"closed_at,omitempty"`
+}
+
+// PullRequestView is the compact form of a pull request. Body is only kept
+// when a single pull request is returned.
+type PullRequestView struct {
+	Number    int64      `json:"number"`
+	Title     string     `json:"title"`
+	State     string     `json:"state"`
+	User      string     `json:"user"`
+	Head      string     `json:"head"`
+	Base      string     `json:"base"`
+	Merged    bool       `json:"merged"`
+	Mergeable bool       `json:"mergeable"`
+	Labels    []string   `json:"labels,omitempty"`
+	Assignees []string   `json:"assignees,omitempty"`
+	Milestone string     `json:"milestone,omitempty"`
+	Comments  int        `json:"comments"`
+	Body      string     `json:"body,omitempty"`
+	HTMLURL   string     `json:"html_url"`
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+	MergedAt  *time.Time `json:"merged_at,omitempty"`
+}
+
+// RepoView is the compact form of a repository. Settings are only kept when
+// a single repository is returned.
+type RepoView struct {
+	FullName      string        `json:"full_name"`
+	Description   string        `json:"description,omitempty"`
+	Private       bool          `json:"private"`
+	Fork          bool          `json:"fork,omitempty"`
+	Mirror        bool          `json:"mirror,omitempty"`
+	Archived      bool          `json:"archived,omitempty"`
+	DefaultBranch string        `json:"default_branch"`
+	Stars         int           `json:"stars"`
+	Forks         int           `json:"forks"`
+	OpenIssues    int           `json:"open_issues"`
+	OpenPulls     int           `json:"open_pulls"`
+	HTMLURL       string        `json:"html_url"`
+	UpdatedAt     time.Time     `json:"updated_at"`
+	Settings      *RepoSettings `json:"settings,omitempty"`
+}
+
+// RepoSettings are the enabled units and merge styles of a repository
+type RepoSettings struct {
+	HasIssues         bool   `json:"has_issues"`
+	HasWiki           bool   `json:"has_wiki"`
+	HasPullRequests   bool   `json:"has_pull_requests"`
+	HasProjects       bool   `json:"has_projects"`
+	HasReleases       bool   `json:"has_releases"`
+	HasPackages       bool   `json:"has_packages"`
+	HasActions        bool   `json:"has_actions"`
+	AllowMerge        bool   `json:"allow_merge_commits"`
+	AllowRebase       bool   `json:"allow_rebase"`
+	AllowRebaseMerge  bool   `json:"allow_rebase_explicit"`
+	AllowSquash       bool   `json:"allow_squash_merge"`
+	DefaultMergeStyle string `json:"default_merge_style,omitempty"`
+}
+
+// UserView is the compact form of a user
+type UserView struct {
+	Login    string `json:"login"`
+	FullName string `json:"full_name,omitempty"`
+	Email    string `json:"email,omitempty"`
+	IsAdmin  bool   `json:"is_admin,omitempty"`
+}
+
+// CommitView is the compact form of a commit. Lists only keep the first
+// line of the message.
+type CommitView struct {
+	SHA       string `json:"sha"`
+	Message   string `json:"message"`
+	Author    string `json:"author"`
+	Date      string `json:"date"`
+	HTMLURL   string `json:"html_url"`
+	Additions int    `json:"additions,omitempty"`
+	Deletions int    `json:"deletions,omitempty"`
+}
+
+// LabelView is the compact form of a label
+type LabelView struct {
+	ID          int64  `json:"id"`
+	Name        string `json:"name"`
+	Color       string `json:"color"`
+	Description string `json:"description,omitempty"`
+}
+
+// NotificationView is the compact form of a notification thread
+type NotificationView struct {
+	ID        int64     `json:"id"`
+	Repo      string    `json:"repo,omitempty"`
+	Title     string    `json:"title"`
+	Type      string    `json:"type"`
+	State     string    `json:"state,omitempty"`
+	HTMLURL   string    `json:"html_url,omitempty"`
+	Unread    bool      `json:"unread"`
+	Pinned    bool      `json:"pinned,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// PackageView is the compact form of a package version
+type PackageView struct {
+	ID        int64     `json:"id"`
+	Owner     string    `json:"owner"`
+	Repo      string    `json:"repo,omitempty"`
+	Creator   string    `json:"creator"`
+	Type      string    `json:"type"`
+	Name      string    `json:"name"`
+	Version   string    `json:"version"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Viewer is implemented by results that hold SDK objects, so that Compact
+// reaches them. CompactView must return a typed nil for a nil receiver,
+// since output schemas are derived from it.
+type Viewer interface {
+	CompactView() any
+}
+
+var viewerType = reflect.TypeFor[Viewer]()
+
+// View returns the compact view of v as V, or the zero V when v has none
+// or the verbosity is full. Viewer implementations use it for nested
+// values.
+func View[V any](v any) V {
+	view, _ := Compact(v).(V)
+	return view
+}
+
+// Compact returns the compact view of issues, pull requests, repositories,
+// users, commits, labels, notifications, packages and Viewers, single, in a
+// slice or in a Page. Other values and full verbosity return v unchanged.
+func Compact(v any) any {
+	if p, ok := v.(pager); ok {
+		return p.view(Compact)
+	}
+	if flag.Verbosity == VerbosityFull {
+		return v
+	}
+	if viewer, ok := v.(Viewer); ok {
+		return viewer.CompactView()
+	}
+	if views, ok := compactViewers(v); ok {
+		return views
+	}
+	switch val := v.(type) {
+	case *forgejo_sdk.Issue:
+		return issueView(val, true)
+	case []*forgejo_sdk.Issue:
+		return compactAll(val, func(i *forgejo_sdk.Issue) *IssueView { return issueView(i, false) })
+	case *forgejo_sdk.PullRequest:
+		return pullRequestView(val, true)
+	case []*forgejo_sdk.PullRequest:
+		return compactAll(val, func(pr *forgejo_sdk.PullRequest) *PullRequestView { return pullRequestView(pr, false) })
+	case *forgejo_sdk.Repository:
+		return repoView(val, true)
+	case []*forgejo_sdk.Repository:
+		return compactAll(val, func(r *forgejo_sdk.Repository) *RepoView { return repoView(r, false) })
+	case *forgejo_sdk.User:
+		return userView(val)
+	case []*forgejo_sdk.User:
+		return compactAll(val, userView)
+	case *forgejo_sdk.Commit:
+		return commitView(val, true)
+	case []*forgejo_sdk.Commit:
+		return compactAll(val, func(c *forgejo_sdk.Commit) *CommitView { return commitView(c, false) })
+	case *forgejo_sdk.Label:
+		return labelView(val)
+	case []*forgejo_sdk.Label:
+		return compactAll(val, labelView)
+	case *forgejo_sdk.NotificationThread:
+		return notificationView(val)
+	case []*forgejo_sdk.NotificationThread:
+		return compactAll(val, notificationView)
+	case *forgejo_sdk.Package:
+		return packageView(val)
+	case []*forgejo_sdk.Package:
+		return compactAll(val, packageView)
+	}
+	return v
+}
+
+// compactViewers returns the views of a slice of Viewers, typed after the
+// view of their element type so that a nil slice still has a schema
+func compactViewers(v any) (any, bool) {
+	list := reflect.ValueOf(v)
+	if list.Kind() != reflect.Slice || !list.Type().Elem().Implements(viewerType) {
+		return nil, false
+	}
+	zero := reflect.Zero(list.Type().Elem()).Interface().(Viewer)
+	views := reflect.MakeSlice(reflect.SliceOf(reflect.TypeOf(zero.CompactView())), 0, list.Len())
+	for i := 0; i < list.Len(); i++ {
+		views = reflect.Append(views, reflect.ValueOf(list.Index(i).Interface().(Viewer).CompactView()))
+	}
+	return views.Interface(), true
+}
+
+func compactAll[T, V any](items []*T, view func(*T) *V) []*V {
+	out := make([]*V, 0, len(items))
+	for _, item := range items {
+		out = append(out, view(item))
+	}
+	return out
+}
+
+func issueView(i *forgejo_sdk.Issue, withBody bool) *IssueView {
+	if i == nil {
+		return nil
+	}
+	v := &IssueView{
+		Number:    i.Index,
+		Title:     i.Title,
+		State:     string(i.State),
+		IsPull:    i.PullRequest != nil,
+		User:      login(i.Poster),
+		Labels:    labelNames(i.Labels),
+		Assignees: logins(i.Assignees),
+		Comments:  i.Comments,
+		HTMLURL:   i.HTMLURL,
+		CreatedAt: i.Created,
+		UpdatedAt: i.Updated,
+		ClosedAt:  i.Closed,
+	}
+	if i.Repository != nil {
+		v.Repo = i.Repository.FullName
+	}
+	if i.Milestone != nil {
+		v.Milestone = i.Milestone.Title
+	}
+	if withBody {
+		v.Body = i.Body
+	}
+	return v
+}
+
+func pullRequestView(pr *forgejo_sdk.PullRequest, withBody bool) *PullRequestView {
+	if pr == nil {
+		return nil
+	}
+	v := &PullRequestView{
+		Number:    pr.Index,
+		Title:     pr.Title,
+		State:     string(pr.State),
+		User:      login(pr.Poster),
+		Merged:    pr.HasMerged,
+		Mergeable: pr.Mergeable,
+		Labels:    labelNames(pr.Labels),
+		Assignees: logins(pr.Assignees),
+		Comments:  pr.Comments,
+		HTMLURL:   pr.HTMLURL,
+		CreatedAt: pr.Created,
+		UpdatedAt: pr.Updated,
+		MergedAt:  pr.Merged,
+	}
+	if pr.Head != nil {
+		v.Head = pr.Head.Ref
+	}
+	if pr.Base != nil {
+		v.Base = pr.Base.Ref
+	}
+	if pr.Milestone != nil {
+		v.Milestone = pr.Milestone.Title
+	}
+	if withBody {
+		v.Body = pr.Body
+	}
+	return v
+}
+
+func repoView(r *forgejo_sdk.Repository, withSettings bool) *RepoView {
+	if r == nil {
+		return nil
+	}
+	v := &RepoView{
+		FullName:      r.FullName,
+		Description:   r.Description,
+		Private:       r.Private,
+		Fork:          r.Fork,
+		Mirror:        r.Mirror,
+		Archived:      r.Archived,
+		DefaultBranch: r.DefaultBranch,
+		Stars:         r.Stars,
+		Forks:         r.Forks,
+		OpenIssues:    r.OpenIssues,
+		OpenPulls:     r.OpenPulls,
+		HTMLURL:       r.HTMLURL,
+		UpdatedAt:     r.Updated,
+	}
+	if withSettings {
+		v.Settings = &RepoSettings{
+			HasIssues:         r.HasIssues,
+			HasWiki:           r.HasWiki,
+			HasPullRequests:   r.HasPullRequests,
+			HasProjects:       r.HasProjects,
+			HasReleases:       r.HasReleases,
+			HasPackages:       r.HasPackages,
+			HasActions:        r.HasActions,
+			AllowMerge:        r.AllowMerge,
+			AllowRebase:       r.AllowRebase,
+			AllowRebaseMerge:  r.AllowRebaseMerge,
+			AllowSquash:       r.AllowSquash,
+			DefaultMergeStyle: string(r.DefaultMergeStyle),
+		}
+	}
+	return v
+}
+
+func userView(u *forgejo_sdk.User) *UserView {
+	if u == nil {
+		return nil
+	}
+	return &UserView{
+		Login:    u.UserName,
+		FullName: u.FullName,
+		Email:    u.Email,
+		IsAdmin:  u.IsAdmin,
+	}
+}
+
+func commitView(c *forgejo_sdk.Commit, fullMessage bool) *CommitView {
+	if c == nil {
+		return nil
+	}
+	v := &CommitView{HTMLURL: c.HTMLURL}
+	if c.CommitMeta != nil {
+		v.SHA = c.SHA
+	}
+	if c.RepoCommit != nil {
+		v.Message = c.RepoCommit.Message
+		if !fullMessage {
+			v.Message, _, _ = strings.Cut(v.Message, "\n")
+		}
+		if c.RepoCommit.Author != nil {
+			v.Author = c.RepoCommit.Author.Name
+			v.Date = c.RepoCommit.Author.Date
+		}
+	}
+	if v.Author == "" && c.Author != nil {
+		v.Author = c.Author.UserName
+	}
+	if c.Stats != nil {
+		v.Additions = c.Stats.Additions
+		v.Deletions = c.Stats.Deletions
+	}
+	return v
+}
+
+func labelView(l *forgejo_sdk.Label) *LabelView {
+	if l == nil {
+		return nil
+	}
+	return &LabelView{
+		ID:          l.ID,
+		Name:        l.Name,
+		Color:       l.Color,
+		Description: l.Description,
+	}
+}
+
+func notificationView(n *forgejo_sdk.NotificationThread) *NotificationView {
+	if n == nil {
+		return nil
+	}
+	v := &NotificationView{
+		ID:        n.ID,
+		Unread:    n.Unread,
+		Pinned:    n.Pinned,
+		UpdatedAt: n.UpdatedAt,
+	}
+	if n.Repository != nil {
+		v.Repo = n.Repository.FullName
+	}
+	if n.Subject != nil {
+		v.Title = n.Subject.Title
+		v.Type = string(n.Subject.Type)
+		v.State = string(n.Subject.State)
+		v.HTMLURL = n.Subject.HTMLURL
+	}
+	return v
+}
+
+func packageView(p *forgejo_sdk.Package) *PackageView {
+	if p == nil {
+		return nil
+	}
+	v := &PackageView{
+		ID:        p.ID,
+		Owner:     p.Owner.UserName,
+		Creator:   p.Creator.UserName,
+		Type:      p.Type,
+		Name:      p.Name,
+		Version:   p.Version,
+		CreatedAt: p.CreatedAt,
+	}
+	if p.Repository != nil {
+		v.Repo = p.Repository.FullName
+	}
+	return v
+}
+
+func login(u *forgejo_sdk.User) string {
+	if u == nil {
+		return ""
+	}
+	return u.UserName
+}
+
+func logins(users []*forgejo_sdk.User) []string {
+	var out []string
+	for _, u := range users {
+		if u != nil {
+			out = append(out, u.UserName)
+		}
+	}
+	return out
+}
+
+func labelNames(labels []*forgejo_sdk.Label) []string {
+	var out []string
+	for _, l := range labels {
+		if l != nil {
+			out = append(out, l.Name)
+		}
+	}
+	return out
+}
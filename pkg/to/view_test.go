@@ -0,0 +1,187 @@
+package to
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"codeberg.org/goern/forgejo-mcp/v2/pkg/flag"
+
+	forgejo_sdk "codeberg.org/mvdkleijn/forgejo-sdk/forgejo/v2"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func sampleIssue() *forgejo_sdk.Issue {
+	return &forgejo_sdk.Issue{
+		ID:         101,
+		Index:      7,
+		Title:      "Crash on start",
+		Body:       "Stack trace...",
+		State:      forgejo_sdk.StateOpen,
+		Poster:     &forgejo_sdk.User{ID: 1, UserName: "alice", Email: "alice@example.org", AvatarURL: "https://example.org/a.png"},
+		Labels:     []*forgejo_sdk.Label{{ID: 3, Name: "bug", Color: "ee0701", URL: "https://example.org/labels/3"}},
+		Assignees:  []*forgejo_sdk.User{{UserName: "bob"}},
+		Milestone:  &forgejo_sdk.Milestone{ID: 2, Title: "v1.0"},
+		Comments:   4,
+		HTMLURL:    "https://example.org/acme/app/issues/7",
+		Created:    time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC),
+		Updated:    time.Date(2026, 10, 2, 0, 0, 0, 0, time.UTC),
+		Repository: &forgejo_sdk.RepositoryMeta{FullName: "acme/app"},
+	}
+}
+
+// TestCompact_Issue verifies issues are flattened and lists drop the body
+func TestCompact_Issue(t *testing.T) {
+	view := Compact(sampleIssue()).(*IssueView)
+	assert.Equal(t, int64(7), view.Number)
+	assert.Equal(t, "alice", view.User)
+	assert.Equal(t, []string{"bug"}, view.Labels)
+	assert.Equal(t, []string{"bob"}, view.Assignees)
+	assert.Equal(t, "v1.0", view.Milestone)
+	assert.Equal(t, "acme/app", view.Repo)
+	assert.Equal(t, "Stack trace...", view.Body)
+
+	list := Compact([]*forgejo_sdk.Issue{sampleIssue()}).([]*IssueView)
+	assert.Len(t, list, 1)
+	assert.Empty(t, list[0].Body)
+}
+
+// TestCompact_Commit verifies lists keep only the first message line
+func TestCompact_Commit(t *testing.T) {
+	commit := &forgejo_sdk.Commit{
+		CommitMeta: &forgejo_sdk.CommitMeta{SHA: "abc123"},
+		RepoCommit: &forgejo_sdk.RepoCommit{
+			Message: "Fix crash\n\nLonger explanation",
+			Author:  &forgejo_sdk.CommitUser{Identity: forgejo_sdk.Identity{Name: "Alice"}, Date: "2026-10-01T00:00:00Z"},
+		},
+	}
+
+	assert.Equal(t, "Fix crash\n\nLonger explanation", Compact(commit).(*CommitView).Message)
+	list := Compact([]*forgejo_sdk.Commit{commit}).([]*CommitView)
+	assert.Equal(t, "Fix crash", list[0].Message)
+	assert.Equal(t, "Alice", list[0].Author)
+	assert.Equal(t, "abc123", list[0].SHA)
+}
+
+// TestCompact_Full verifies full verbosity returns values unchanged
+func TestCompact_Full(t *testing.T) {
+	flag.Verbosity = VerbosityFull
+	defer func() { flag.Verbosity = "" }()
+
+	issue := sampleIssue()
+	assert.Same(t, issue, Compact(issue))
+}
+
+// TestCompact_OtherTypes verifies values without a view pass through
+func TestCompact_OtherTypes(t *testing.T) {
+	branch := &forgejo_sdk.Branch{Name: "main"}
+	assert.Same(t, branch, Compact(branch))
+}
+
+// TestTextResult_Compact verifies nested objects are dropped from results
+func TestTextResult_Compact(t *testing.T) {
+	result, err := TextResult([]*forgejo_sdk.Issue{sampleIssue()})
+	assert.NoError(t, err)
+
+	text := result.Content[0].(mcp.TextContent).Text
+	assert.Contains(t, text, `"user":"alice"`)
+	assert.NotContains(t, text, "avatar_url")
+	assert.NotContains(t, text, "alice@example.org")
+}
+
+// TestCompact_Page verifies page items are compacted, and kept in full
+// verbosity
+func TestCompact_Page(t *testing.T) {
+	defer func(verbosity string) { flag.Verbosity = verbosity }(flag.Verbosity)
+	page := &Page[*forgejo_sdk.Issue]{Items: []*forgejo_sdk.Issue{sampleIssue()}, Page: 2, Limit: 1}
+
+	flag.Verbosity = VerbosityCompact
+	view := Compact(page).(*pageView)
+	assert.Equal(t, 2, view.Page)
+	items := view.Items.([]*IssueView)
+	assert.Equal(t, "alice", items[0].User)
+	assert.Empty(t, items[0].Body)
+
+	flag.Verbosity = VerbosityFull
+	view = Compact(page).(*pageView)
+	assert.IsType(t, []*forgejo_sdk.Issue{}, view.Items)
+
+	// Empty pages list no items rather than null
+	view = Compact(&Page[*forgejo_sdk.Issue]{Page: 1}).(*pageView)
+	assert.Equal(t, []*forgejo_sdk.Issue{}, view.Items)
+}
+
+// threadResult is a result wrapping SDK objects, declaring its compact view
+type threadResult struct {
+	Issue *forgejo_sdk.Issue `json:"issue"`
+	Note  string             `json:"note"`
+}
+
+type threadResultView struct {
+	*threadResult
+	Issue *IssueView `json:"issue"`
+}
+
+func (r *threadResult) CompactView() any {
+	if r == nil {
+		return (*threadResultView)(nil)
+	}
+	return &threadResultView{threadResult: r, Issue: View[*IssueView](r.Issue)}
+}
+
+// TestCompact_Viewer verifies wrappers, single and in lists and pages,
+// compact the SDK objects nested in them
+func TestCompact_Viewer(t *testing.T) {
+	defer func(verbosity string) { flag.Verbosity = verbosity }(flag.Verbosity)
+	flag.Verbosity = VerbosityCompact
+	result := &threadResult{Issue: sampleIssue(), Note: "triaged"}
+
+	data, err := json.Marshal(Compact(result))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"note":"triaged","issue":{"number":7,"title":"Crash on start","state":"open","repo":"acme/app","user":"alice","labels":["bug"],"assignees":["bob"],"milestone":"v1.0","comments":4,"body":"Stack trace...","html_url":"https://example.org/acme/app/issues/7","created_at":"2026-10-01T00:00:00Z","updated_at":"2026-10-02T00:00:00Z"}}`, string(data))
+
+	list := Compact([]*threadResult{result}).([]*threadResultView)
+	assert.Equal(t, "alice", list[0].Issue.User)
+	assert.IsType(t, []*threadResultView{}, Compact([]*threadResult(nil)))
+
+	view := Compact(&Page[*threadResult]{Items: []*threadResult{result}}).(*pageView)
+	assert.IsType(t, []*threadResultView{}, view.Items)
+
+	flag.Verbosity = VerbosityFull
+	assert.Same(t, result, Compact(result))
+	assert.Nil(t, View[*IssueView](sampleIssue()))
+}
+
+// TestCompact_Notification verifies notifications keep the repository
+// name and the subject
+func TestCompact_Notification(t *testing.T) {
+	view := Compact(&forgejo_sdk.NotificationThread{
+		ID:         5,
+		Repository: &forgejo_sdk.Repository{FullName: "acme/app", Owner: &forgejo_sdk.User{UserName: "acme"}},
+		Subject:    &forgejo_sdk.NotificationSubject{Title: "Crash on start", Type: forgejo_sdk.NotifySubjectIssue, State: forgejo_sdk.NotifySubjectOpen},
+		Unread:     true,
+	}).(*NotificationView)
+	assert.Equal(t, "acme/app", view.Repo)
+	assert.Equal(t, "Crash on start", view.Title)
+	assert.Equal(t, "Issue", view.Type)
+	assert.Equal(t, "open", view.State)
+	assert.True(t, view.Unread)
+}
+
+// TestCompact_Package verifies packages keep owner and creator logins
+func TestCompact_Package(t *testing.T) {
+	view := Compact(&forgejo_sdk.Package{
+		ID:      9,
+		Owner:   forgejo_sdk.User{UserName: "acme", Email: "acme@example.org"},
+		Creator: forgejo_sdk.User{UserName: "alice"},
+		Type:    "npm",
+		Name:    "web",
+		Version: "1.0.0",
+	}).(*PackageView)
+	assert.Equal(t, "acme", view.Owner)
+	assert.Equal(t, "alice", view.Creator)
+	assert.Equal(t, "1.0.0", view.Version)
+	assert.Empty(t, view.Repo)
+}